@@ -16,7 +16,8 @@ import (
 )
 
 type Aerospike struct {
-	Servers []string
+	Servers    []string
+	GatherSets bool `toml:"gather_sets"`
 }
 
 var sampleConfig = `
@@ -24,6 +25,9 @@ var sampleConfig = `
   ## This plugin will query all namespaces the aerospike
   ## server has configured and get stats for them.
   servers = ["localhost:3000"]
+
+  ## Gather the object counts of the sets of every namespace
+  # gather_sets = false
  `
 
 func (a *Aerospike) SampleConfig() string {
@@ -81,12 +85,7 @@ func (a *Aerospike) gatherServer(hostport string, acc telegraf.Accumulator) erro
 			return err
 		}
 		for k, v := range stats {
-			val, err := parseValue(v)
-			if err == nil {
-				fields[strings.Replace(k, "-", "_", -1)] = val
-			} else {
-				log.Printf("I! skipping aerospike field %v with int64 overflow: %q", k, v)
-			}
+			addField(fields, k, v)
 		}
 		acc.AddFields("aerospike_node", fields, tags, time.Now())
 
@@ -94,38 +93,107 @@ func (a *Aerospike) gatherServer(hostport string, acc telegraf.Accumulator) erro
 		if err != nil {
 			return err
 		}
-		namespaces := strings.Split(info["namespaces"], ";")
 
-		for _, namespace := range namespaces {
+		for _, namespace := range parseList(info["namespaces"]) {
 			nTags := map[string]string{
 				"aerospike_host": hostport,
 				"node_name":      n.GetName(),
 			}
 			nTags["namespace"] = namespace
-			nFields := make(map[string]interface{})
 			info, err := as.RequestNodeInfo(n, "namespace/"+namespace)
 			if err != nil {
 				continue
 			}
-			stats := strings.Split(info["namespace/"+namespace], ";")
-			for _, stat := range stats {
-				parts := strings.Split(stat, "=")
-				if len(parts) < 2 {
-					continue
-				}
-				val, err := parseValue(parts[1])
-				if err == nil {
-					nFields[strings.Replace(parts[0], "-", "_", -1)] = val
-				} else {
-					log.Printf("I! skipping aerospike field %v with int64 overflow: %q", parts[0], parts[1])
-				}
-			}
+			nFields := parseNamespaceInfo(info["namespace/"+namespace])
 			acc.AddFields("aerospike_namespace", nFields, nTags, time.Now())
+
+			if !a.GatherSets {
+				continue
+			}
+			info, err = as.RequestNodeInfo(n, "sets/"+namespace)
+			if err != nil {
+				continue
+			}
+			for _, set := range parseSetsInfo(info["sets/"+namespace]) {
+				sTags := copyTags(nTags)
+				sTags["set"] = set.name
+				acc.AddFields("aerospike_set", set.fields, sTags, time.Now())
+			}
 		}
 	}
 	return nil
 }
 
+type setInfo struct {
+	name   string
+	fields map[string]interface{}
+}
+
+// parseList splits the semicolon separated list of an info response, ie:
+//   test;bar
+func parseList(info string) []string {
+	var list []string
+	for _, item := range strings.Split(info, ";") {
+		if item = strings.TrimSpace(item); len(item) > 0 {
+			list = append(list, item)
+		}
+	}
+	return list
+}
+
+// parseNamespaceInfo parses the response of the namespace/<ns> info
+// command, ie:
+//   objects=2;sub-objects=0;master-objects=2;memory_used_bytes=112;...
+func parseNamespaceInfo(info string) map[string]interface{} {
+	fields := make(map[string]interface{})
+	for _, stat := range parseList(info) {
+		parts := strings.SplitN(stat, "=", 2)
+		if len(parts) < 2 {
+			continue
+		}
+		addField(fields, parts[0], parts[1])
+	}
+	return fields
+}
+
+// parseSetsInfo parses the response of the sets/<ns> info command, the
+// statistics of a set are colon separated, ie:
+//   ns=test:set=demo:objects=2:tombstones=0:memory_data_bytes=28;...
+// Servers older than 3.9 use ns_name, set_name and n_objects.
+func parseSetsInfo(info string) []setInfo {
+	var sets []setInfo
+	for _, item := range parseList(info) {
+		set := setInfo{fields: make(map[string]interface{})}
+		for _, stat := range strings.Split(item, ":") {
+			parts := strings.SplitN(stat, "=", 2)
+			if len(parts) < 2 {
+				continue
+			}
+			switch parts[0] {
+			case "ns", "ns_name":
+			case "set", "set_name":
+				set.name = parts[1]
+			default:
+				addField(set.fields, parts[0], parts[1])
+			}
+		}
+		if len(set.name) == 0 {
+			continue
+		}
+		sets = append(sets, set)
+	}
+	return sets
+}
+
+func addField(fields map[string]interface{}, k, v string) {
+	val, err := parseValue(v)
+	if err == nil {
+		fields[strings.Replace(k, "-", "_", -1)] = val
+	} else {
+		log.Printf("I! skipping aerospike field %v with int64 overflow: %q", k, v)
+	}
+}
+
 func parseValue(v string) (interface{}, error) {
 	if parsed, err := strconv.ParseInt(v, 10, 64); err == nil {
 		return parsed, nil
@@ -66,3 +66,61 @@ func TestAerospikeParseValue(t *testing.T) {
 	assert.NoError(t, err)
 	assert.Equal(t, val, `BB977942A2CA502`, "must be left as string")
 }
+
+func TestAerospikeParseNamespaceInfo(t *testing.T) {
+	// $ asinfo -v namespace/test
+	info := "objects=2;sub-objects=0;master-objects=2;memory_used_bytes=112;" +
+		"stop-writes=false;hwm-breached=false;" +
+		"cold-start-evict-ttl=4294967295;storage-engine=memory;;"
+
+	fields := parseNamespaceInfo(info)
+	assert.Equal(t, map[string]interface{}{
+		"objects":           int64(2),
+		"sub_objects":       int64(0),
+		"master_objects":    int64(2),
+		"memory_used_bytes": int64(112),
+		"stop_writes":       false,
+		"hwm_breached":      false,
+		"storage_engine":    "memory",
+		// cold-start-evict-ttl fits in an int64
+		"cold_start_evict_ttl": int64(4294967295),
+	}, fields)
+
+	assert.Empty(t, parseNamespaceInfo(""))
+	assert.Equal(t, []string{"test", "bar"}, parseList("test;bar;"))
+	assert.Empty(t, parseList(""))
+}
+
+func TestAerospikeParseSetsInfo(t *testing.T) {
+	// $ asinfo -v sets/test
+	info := "ns=test:set=demo:objects=2:tombstones=0:memory_data_bytes=28:" +
+		"truncate_lut=0:stop-writes-count=0:disable-eviction=false;" +
+		"ns=test:set=users:objects=1034:tombstones=3:memory_data_bytes=98730:" +
+		"truncate_lut=0:stop-writes-count=0:disable-eviction=true;"
+
+	sets := parseSetsInfo(info)
+	require.Len(t, sets, 2)
+	assert.Equal(t, "demo", sets[0].name)
+	assert.Equal(t, map[string]interface{}{
+		"objects":           int64(2),
+		"tombstones":        int64(0),
+		"memory_data_bytes": int64(28),
+		"truncate_lut":      int64(0),
+		"stop_writes_count": int64(0),
+		"disable_eviction":  false,
+	}, sets[0].fields)
+	assert.Equal(t, "users", sets[1].name)
+	assert.Equal(t, int64(1034), sets[1].fields["objects"])
+	assert.Equal(t, true, sets[1].fields["disable_eviction"])
+
+	// servers older than 3.9
+	sets = parseSetsInfo("ns_name=test:set_name=demo:n_objects=2:set-enable-xdr=use-default;")
+	require.Len(t, sets, 1)
+	assert.Equal(t, "demo", sets[0].name)
+	assert.Equal(t, map[string]interface{}{
+		"n_objects":      int64(2),
+		"set_enable_xdr": "use-default",
+	}, sets[0].fields)
+
+	assert.Empty(t, parseSetsInfo(""))
+}
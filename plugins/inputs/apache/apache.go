@@ -79,7 +79,6 @@ func (n *Apache) Gather(acc telegraf.Accumulator) error {
 	}
 
 	var wg sync.WaitGroup
-	wg.Add(len(n.Urls))
 	for _, u := range n.Urls {
 		addr, err := url.Parse(u)
 		if err != nil {
@@ -87,6 +86,7 @@ func (n *Apache) Gather(acc telegraf.Accumulator) error {
 			continue
 		}
 
+		wg.Add(1)
 		go func(addr *url.URL) {
 			defer wg.Done()
 			acc.AddError(n.gatherUrl(addr, acc))
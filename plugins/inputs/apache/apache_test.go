@@ -72,3 +72,62 @@ func TestHTTPApache(t *testing.T) {
 	}
 	acc.AssertContainsFields(t, "apache", fields)
 }
+
+func TestGatherScores(t *testing.T) {
+	n := &Apache{}
+
+	// one worker in every state, plus a few repeats and unknown codes
+	fields := n.gatherScores("_SRWKDCLGI.__RRW...x ")
+
+	require.Equal(t, map[string]interface{}{
+		"scboard_waiting":      float64(3),
+		"scboard_starting":     float64(1),
+		"scboard_reading":      float64(3),
+		"scboard_sending":      float64(2),
+		"scboard_keepalive":    float64(1),
+		"scboard_dnslookup":    float64(1),
+		"scboard_closing":      float64(1),
+		"scboard_logging":      float64(1),
+		"scboard_finishing":    float64(1),
+		"scboard_idle_cleanup": float64(1),
+		"scboard_open":         float64(4),
+	}, fields)
+}
+
+func TestHTTPApacheBasicAuthTLS(t *testing.T) {
+	ts := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		username, password, ok := r.BasicAuth()
+		if !ok || username != "myuser" || password != "mypassword" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		fmt.Fprintln(w, apacheStatus)
+	}))
+	defer ts.Close()
+
+	a := Apache{
+		// The invalid URL must not keep the others from being gathered
+		Urls:               []string{"http://%zz", ts.URL},
+		Username:           "myuser",
+		Password:           "mypassword",
+		InsecureSkipVerify: true,
+	}
+
+	var acc testutil.Accumulator
+	require.NoError(t, a.Gather(&acc))
+	require.Len(t, acc.Errors, 1)
+	require.True(t, acc.HasMeasurement("apache"))
+
+	a = Apache{
+		Urls:               []string{ts.URL},
+		Username:           "myuser",
+		Password:           "wrong",
+		InsecureSkipVerify: true,
+	}
+
+	acc = testutil.Accumulator{}
+	require.NoError(t, a.Gather(&acc))
+	require.Len(t, acc.Errors, 1)
+	require.Contains(t, acc.Errors[0].Error(), "401")
+	require.False(t, acc.HasMeasurement("apache"))
+}
@@ -15,6 +15,8 @@ import (
 type Bcache struct {
 	BcachePath string
 	BcacheDevs []string
+
+	GatherCacheSets bool
 }
 
 var sampleConfig = `
@@ -26,6 +28,9 @@ var sampleConfig = `
   ## Setting devices will restrict the stats to the specified
   ## bcache devices.
   bcacheDevs = ["bcache0"]
+
+  ## Gather the stats of the cache sets, tagged with the cache set uuid
+  # gatherCacheSets = false
 `
 
 func (b *Bcache) SampleConfig() string {
@@ -105,6 +110,43 @@ func (b *Bcache) gatherBcache(bdev string, acc telegraf.Accumulator) error {
 	return nil
 }
 
+// cacheSetFiles are read from the cache set directory, sizes are written in
+// the human readable format
+var cacheSetFiles = map[string]bool{
+	"cache_available_percent": false,
+	"dirty_data":              true,
+	"btree_cache_size":        true,
+}
+
+func (b *Bcache) gatherCacheSet(cset string, acc telegraf.Accumulator) error {
+	fields := make(map[string]interface{})
+	for key, pretty := range cacheSetFiles {
+		file, err := ioutil.ReadFile(cset + "/" + key)
+		if err != nil {
+			if os.IsNotExist(err) {
+				continue
+			}
+			return err
+		}
+		rawValue := strings.TrimSpace(string(file))
+		if len(rawValue) == 0 {
+			continue
+		}
+		if pretty {
+			fields[key] = prettyToBytes(rawValue)
+		} else {
+			value, _ := strconv.ParseUint(rawValue, 10, 64)
+			fields[key] = value
+		}
+	}
+	if len(fields) == 0 {
+		return nil
+	}
+	tags := map[string]string{"uuid": filepath.Base(cset)}
+	acc.AddFields("bcache_cache_set", fields, tags)
+	return nil
+}
+
 func (b *Bcache) Gather(acc telegraf.Accumulator) error {
 	bcacheDevsChecked := make(map[string]bool)
 	var restrictDevs bool
@@ -119,6 +161,20 @@ func (b *Bcache) Gather(acc telegraf.Accumulator) error {
 	if len(bcachePath) == 0 {
 		bcachePath = "/sys/fs/bcache"
 	}
+	if b.GatherCacheSets {
+		// the cache sets are the directories named after their uuid, next
+		// to the register files
+		entries, _ := ioutil.ReadDir(bcachePath)
+		for _, entry := range entries {
+			if !entry.IsDir() {
+				continue
+			}
+			err := b.gatherCacheSet(filepath.Join(bcachePath, entry.Name()), acc)
+			if err != nil {
+				acc.AddError(err)
+			}
+		}
+	}
 	bdevs, _ := filepath.Glob(bcachePath + "/*/bdev*")
 	if len(bdevs) < 1 {
 		return errors.New("Can't find any bcache device")
@@ -6,6 +6,7 @@ import (
 	"testing"
 
 	"github.com/influxdata/telegraf/testutil"
+	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
 
@@ -119,3 +120,67 @@ func TestBcacheGeneratesMetrics(t *testing.T) {
 	err = os.RemoveAll(os.TempDir() + "/telegraf")
 	require.NoError(t, err)
 }
+
+func TestBcacheGeneratesCacheSetMetrics(t *testing.T) {
+	err := os.MkdirAll(testBcacheUuidPath, 0755)
+	require.NoError(t, err)
+
+	err = os.MkdirAll(testBcacheDevPath, 0755)
+	require.NoError(t, err)
+
+	err = os.MkdirAll(testBcacheBackingDevPath+"/bcache", 0755)
+	require.NoError(t, err)
+
+	err = os.Symlink(testBcacheBackingDevPath+"/bcache", testBcacheUuidPath+"/bdev0")
+	require.NoError(t, err)
+
+	err = os.Symlink(testBcacheDevPath, testBcacheUuidPath+"/bdev0/dev")
+	require.NoError(t, err)
+
+	err = ioutil.WriteFile(testBcacheUuidPath+"/bdev0/dirty_data",
+		[]byte(dirty_data), 0644)
+	require.NoError(t, err)
+
+	err = ioutil.WriteFile(testBcacheUuidPath+"/cache_available_percent",
+		[]byte("63\n"), 0644)
+	require.NoError(t, err)
+
+	err = ioutil.WriteFile(testBcacheUuidPath+"/dirty_data",
+		[]byte("3.5G\n"), 0644)
+	require.NoError(t, err)
+
+	err = ioutil.WriteFile(testBcacheUuidPath+"/btree_cache_size",
+		[]byte("270.5M\n"), 0644)
+	require.NoError(t, err)
+
+	// the register files aren't cache sets
+	err = ioutil.WriteFile(testBcachePath+"/register", []byte{}, 0200)
+	require.NoError(t, err)
+
+	var acc testutil.Accumulator
+
+	b := &Bcache{BcachePath: testBcachePath}
+
+	err = b.Gather(&acc)
+	require.NoError(t, err)
+	assert.False(t, acc.HasMeasurement("bcache_cache_set"))
+
+	b = &Bcache{BcachePath: testBcachePath, GatherCacheSets: true}
+
+	err = b.Gather(&acc)
+	require.NoError(t, err)
+	require.Empty(t, acc.Errors)
+
+	fields := map[string]interface{}{
+		"cache_available_percent": uint64(63),
+		"dirty_data":              uint64(3758096384),
+		"btree_cache_size":        uint64(283639808),
+	}
+	tags := map[string]string{
+		"uuid": "663955a3-765a-4737-a9fd-8250a7a78411",
+	}
+	acc.AssertContainsTaggedFields(t, "bcache_cache_set", fields, tags)
+
+	err = os.RemoveAll(os.TempDir() + "/telegraf")
+	require.NoError(t, err)
+}
@@ -39,6 +39,9 @@ type Docker struct {
 	ContainerInclude []string `toml:"container_name_include"`
 	ContainerExclude []string `toml:"container_name_exclude"`
 
+	ContainerLabelInclude []string `toml:"container_label_include"`
+	ContainerLabelExclude []string `toml:"container_label_exclude"`
+
 	SSLCA              string `toml:"ssl_ca"`
 	SSLCert            string `toml:"ssl_cert"`
 	SSLKey             string `toml:"ssl_key"`
@@ -53,6 +56,9 @@ type Docker struct {
 	filtersCreated  bool
 	labelFilter     filter.Filter
 	containerFilter filter.Filter
+
+	containerLabelInclude filter.Filter
+	containerLabelExclude filter.Filter
 }
 
 // KB, MB, GB, TB, PB...human friendly
@@ -68,6 +74,10 @@ const (
 
 var (
 	sizeRegex = regexp.MustCompile(`^(\d+(\.\d+)*) ?([kKmMgGtTpP])?[bB]?$`)
+
+	// labelKeyReplacer replaces characters in label keys that cannot be
+	// represented in a tag key.
+	labelKeyReplacer = strings.NewReplacer(" ", "_", "\t", "_", "\n", "_", "\r", "_")
 )
 
 var sampleConfig = `
@@ -87,7 +97,14 @@ var sampleConfig = `
   container_name_include = []
   container_name_exclude = []
 
-  ## Timeout for docker list, info, and stats commands
+  ## Containers to include and exclude by label. Globs accepted and matched
+  ## against both the label key and "key=value", ie "telegraf.monitor=true".
+  ## Note that an empty array for both will include all containers
+  container_label_include = []
+  container_label_exclude = []
+
+  ## Timeout for docker list, info, and stats commands.  The stats timeout
+  ## applies per container, containers that time out are skipped.
   timeout = "5s"
 
   ## Whether to report for each container per-device blkio (8:0, 8:1...) and
@@ -148,6 +165,10 @@ func (d *Docker) Gather(acc telegraf.Accumulator) error {
 		if err != nil {
 			return err
 		}
+		err = d.createContainerLabelFilters()
+		if err != nil {
+			return err
+		}
 		d.filtersCreated = true
 	}
 
@@ -197,6 +218,10 @@ func (d *Docker) gatherSwarmInfo(acc telegraf.Accumulator) error {
 	defer cancel()
 	services, err := d.client.ServiceList(ctx, types.ServiceListOptions{})
 	if err != nil {
+		if isNotSwarmManagerError(err) {
+			log.Printf("D! Skipping docker swarm services, %s", err)
+			return nil
+		}
 		return err
 	}
 
@@ -260,6 +285,14 @@ func (d *Docker) gatherSwarmInfo(acc telegraf.Accumulator) error {
 	return nil
 }
 
+// isNotSwarmManagerError reports whether err was returned because the daemon
+// is not a swarm manager, in which case the swarm APIs are unavailable.
+func isNotSwarmManagerError(err error) bool {
+	msg := strings.ToLower(err.Error())
+	return strings.Contains(msg, "not a swarm manager") ||
+		strings.Contains(msg, "not part of a swarm")
+}
+
 func (d *Docker) gatherInfo(acc telegraf.Accumulator) error {
 	// Init vars
 	dataFields := make(map[string]interface{})
@@ -288,11 +321,18 @@ func (d *Docker) gatherInfo(acc telegraf.Accumulator) error {
 	// Add metrics
 	acc.AddFields("docker",
 		fields,
-		map[string]string{"engine_host": d.engine_host},
+		map[string]string{
+			"engine_host":    d.engine_host,
+			"server_version": info.ServerVersion,
+		},
 		now)
 	acc.AddFields("docker",
 		map[string]interface{}{"memory_total": info.MemTotal},
-		map[string]string{"unit": "bytes", "engine_host": d.engine_host},
+		map[string]string{
+			"unit":           "bytes",
+			"engine_host":    d.engine_host,
+			"server_version": info.ServerVersion,
+		},
 		now)
 	// Get storage metrics
 	for _, rawData := range info.DriverStatus {
@@ -368,10 +408,19 @@ func (d *Docker) gatherContainer(
 		return nil
 	}
 
+	if !d.matchContainerLabels(container.Labels) {
+		return nil
+	}
+
 	ctx, cancel := context.WithTimeout(context.Background(), d.Timeout.Duration)
 	defer cancel()
 	r, err := d.client.ContainerStats(ctx, container.ID, false)
 	if err != nil {
+		if ctx.Err() == context.DeadlineExceeded {
+			log.Printf("W! Timeout after %s getting docker stats for container %s, skipping",
+				d.Timeout.Duration, cname)
+			return nil
+		}
 		return fmt.Errorf("Error getting docker stats: %s", err.Error())
 	}
 	defer r.Body.Close()
@@ -380,15 +429,21 @@ func (d *Docker) gatherContainer(
 		if err == io.EOF {
 			return nil
 		}
+		if ctx.Err() == context.DeadlineExceeded {
+			log.Printf("W! Timeout after %s reading docker stats for container %s, skipping",
+				d.Timeout.Duration, cname)
+			return nil
+		}
 		return fmt.Errorf("Error decoding: %s", err.Error())
 	}
 	daemonOSType := r.OSType
 
 	// Add labels to tags
 	for k, label := range container.Labels {
-		if d.labelFilter.Match(k) {
-			tags[k] = label
+		if label == "" || !d.labelFilter.Match(k) {
+			continue
 		}
+		tags[sanitizeLabelKey(k)] = label
 	}
 
 	// Add whitelisted environment variables to tags
@@ -601,60 +656,59 @@ func gatherBlockIOMetrics(
 	// Make a map of devices to their block io stats
 	deviceStatMap := make(map[string]map[string]interface{})
 
-	for _, metric := range blkioStats.IoServiceBytesRecursive {
-		device := fmt.Sprintf("%d:%d", metric.Major, metric.Minor)
-		_, ok := deviceStatMap[device]
+	// deviceFields returns the field map for a device, identified the same
+	// way as the major and minor columns of /proc/diskstats.
+	deviceFields := func(major, minor uint64) map[string]interface{} {
+		device := fmt.Sprintf("%d:%d", major, minor)
+		fields, ok := deviceStatMap[device]
 		if !ok {
-			deviceStatMap[device] = make(map[string]interface{})
+			fields = make(map[string]interface{})
+			deviceStatMap[device] = fields
 		}
+		return fields
+	}
 
+	for _, metric := range blkioStats.IoServiceBytesRecursive {
 		field := fmt.Sprintf("io_service_bytes_recursive_%s", strings.ToLower(metric.Op))
-		deviceStatMap[device][field] = metric.Value
+		deviceFields(metric.Major, metric.Minor)[field] = metric.Value
 	}
 
 	for _, metric := range blkioStats.IoServicedRecursive {
-		device := fmt.Sprintf("%d:%d", metric.Major, metric.Minor)
-		_, ok := deviceStatMap[device]
-		if !ok {
-			deviceStatMap[device] = make(map[string]interface{})
-		}
-
 		field := fmt.Sprintf("io_serviced_recursive_%s", strings.ToLower(metric.Op))
-		deviceStatMap[device][field] = metric.Value
+		deviceFields(metric.Major, metric.Minor)[field] = metric.Value
 	}
 
 	for _, metric := range blkioStats.IoQueuedRecursive {
-		device := fmt.Sprintf("%d:%d", metric.Major, metric.Minor)
 		field := fmt.Sprintf("io_queue_recursive_%s", strings.ToLower(metric.Op))
-		deviceStatMap[device][field] = metric.Value
+		deviceFields(metric.Major, metric.Minor)[field] = metric.Value
 	}
 
 	for _, metric := range blkioStats.IoServiceTimeRecursive {
-		device := fmt.Sprintf("%d:%d", metric.Major, metric.Minor)
 		field := fmt.Sprintf("io_service_time_recursive_%s", strings.ToLower(metric.Op))
-		deviceStatMap[device][field] = metric.Value
+		deviceFields(metric.Major, metric.Minor)[field] = metric.Value
 	}
 
 	for _, metric := range blkioStats.IoWaitTimeRecursive {
-		device := fmt.Sprintf("%d:%d", metric.Major, metric.Minor)
 		field := fmt.Sprintf("io_wait_time_%s", strings.ToLower(metric.Op))
-		deviceStatMap[device][field] = metric.Value
+		deviceFields(metric.Major, metric.Minor)[field] = metric.Value
 	}
 
 	for _, metric := range blkioStats.IoMergedRecursive {
-		device := fmt.Sprintf("%d:%d", metric.Major, metric.Minor)
 		field := fmt.Sprintf("io_merged_recursive_%s", strings.ToLower(metric.Op))
-		deviceStatMap[device][field] = metric.Value
+		deviceFields(metric.Major, metric.Minor)[field] = metric.Value
 	}
 
 	for _, metric := range blkioStats.IoTimeRecursive {
-		device := fmt.Sprintf("%d:%d", metric.Major, metric.Minor)
-		deviceStatMap[device]["io_time_recursive"] = metric.Value
+		deviceFields(metric.Major, metric.Minor)["io_time_recursive"] = metric.Value
 	}
 
 	for _, metric := range blkioStats.SectorsRecursive {
-		device := fmt.Sprintf("%d:%d", metric.Major, metric.Minor)
-		deviceStatMap[device]["sectors_recursive"] = metric.Value
+		deviceFields(metric.Major, metric.Minor)["sectors_recursive"] = metric.Value
+	}
+
+	// Containers without any block io have nothing to report.
+	if len(deviceStatMap) == 0 {
+		return
 	}
 
 	totalStatMap := make(map[string]interface{})
@@ -706,6 +760,11 @@ func copyTags(in map[string]string) map[string]string {
 	return out
 }
 
+// sanitizeLabelKey converts a docker label key into a valid tag key.
+func sanitizeLabelKey(key string) string {
+	return labelKeyReplacer.Replace(strings.TrimSpace(key))
+}
+
 func sliceContains(in string, sl []string) bool {
 	for _, str := range sl {
 		if str == in {
@@ -750,6 +809,42 @@ func (d *Docker) createContainerFilters() error {
 	return nil
 }
 
+func (d *Docker) createContainerLabelFilters() error {
+	include, err := filter.Compile(d.ContainerLabelInclude)
+	if err != nil {
+		return err
+	}
+	exclude, err := filter.Compile(d.ContainerLabelExclude)
+	if err != nil {
+		return err
+	}
+	d.containerLabelInclude = include
+	d.containerLabelExclude = exclude
+	return nil
+}
+
+// matchContainerLabels reports whether a container should be gathered based
+// on its labels.  A container is gathered if any of its labels match the
+// include filter and none of them match the exclude filter.
+func (d *Docker) matchContainerLabels(labels map[string]string) bool {
+	matchAny := func(f filter.Filter) bool {
+		for k, v := range labels {
+			if f.Match(k) || f.Match(k+"="+v) {
+				return true
+			}
+		}
+		return false
+	}
+
+	if d.containerLabelInclude != nil && !matchAny(d.containerLabelInclude) {
+		return false
+	}
+	if d.containerLabelExclude != nil && matchAny(d.containerLabelExclude) {
+		return false
+	}
+	return true
+}
+
 func (d *Docker) createLabelFilters() error {
 	filter, err := filter.NewIncludeExcludeFilter(d.LabelInclude, d.LabelExclude)
 	if err != nil {
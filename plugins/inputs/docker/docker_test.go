@@ -3,8 +3,13 @@ package docker
 import (
 	"context"
 	"crypto/tls"
+	"errors"
+	"strings"
+	"sync/atomic"
 	"testing"
+	"time"
 
+	"github.com/influxdata/telegraf/internal"
 	"github.com/influxdata/telegraf/testutil"
 
 	"github.com/docker/docker/api/types"
@@ -245,6 +250,56 @@ func TestDockerGatherContainerStats(t *testing.T) {
 	acc.AssertDoesNotContainsTaggedFields(t, "docker_container_cpu", cpu3fields, cputags)
 }
 
+func TestDockerGatherBlockIOMetrics(t *testing.T) {
+	var acc testutil.Accumulator
+	stats := &types.StatsJSON{}
+	stats.BlkioStats.IoServiceBytesRecursive = []types.BlkioStatEntry{
+		{Major: 8, Minor: 0, Op: "Read", Value: 100},
+		{Major: 8, Minor: 0, Op: "Write", Value: 200},
+	}
+	stats.BlkioStats.IoServicedRecursive = []types.BlkioStatEntry{
+		{Major: 8, Minor: 0, Op: "Read", Value: 10},
+		{Major: 8, Minor: 0, Op: "Write", Value: 20},
+	}
+	// A device only reported in the queued stats
+	stats.BlkioStats.IoQueuedRecursive = []types.BlkioStatEntry{
+		{Major: 8, Minor: 16, Op: "Total", Value: 3},
+	}
+
+	tags := map[string]string{"container_name": "redis"}
+	gatherBlockIOMetrics(stats, &acc, tags, time.Now(), "123456789", true, false)
+
+	acc.AssertContainsTaggedFields(t,
+		"docker_container_blkio",
+		map[string]interface{}{
+			"io_service_bytes_recursive_read":  uint64(100),
+			"io_service_bytes_recursive_write": uint64(200),
+			"io_serviced_recursive_read":       uint64(10),
+			"io_serviced_recursive_write":      uint64(20),
+			"container_id":                     "123456789",
+		},
+		map[string]string{"container_name": "redis", "device": "8:0"},
+	)
+	acc.AssertContainsTaggedFields(t,
+		"docker_container_blkio",
+		map[string]interface{}{
+			"io_queue_recursive_total": uint64(3),
+			"container_id":             "123456789",
+		},
+		map[string]string{"container_name": "redis", "device": "8:16"},
+	)
+}
+
+func TestDockerGatherBlockIOMetricsNoBlockIO(t *testing.T) {
+	var acc testutil.Accumulator
+	stats := &types.StatsJSON{}
+
+	tags := map[string]string{"container_name": "redis"}
+	gatherBlockIOMetrics(stats, &acc, tags, time.Now(), "123456789", true, true)
+
+	acc.AssertDoesNotContainMeasurement(t, "docker_container_blkio")
+}
+
 func TestDocker_WindowsMemoryContainerStats(t *testing.T) {
 	var acc testutil.Accumulator
 
@@ -387,6 +442,35 @@ func TestContainerLabels(t *testing.T) {
 				"aa": "x",
 			},
 		},
+		{
+			name: "Empty values are dropped",
+			container: types.Container{
+				Labels: map[string]string{
+					"a": "x",
+					"b": "",
+				},
+			},
+			include: []string{},
+			exclude: []string{},
+			expected: map[string]string{
+				"a": "x",
+			},
+		},
+		{
+			name: "Keys are sanitized",
+			container: types.Container{
+				Labels: map[string]string{
+					"com.docker.compose.service": "web",
+					" my label\n":                "y",
+				},
+			},
+			include: []string{},
+			exclude: []string{},
+			expected: map[string]string{
+				"com.docker.compose.service": "web",
+				"my_label":                   "y",
+			},
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
@@ -409,16 +493,22 @@ func TestContainerLabels(t *testing.T) {
 			err := d.Gather(&acc)
 			require.NoError(t, err)
 
-			// Grab tags from a container metric
-			var actual map[string]string
+			// Check tags on every container metric
 			for _, metric := range acc.Metrics {
-				if metric.Measurement == "docker_container_cpu" {
-					actual = metric.Tags
+				if !strings.HasPrefix(metric.Measurement, "docker_container_") {
+					continue
+				}
+				actual := metric.Tags
+
+				for k, v := range tt.expected {
+					require.Equal(t, v, actual[k])
 				}
-			}
 
-			for k, v := range tt.expected {
-				require.Equal(t, v, actual[k])
+				for k := range tt.container.Labels {
+					if _, ok := tt.expected[k]; !ok {
+						require.NotContains(t, actual, k)
+					}
+				}
 			}
 		})
 	}
@@ -590,6 +680,112 @@ func TestContainerNames(t *testing.T) {
 	}
 }
 
+func TestContainerLabelFilter(t *testing.T) {
+	var containers = []types.Container{
+		{
+			Names:  []string{"/monitored"},
+			Labels: map[string]string{"telegraf.monitor": "true", "team": "db"},
+		},
+		{
+			Names:  []string{"/unmonitored"},
+			Labels: map[string]string{"telegraf.monitor": "false", "team": "web"},
+		},
+		{
+			Names:  []string{"/unlabeled"},
+			Labels: map[string]string{},
+		},
+	}
+
+	var tests = []struct {
+		name     string
+		include  []string
+		exclude  []string
+		expected []string
+	}{
+		{
+			name:     "Empty filters matches all",
+			include:  []string{},
+			exclude:  []string{},
+			expected: []string{"monitored", "unmonitored", "unlabeled"},
+		},
+		{
+			name:     "Include by key",
+			include:  []string{"telegraf.monitor"},
+			exclude:  []string{},
+			expected: []string{"monitored", "unmonitored"},
+		},
+		{
+			name:     "Include by key and value",
+			include:  []string{"telegraf.monitor=true"},
+			exclude:  []string{},
+			expected: []string{"monitored"},
+		},
+		{
+			name:     "Include glob",
+			include:  []string{"team=*"},
+			exclude:  []string{},
+			expected: []string{"monitored", "unmonitored"},
+		},
+		{
+			name:     "Exclude by key and value",
+			include:  []string{},
+			exclude:  []string{"team=web"},
+			expected: []string{"monitored", "unlabeled"},
+		},
+		{
+			name:     "Excluded includes",
+			include:  []string{"telegraf.monitor"},
+			exclude:  []string{"team=d*"},
+			expected: []string{"unmonitored"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var acc testutil.Accumulator
+
+			var statsCalls int32
+			newClientFunc := func(host string, tlsConfig *tls.Config) (Client, error) {
+				client := baseClient
+				client.ContainerListF = func(context.Context, types.ContainerListOptions) ([]types.Container, error) {
+					return containers, nil
+				}
+				client.ContainerStatsF = func(context.Context, string, bool) (types.ContainerStats, error) {
+					atomic.AddInt32(&statsCalls, 1)
+					return containerStats(), nil
+				}
+				return &client, nil
+			}
+
+			d := Docker{
+				newClient:             newClientFunc,
+				ContainerLabelInclude: tt.include,
+				ContainerLabelExclude: tt.exclude,
+			}
+
+			err := d.Gather(&acc)
+			require.NoError(t, err)
+
+			// Set of expected names
+			var expected = make(map[string]bool)
+			for _, v := range tt.expected {
+				expected[v] = true
+			}
+
+			// Set of actual names
+			var actual = make(map[string]bool)
+			for _, metric := range acc.Metrics {
+				if name, ok := metric.Tags["container_name"]; ok {
+					actual[name] = true
+				}
+			}
+
+			require.Equal(t, expected, actual)
+			// Filtered containers must not have their stats requested
+			require.Equal(t, int32(len(tt.expected)), atomic.LoadInt32(&statsCalls))
+		})
+	}
+}
+
 func TestDockerGatherInfo(t *testing.T) {
 	var acc testutil.Accumulator
 	d := Docker{
@@ -614,7 +810,22 @@ func TestDockerGatherInfo(t *testing.T) {
 			"n_images":                int(199),
 			"n_goroutines":            int(39),
 		},
-		map[string]string{"engine_host": "absol"},
+		map[string]string{
+			"engine_host":    "absol",
+			"server_version": "17.09.0-ce",
+		},
+	)
+
+	acc.AssertContainsTaggedFields(t,
+		"docker",
+		map[string]interface{}{
+			"memory_total": int64(3840757760),
+		},
+		map[string]string{
+			"unit":           "bytes",
+			"engine_host":    "absol",
+			"server_version": "17.09.0-ce",
+		},
 	)
 
 	acc.AssertContainsTaggedFields(t,
@@ -711,3 +922,65 @@ func TestDockerGatherSwarmInfo(t *testing.T) {
 		},
 	)
 }
+
+func TestDockerGatherSwarmInfoNotManager(t *testing.T) {
+	var acc testutil.Accumulator
+
+	newClientFunc := func(host string, tlsConfig *tls.Config) (Client, error) {
+		client := baseClient
+		client.ServiceListF = func(context.Context, types.ServiceListOptions) ([]swarm.Service, error) {
+			return nil, errors.New("Error response from daemon: This node is not a swarm manager. " +
+				"Use \"docker swarm init\" or \"docker swarm join\" to connect this node to swarm and try again.")
+		}
+		return &client, nil
+	}
+
+	d := Docker{
+		newClient:      newClientFunc,
+		GatherServices: true,
+	}
+
+	err := acc.GatherError(d.Gather)
+	require.NoError(t, err)
+	acc.AssertDoesNotContainMeasurement(t, "docker_swarm")
+	require.True(t, acc.HasMeasurement("docker_container_cpu"))
+}
+
+func TestDockerGatherContainerStatsTimeout(t *testing.T) {
+	var acc testutil.Accumulator
+
+	newClientFunc := func(host string, tlsConfig *tls.Config) (Client, error) {
+		client := baseClient
+		client.ContainerListF = func(context.Context, types.ContainerListOptions) ([]types.Container, error) {
+			return []types.Container{
+				{ID: "fast", Names: []string{"/fast"}},
+				{ID: "wedged", Names: []string{"/wedged"}},
+			}, nil
+		}
+		client.ContainerStatsF = func(ctx context.Context, containerID string, stream bool) (types.ContainerStats, error) {
+			if containerID == "wedged" {
+				// Block past the deadline like an unresponsive daemon
+				<-ctx.Done()
+				return types.ContainerStats{}, ctx.Err()
+			}
+			return containerStats(), nil
+		}
+		return &client, nil
+	}
+
+	d := Docker{
+		newClient: newClientFunc,
+		Timeout:   internal.Duration{Duration: 100 * time.Millisecond},
+	}
+
+	err := acc.GatherError(d.Gather)
+	require.NoError(t, err)
+
+	var names = make(map[string]bool)
+	for _, metric := range acc.Metrics {
+		if name, ok := metric.Tags["container_name"]; ok {
+			names[name] = true
+		}
+	}
+	require.Equal(t, map[string]bool{"fast": true}, names)
+}
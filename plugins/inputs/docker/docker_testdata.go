@@ -52,6 +52,7 @@ var info = types.Info{
 	Driver:            "devicemapper",
 	NGoroutines:       39,
 	NCPU:              4,
+	ServerVersion:     "17.09.0-ce",
 	DockerRootDir:     "/var/lib/docker",
 	NoProxy:           "",
 	BridgeNfIP6tables: true,
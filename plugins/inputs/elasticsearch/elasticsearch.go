@@ -4,6 +4,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/filter"
 	"github.com/influxdata/telegraf/internal"
 	"github.com/influxdata/telegraf/plugins/inputs"
 	jsonparser "github.com/influxdata/telegraf/plugins/parsers/json"
@@ -22,10 +23,14 @@ var mask = regexp.MustCompile(`https?:\/\/\S+:\S+@`)
 const statsPath = "/_nodes/stats"
 const statsPathLocal = "/_nodes/_local/stats"
 
+// Only request the index stats groups that are reported
+const indexStatsPath = "/_stats/docs,store,indexing,search,segments"
+
 type nodeStat struct {
 	Host       string            `json:"host"`
 	Name       string            `json:"name"`
 	Attributes map[string]string `json:"attributes"`
+	Roles      []string          `json:"roles"`
 	Indices    interface{}       `json:"indices"`
 	OS         interface{}       `json:"os"`
 	Process    interface{}       `json:"process"`
@@ -70,6 +75,35 @@ type clusterStats struct {
 	Nodes       interface{} `json:"nodes"`
 }
 
+type indexStats struct {
+	Indices map[string]struct {
+		Total indexStatsDetail `json:"total"`
+	} `json:"indices"`
+}
+
+type indexStatsDetail struct {
+	Docs struct {
+		Count   int64 `json:"count"`
+		Deleted int64 `json:"deleted"`
+	} `json:"docs"`
+	Store struct {
+		SizeInBytes int64 `json:"size_in_bytes"`
+	} `json:"store"`
+	Indexing struct {
+		IndexTotal        int64 `json:"index_total"`
+		IndexTimeInMillis int64 `json:"index_time_in_millis"`
+	} `json:"indexing"`
+	Search struct {
+		QueryTotal        int64 `json:"query_total"`
+		QueryTimeInMillis int64 `json:"query_time_in_millis"`
+		FetchTotal        int64 `json:"fetch_total"`
+		FetchTimeInMillis int64 `json:"fetch_time_in_millis"`
+	} `json:"search"`
+	Segments struct {
+		Count int64 `json:"count"`
+	} `json:"segments"`
+}
+
 type catMaster struct {
 	NodeID   string `json:"id"`
 	NodeIP   string `json:"ip"`
@@ -95,19 +129,35 @@ const sampleConfig = `
 
   ## Adjust cluster_health_level when you want to also obtain detailed health stats
   ## The options are
-  ##  - indices (default)
-  ##  - cluster
-  # cluster_health_level = "indices"
+  ##  - cluster (default)
+  ##  - indices
+  # cluster_health_level = "cluster"
 
   ## Set cluster_stats to true when you want to also obtain cluster stats from the
   ## Master node.
   cluster_stats = false
 
+  ## Set gather_index_stats to true when you want to also obtain per index stats.
+  gather_index_stats = false
+
+  ## Indices to gather stats for when gather_index_stats is true, globs
+  ## accepted.  Per default, stats are gathered for all indices.
+  # indices_include = ["logstash-*"]
+
   ## node_stats is a list of sub-stats that you want to have gathered. Valid options
   ## are "indices", "os", "process", "jvm", "thread_pool", "fs", "transport", "http",
   ## "breakers". Per default, all stats are gathered.
   # node_stats = ["jvm", "http"]
 
+  ## node_roles restricts the gathered node stats to nodes having at least one
+  ## of the given roles, ie "master", "data" or "ingest".  Per default, all
+  ## nodes are gathered.
+  # node_roles = ["data"]
+
+  ## HTTP Basic Authentication username and password.
+  # username = ""
+  # password = ""
+
   ## Optional SSL Config
   # ssl_ca = "/etc/telegraf/ca.pem"
   # ssl_cert = "/etc/telegraf/cert.pem"
@@ -125,7 +175,12 @@ type Elasticsearch struct {
 	ClusterHealth           bool
 	ClusterHealthLevel      string
 	ClusterStats            bool
+	GatherIndexStats        bool
+	IndicesInclude          []string
 	NodeStats               []string
+	NodeRoles               []string
+	Username                string
+	Password                string
 	SSLCA                   string `toml:"ssl_ca"`   // Path to CA file
 	SSLCert                 string `toml:"ssl_cert"` // Path to host cert file
 	SSLKey                  string `toml:"ssl_key"`  // Path to cert key file
@@ -133,13 +188,14 @@ type Elasticsearch struct {
 	client                  *http.Client
 	catMasterResponseTokens []string
 	isMaster                bool
+	indexFilter             filter.Filter
 }
 
 // NewElasticsearch return a new instance of Elasticsearch
 func NewElasticsearch() *Elasticsearch {
 	return &Elasticsearch{
 		HttpTimeout:        internal.Duration{Duration: time.Second * 5},
-		ClusterHealthLevel: "indices",
+		ClusterHealthLevel: "cluster",
 	}
 }
 
@@ -178,6 +234,14 @@ func (e *Elasticsearch) Gather(acc telegraf.Accumulator) error {
 		e.client = client
 	}
 
+	if e.GatherIndexStats && e.indexFilter == nil && len(e.IndicesInclude) > 0 {
+		indexFilter, err := filter.Compile(e.IndicesInclude)
+		if err != nil {
+			return err
+		}
+		e.indexFilter = indexFilter
+	}
+
 	var wg sync.WaitGroup
 	wg.Add(len(e.Servers))
 
@@ -219,6 +283,13 @@ func (e *Elasticsearch) Gather(acc telegraf.Accumulator) error {
 					return
 				}
 			}
+
+			if e.GatherIndexStats {
+				if err := e.gatherIndexStats(s+indexStatsPath, acc); err != nil {
+					acc.AddError(fmt.Errorf(mask.ReplaceAllString(err.Error(), "http(s)://XXX:XXX@")))
+					return
+				}
+			}
 		}(serv, acc)
 	}
 
@@ -281,6 +352,10 @@ func (e *Elasticsearch) gatherNodeStats(url string, acc telegraf.Accumulator) er
 			e.isMaster = (id == e.catMasterResponseTokens[0])
 		}
 
+		if !e.nodeRoleMatch(n) {
+			continue
+		}
+
 		for k, v := range n.Attributes {
 			tags["node_attribute_"+k] = v
 		}
@@ -316,6 +391,27 @@ func (e *Elasticsearch) gatherNodeStats(url string, acc telegraf.Accumulator) er
 	return nil
 }
 
+// nodeRoleMatch reports whether the node has one of the configured roles.
+// Roles are read from the "roles" list of Elasticsearch 5 and later, or from
+// the "master", "data" and "ingest" node attributes of earlier versions.
+func (e *Elasticsearch) nodeRoleMatch(n *nodeStat) bool {
+	if len(e.NodeRoles) == 0 {
+		return true
+	}
+
+	for _, role := range e.NodeRoles {
+		for _, r := range n.Roles {
+			if r == role {
+				return true
+			}
+		}
+		if v, ok := n.Attributes[role]; ok && v == "true" {
+			return true
+		}
+	}
+	return false
+}
+
 func (e *Elasticsearch) gatherClusterHealth(url string, acc telegraf.Accumulator) error {
 	healthStats := &clusterHealth{}
 	if err := e.gatherJsonData(url, healthStats); err != nil {
@@ -343,7 +439,6 @@ func (e *Elasticsearch) gatherClusterHealth(url string, acc telegraf.Accumulator
 
 	for name, health := range healthStats.Indices {
 		indexFields := map[string]interface{}{
-			"status":                health.Status,
 			"status_code":           mapHealthStatusToCode(health.Status),
 			"number_of_shards":      health.NumberOfShards,
 			"number_of_replicas":    health.NumberOfReplicas,
@@ -354,9 +449,9 @@ func (e *Elasticsearch) gatherClusterHealth(url string, acc telegraf.Accumulator
 			"unassigned_shards":     health.UnassignedShards,
 		}
 		acc.AddFields(
-			"elasticsearch_indices",
+			"elasticsearch_cluster_health_indices",
 			indexFields,
-			map[string]string{"index": name},
+			map[string]string{"index": name, "status": health.Status},
 			measurementTime,
 		)
 	}
@@ -393,8 +488,58 @@ func (e *Elasticsearch) gatherClusterStats(url string, acc telegraf.Accumulator)
 	return nil
 }
 
+// get issues a GET request to url, adding the basic authentication header
+// when credentials are configured.
+func (e *Elasticsearch) get(url string) (*http.Response, error) {
+	req, err := http.NewRequest("GET", url, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	if e.Username != "" || e.Password != "" {
+		req.SetBasicAuth(e.Username, e.Password)
+	}
+
+	return e.client.Do(req)
+}
+
+func (e *Elasticsearch) gatherIndexStats(url string, acc telegraf.Accumulator) error {
+	stats := &indexStats{}
+	if err := e.gatherJsonData(url, stats); err != nil {
+		return err
+	}
+	now := time.Now()
+
+	for name, index := range stats.Indices {
+		if e.indexFilter != nil && !e.indexFilter.Match(name) {
+			continue
+		}
+
+		total := index.Total
+		fields := map[string]interface{}{
+			"docs_count":                    total.Docs.Count,
+			"docs_deleted":                  total.Docs.Deleted,
+			"store_size_in_bytes":           total.Store.SizeInBytes,
+			"indexing_index_total":          total.Indexing.IndexTotal,
+			"indexing_index_time_in_millis": total.Indexing.IndexTimeInMillis,
+			"search_query_total":            total.Search.QueryTotal,
+			"search_query_time_in_millis":   total.Search.QueryTimeInMillis,
+			"search_fetch_total":            total.Search.FetchTotal,
+			"search_fetch_time_in_millis":   total.Search.FetchTimeInMillis,
+			"segments_count":                total.Segments.Count,
+		}
+		acc.AddFields(
+			"elasticsearch_indices",
+			fields,
+			map[string]string{"index_name": name},
+			now,
+		)
+	}
+	return nil
+}
+
 func (e *Elasticsearch) setCatMaster(url string) error {
-	r, err := e.client.Get(url)
+	r, err := e.get(url)
 	if err != nil {
 		return err
 	}
@@ -417,7 +562,7 @@ func (e *Elasticsearch) setCatMaster(url string) error {
 }
 
 func (e *Elasticsearch) gatherJsonData(url string, v interface{}) error {
-	r, err := e.client.Get(url)
+	r, err := e.get(url)
 	if err != nil {
 		return err
 	}
@@ -3,6 +3,8 @@ package elasticsearch
 import (
 	"io/ioutil"
 	"net/http"
+	"net/http/httptest"
+	"sort"
 	"strings"
 	"testing"
 
@@ -122,6 +124,48 @@ func TestGatherNodeStats(t *testing.T) {
 	checkNodeStatsResult(t, &acc)
 }
 
+func TestGatherNodeStatsNodeRoles(t *testing.T) {
+	var tests = []struct {
+		name     string
+		roles    []string
+		expected []string
+	}{
+		{
+			name:     "No filter gathers all nodes",
+			roles:    nil,
+			expected: []string{"master", "data", "legacy", "unknown"},
+		},
+		{
+			name:     "Data nodes",
+			roles:    []string{"data"},
+			expected: []string{"data", "legacy"},
+		},
+		{
+			name:     "Master or ingest nodes",
+			roles:    []string{"master", "ingest"},
+			expected: []string{"master", "data"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			es := newElasticsearchWithClient()
+			es.NodeRoles = tt.roles
+			es.client.Transport = newTransportMock(http.StatusOK, nodeStatsResponseMixedRoles)
+
+			var acc testutil.Accumulator
+			require.NoError(t, es.gatherNodeStats("junk", &acc))
+
+			var actual []string
+			for _, m := range acc.Metrics {
+				actual = append(actual, m.Tags["node_host"])
+			}
+			sort.Strings(actual)
+			sort.Strings(tt.expected)
+			require.Equal(t, tt.expected, actual)
+		})
+	}
+}
+
 func TestGatherClusterHealthEmptyClusterHealth(t *testing.T) {
 	es := newElasticsearchWithClient()
 	es.Servers = []string{"http://example.com:9200"}
@@ -138,13 +182,13 @@ func TestGatherClusterHealthEmptyClusterHealth(t *testing.T) {
 		clusterHealthExpected,
 		map[string]string{"name": "elasticsearch_telegraf"})
 
-	acc.AssertDoesNotContainsTaggedFields(t, "elasticsearch_indices",
+	acc.AssertDoesNotContainsTaggedFields(t, "elasticsearch_cluster_health_indices",
 		v1IndexExpected,
-		map[string]string{"index": "v1"})
+		map[string]string{"index": "v1", "status": "green"})
 
-	acc.AssertDoesNotContainsTaggedFields(t, "elasticsearch_indices",
+	acc.AssertDoesNotContainsTaggedFields(t, "elasticsearch_cluster_health_indices",
 		v2IndexExpected,
-		map[string]string{"index": "v2"})
+		map[string]string{"index": "v2", "status": "red"})
 }
 
 func TestGatherClusterHealthSpecificClusterHealth(t *testing.T) {
@@ -163,13 +207,13 @@ func TestGatherClusterHealthSpecificClusterHealth(t *testing.T) {
 		clusterHealthExpected,
 		map[string]string{"name": "elasticsearch_telegraf"})
 
-	acc.AssertDoesNotContainsTaggedFields(t, "elasticsearch_indices",
+	acc.AssertDoesNotContainsTaggedFields(t, "elasticsearch_cluster_health_indices",
 		v1IndexExpected,
-		map[string]string{"index": "v1"})
+		map[string]string{"index": "v1", "status": "green"})
 
-	acc.AssertDoesNotContainsTaggedFields(t, "elasticsearch_indices",
+	acc.AssertDoesNotContainsTaggedFields(t, "elasticsearch_cluster_health_indices",
 		v2IndexExpected,
-		map[string]string{"index": "v2"})
+		map[string]string{"index": "v2", "status": "red"})
 }
 
 func TestGatherClusterHealthAlsoIndicesHealth(t *testing.T) {
@@ -188,13 +232,39 @@ func TestGatherClusterHealthAlsoIndicesHealth(t *testing.T) {
 		clusterHealthExpected,
 		map[string]string{"name": "elasticsearch_telegraf"})
 
-	acc.AssertContainsTaggedFields(t, "elasticsearch_indices",
+	acc.AssertContainsTaggedFields(t, "elasticsearch_cluster_health_indices",
 		v1IndexExpected,
-		map[string]string{"index": "v1"})
+		map[string]string{"index": "v1", "status": "green"})
 
-	acc.AssertContainsTaggedFields(t, "elasticsearch_indices",
+	acc.AssertContainsTaggedFields(t, "elasticsearch_cluster_health_indices",
 		v2IndexExpected,
-		map[string]string{"index": "v2"})
+		map[string]string{"index": "v2", "status": "red"})
+}
+
+func TestGatherClusterHealthDefaultLevel(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Path {
+		case "/_cluster/health":
+			require.Equal(t, "cluster", r.URL.Query().Get("level"))
+			fmt.Fprint(w, clusterHealthResponse)
+		default:
+			fmt.Fprint(w, nodeStatsResponse)
+		}
+	}))
+	defer ts.Close()
+
+	es := NewElasticsearch()
+	es.Servers = []string{ts.URL}
+	es.ClusterHealth = true
+
+	var acc testutil.Accumulator
+	require.NoError(t, acc.GatherError(es.Gather))
+
+	acc.AssertContainsTaggedFields(t, "elasticsearch_cluster_health",
+		clusterHealthExpected,
+		map[string]string{"name": "elasticsearch_telegraf"})
+	acc.AssertDoesNotContainMeasurement(t, "elasticsearch_cluster_health_indices")
 }
 
 func TestGatherClusterStatsMaster(t *testing.T) {
@@ -267,6 +337,102 @@ func TestGatherClusterStatsNonMaster(t *testing.T) {
 	checkNodeStatsResult(t, &acc)
 }
 
+func TestGatherIndexStats(t *testing.T) {
+	es := newElasticsearchWithClient()
+	es.Servers = []string{"http://example.com:9200"}
+	es.client.Transport = newTransportMock(http.StatusOK, indexStatsResponse)
+
+	var acc testutil.Accumulator
+	require.NoError(t, es.gatherIndexStats("junk", &acc))
+
+	acc.AssertContainsTaggedFields(t, "elasticsearch_indices",
+		twitterIndexStatsExpected,
+		map[string]string{"index_name": "twitter"})
+
+	acc.AssertContainsTaggedFields(t, "elasticsearch_indices",
+		logstashIndexStatsExpected,
+		map[string]string{"index_name": "logstash-2017.12.01"})
+}
+
+func TestGatherIndexStatsIncludeFilter(t *testing.T) {
+	es := newElasticsearchWithClient()
+	es.Servers = []string{"http://example.com:9200"}
+	es.GatherIndexStats = true
+	es.IndicesInclude = []string{"logstash-*"}
+	es.client.Transport = newTransportMock(http.StatusOK, indexStatsResponse)
+
+	// The mock answers every request with the index stats, so no node stats
+	// are reported.
+	var acc testutil.Accumulator
+	require.NoError(t, acc.GatherError(es.Gather))
+
+	acc.AssertContainsTaggedFields(t, "elasticsearch_indices",
+		logstashIndexStatsExpected,
+		map[string]string{"index_name": "logstash-2017.12.01"})
+
+	acc.AssertDoesNotContainsTaggedFields(t, "elasticsearch_indices",
+		twitterIndexStatsExpected,
+		map[string]string{"index_name": "twitter"})
+}
+
+func basicAuthHandler(t *testing.T) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		username, password, ok := r.BasicAuth()
+		if !ok || username != "telegraf" || password != "secret" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		require.Equal(t, statsPathLocal, r.URL.Path)
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprint(w, nodeStatsResponse)
+	})
+}
+
+func TestGatherBasicAuth(t *testing.T) {
+	ts := httptest.NewServer(basicAuthHandler(t))
+	defer ts.Close()
+
+	es := NewElasticsearch()
+	es.Servers = []string{ts.URL}
+	es.Local = true
+	es.Username = "telegraf"
+	es.Password = "secret"
+
+	var acc testutil.Accumulator
+	require.NoError(t, acc.GatherError(es.Gather))
+	checkNodeStatsResult(t, &acc)
+}
+
+func TestGatherBasicAuthTLS(t *testing.T) {
+	ts := httptest.NewTLSServer(basicAuthHandler(t))
+	defer ts.Close()
+
+	es := NewElasticsearch()
+	es.Servers = []string{ts.URL}
+	es.Local = true
+	es.Username = "telegraf"
+	es.Password = "secret"
+	es.InsecureSkipVerify = true
+
+	var acc testutil.Accumulator
+	require.NoError(t, acc.GatherError(es.Gather))
+	checkNodeStatsResult(t, &acc)
+}
+
+func TestGatherBasicAuthUnauthorized(t *testing.T) {
+	ts := httptest.NewServer(basicAuthHandler(t))
+	defer ts.Close()
+
+	es := NewElasticsearch()
+	es.Servers = []string{ts.URL}
+	es.Local = true
+
+	var acc testutil.Accumulator
+	err := acc.GatherError(es.Gather)
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "status-code 401")
+}
+
 func newElasticsearchWithClient() *Elasticsearch {
 	es := NewElasticsearch()
 	es.client = &http.Client{}
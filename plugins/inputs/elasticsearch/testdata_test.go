@@ -66,7 +66,6 @@ var clusterHealthExpected = map[string]interface{}{
 }
 
 var v1IndexExpected = map[string]interface{}{
-	"status":                "green",
 	"status_code":           1,
 	"number_of_shards":      10,
 	"number_of_replicas":    1,
@@ -78,7 +77,6 @@ var v1IndexExpected = map[string]interface{}{
 }
 
 var v2IndexExpected = map[string]interface{}{
-	"status":                "red",
 	"status_code":           3,
 	"number_of_shards":      10,
 	"number_of_replicas":    1,
@@ -1145,3 +1143,217 @@ var clusterstatsNodesExpected = map[string]interface{}{
 const IsMasterResult = "SDFsfSDFsdfFSDSDfSFDSDF 10.206.124.66 10.206.124.66 test.host.com "
 
 const IsNotMasterResult = "junk 10.206.124.66 10.206.124.66 test.junk.com "
+
+const indexStatsResponse = `
+{
+  "_shards": {
+    "total": 20,
+    "successful": 10,
+    "failed": 0
+  },
+  "_all": {
+    "primaries": {
+      "docs": {
+        "count": 30652,
+        "deleted": 5229
+      }
+    },
+    "total": {
+      "docs": {
+        "count": 30652,
+        "deleted": 5229
+      }
+    }
+  },
+  "indices": {
+    "twitter": {
+      "primaries": {
+        "docs": {
+          "count": 29652,
+          "deleted": 5229
+        },
+        "store": {
+          "size_in_bytes": 37715234,
+          "throttle_time_in_millis": 0
+        },
+        "indexing": {
+          "index_total": 84790,
+          "index_time_in_millis": 29680,
+          "index_current": 0,
+          "index_failed": 0
+        },
+        "search": {
+          "open_contexts": 0,
+          "query_total": 1010,
+          "query_time_in_millis": 3122,
+          "query_current": 0,
+          "fetch_total": 906,
+          "fetch_time_in_millis": 271,
+          "fetch_current": 0
+        },
+        "segments": {
+          "count": 24,
+          "memory_in_bytes": 268356
+        }
+      },
+      "total": {
+        "docs": {
+          "count": 29652,
+          "deleted": 5229
+        },
+        "store": {
+          "size_in_bytes": 37715234,
+          "throttle_time_in_millis": 0
+        },
+        "indexing": {
+          "index_total": 84790,
+          "index_time_in_millis": 29680,
+          "index_current": 0,
+          "index_failed": 0
+        },
+        "search": {
+          "open_contexts": 0,
+          "query_total": 1010,
+          "query_time_in_millis": 3122,
+          "query_current": 0,
+          "fetch_total": 906,
+          "fetch_time_in_millis": 271,
+          "fetch_current": 0
+        },
+        "segments": {
+          "count": 24,
+          "memory_in_bytes": 268356
+        }
+      }
+    },
+    "logstash-2017.12.01": {
+      "primaries": {
+        "docs": {
+          "count": 1000,
+          "deleted": 0
+        },
+        "store": {
+          "size_in_bytes": 283405,
+          "throttle_time_in_millis": 0
+        },
+        "indexing": {
+          "index_total": 1000,
+          "index_time_in_millis": 408,
+          "index_current": 0,
+          "index_failed": 0
+        },
+        "search": {
+          "open_contexts": 0,
+          "query_total": 15,
+          "query_time_in_millis": 12,
+          "query_current": 0,
+          "fetch_total": 3,
+          "fetch_time_in_millis": 1,
+          "fetch_current": 0
+        },
+        "segments": {
+          "count": 5,
+          "memory_in_bytes": 19236
+        }
+      },
+      "total": {
+        "docs": {
+          "count": 1000,
+          "deleted": 0
+        },
+        "store": {
+          "size_in_bytes": 283405,
+          "throttle_time_in_millis": 0
+        },
+        "indexing": {
+          "index_total": 1000,
+          "index_time_in_millis": 408,
+          "index_current": 0,
+          "index_failed": 0
+        },
+        "search": {
+          "open_contexts": 0,
+          "query_total": 15,
+          "query_time_in_millis": 12,
+          "query_current": 0,
+          "fetch_total": 3,
+          "fetch_time_in_millis": 1,
+          "fetch_current": 0
+        },
+        "segments": {
+          "count": 5,
+          "memory_in_bytes": 19236
+        }
+      }
+    }
+  }
+}
+`
+
+var twitterIndexStatsExpected = map[string]interface{}{
+	"docs_count":                    int64(29652),
+	"docs_deleted":                  int64(5229),
+	"store_size_in_bytes":           int64(37715234),
+	"indexing_index_total":          int64(84790),
+	"indexing_index_time_in_millis": int64(29680),
+	"search_query_total":            int64(1010),
+	"search_query_time_in_millis":   int64(3122),
+	"search_fetch_total":            int64(906),
+	"search_fetch_time_in_millis":   int64(271),
+	"segments_count":                int64(24),
+}
+
+var logstashIndexStatsExpected = map[string]interface{}{
+	"docs_count":                    int64(1000),
+	"docs_deleted":                  int64(0),
+	"store_size_in_bytes":           int64(283405),
+	"indexing_index_total":          int64(1000),
+	"indexing_index_time_in_millis": int64(408),
+	"search_query_total":            int64(15),
+	"search_query_time_in_millis":   int64(12),
+	"search_fetch_total":            int64(3),
+	"search_fetch_time_in_millis":   int64(1),
+	"segments_count":                int64(5),
+}
+
+const nodeStatsResponseMixedRoles = `
+{
+  "cluster_name": "es-testcluster",
+  "nodes": {
+    "masterNodeId": {
+      "name": "master.host.com",
+      "host": "master",
+      "roles": ["master"],
+      "process": {
+        "open_file_descriptors": 100
+      }
+    },
+    "dataNodeId": {
+      "name": "data.host.com",
+      "host": "data",
+      "roles": ["data", "ingest"],
+      "process": {
+        "open_file_descriptors": 200
+      }
+    },
+    "legacyDataNodeId": {
+      "name": "legacy.host.com",
+      "host": "legacy",
+      "attributes": {
+        "master": "false",
+        "data": "true"
+      },
+      "process": {
+        "open_file_descriptors": 300
+      }
+    },
+    "unknownNodeId": {
+      "name": "unknown.host.com",
+      "host": "unknown",
+      "process": {
+        "open_file_descriptors": 400
+      }
+    }
+  }
+}
+`
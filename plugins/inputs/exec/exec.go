@@ -3,8 +3,11 @@ package exec
 import (
 	"bytes"
 	"fmt"
+	"log"
+	"os"
 	"os/exec"
 	"path/filepath"
+	"regexp"
 	"runtime"
 	"strings"
 	"sync"
@@ -28,6 +31,11 @@ const sampleConfig = `
     "/tmp/collect_*.sh"
   ]
 
+  ## Environment variables added to the environment of the commands, values
+  ## may reference the variables of the telegraf process with ${VAR}, other
+  ## uses of $ and references to unset variables are left as they are.
+  # environment = ["API_TOKEN=mytoken", "REGION=${AWS_REGION}"]
+
   ## Timeout for each command to complete.
   timeout = "5s"
 
@@ -42,9 +50,10 @@ const sampleConfig = `
 `
 
 type Exec struct {
-	Commands []string
-	Command  string
-	Timeout  internal.Duration
+	Commands    []string
+	Command     string
+	Environment []string
+	Timeout     internal.Duration
 
 	parser parsers.Parser
 
@@ -89,17 +98,15 @@ func (c CommandRunner) Run(
 	command string,
 	acc telegraf.Accumulator,
 ) ([]byte, error) {
-	split_cmd, err := shellquote.Split(command)
-	if err != nil || len(split_cmd) == 0 {
-		return nil, fmt.Errorf("exec: unable to parse command, %s", err)
+	cmd, err := NewCommand(command, e.Environment)
+	if err != nil {
+		return nil, err
 	}
 
-	cmd := exec.Command(split_cmd[0], split_cmd[1:]...)
-
 	var out bytes.Buffer
 	cmd.Stdout = &out
 
-	if err := internal.RunTimeout(cmd, e.Timeout.Duration); err != nil {
+	if err := runTimeout(cmd, e.Timeout.Duration); err != nil {
 		switch e.parser.(type) {
 		case *nagios.NagiosParser:
 			AddNagiosState(err, acc)
@@ -117,6 +124,65 @@ func (c CommandRunner) Run(
 	return out.Bytes(), nil
 }
 
+// NewCommand creates the command to run, in its own process group.
+func NewCommand(command string, environment []string) (*exec.Cmd, error) {
+	split_cmd, err := shellquote.Split(command)
+	if err != nil || len(split_cmd) == 0 {
+		return nil, fmt.Errorf("exec: unable to parse command, %s", err)
+	}
+
+	cmd := exec.Command(split_cmd[0], split_cmd[1:]...)
+	setProcessGroup(cmd)
+	if len(environment) > 0 {
+		cmd.Env = append(os.Environ(), expandEnvironment(environment)...)
+	}
+	return cmd, nil
+}
+
+// envVarRe matches the ${VAR} references in the values of the environment
+var envVarRe = regexp.MustCompile(`\$\{(\w+)\}`)
+
+// expandEnvironment expands the ${VAR} references in the values of the
+// KEY=value entries. Keys, other uses of $ and references to unset variables
+// are left as they are.
+func expandEnvironment(env []string) []string {
+	expanded := make([]string, 0, len(env))
+	for _, kv := range env {
+		i := strings.Index(kv, "=")
+		if i < 0 {
+			expanded = append(expanded, kv)
+			continue
+		}
+		value := envVarRe.ReplaceAllStringFunc(kv[i+1:], func(ref string) string {
+			if v, ok := os.LookupEnv(ref[2 : len(ref)-1]); ok {
+				return v
+			}
+			return ref
+		})
+		expanded = append(expanded, kv[:i+1]+value)
+	}
+	return expanded
+}
+
+// runTimeout runs the given command with the given timeout. If the command
+// times out, it kills the process along with the processes it started, which
+// would otherwise keep its output open and block the wait.
+func runTimeout(cmd *exec.Cmd, timeout time.Duration) error {
+	if err := cmd.Start(); err != nil {
+		return err
+	}
+	timer := time.AfterFunc(timeout, func() {
+		if err := KillProcessGroup(cmd.Process); err != nil {
+			log.Printf("E! [inputs.exec] Error killing process %d: %s", cmd.Process.Pid, err)
+		}
+	})
+	err := cmd.Wait()
+	if !timer.Stop() {
+		return internal.TimeoutErr
+	}
+	return err
+}
+
 // removeCarriageReturns removes all carriage returns from the input if the
 // OS is Windows. It does not return any errors.
 func removeCarriageReturns(b bytes.Buffer) bytes.Buffer {
@@ -175,15 +241,26 @@ func (e *Exec) SetParser(parser parsers.Parser) {
 }
 
 func (e *Exec) Gather(acc telegraf.Accumulator) error {
-	var wg sync.WaitGroup
 	// Legacy single command support
 	if e.Command != "" {
 		e.Commands = append(e.Commands, e.Command)
 		e.Command = ""
 	}
 
-	commands := make([]string, 0, len(e.Commands))
-	for _, pattern := range e.Commands {
+	var wg sync.WaitGroup
+	commands := ExpandCommands(e.Commands, acc)
+	wg.Add(len(commands))
+	for _, command := range commands {
+		go e.ProcessCommand(command, acc, &wg)
+	}
+	wg.Wait()
+	return nil
+}
+
+// ExpandCommands returns the commands to run, matching their glob patterns.
+func ExpandCommands(patterns []string, acc telegraf.Accumulator) []string {
+	commands := make([]string, 0, len(patterns))
+	for _, pattern := range patterns {
 		cmdAndArgs := strings.SplitN(pattern, " ", 2)
 		if len(cmdAndArgs) == 0 {
 			continue
@@ -213,12 +290,7 @@ func (e *Exec) Gather(acc telegraf.Accumulator) error {
 		}
 	}
 
-	wg.Add(len(commands))
-	for _, command := range commands {
-		go e.ProcessCommand(command, acc, &wg)
-	}
-	wg.Wait()
-	return nil
+	return commands
 }
 
 func init() {
@@ -3,10 +3,13 @@ package exec
 import (
 	"bytes"
 	"fmt"
+	"os"
 	"runtime"
 	"testing"
+	"time"
 
 	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/plugins/inputs"
 	"github.com/influxdata/telegraf/plugins/parsers"
 
 	"github.com/influxdata/telegraf/testutil"
@@ -144,6 +147,84 @@ func TestCommandError(t *testing.T) {
 	assert.Equal(t, acc.NFields(), 0, "No new points should have been added")
 }
 
+func TestCommandTimeout(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("Skipping test on windows")
+	}
+	parser, _ := parsers.NewInfluxParser()
+
+	// the shell keeps its output open in the sleep command it starts
+	for _, command := range []string{"sleep 10", "sh -c 'sleep 10; echo'"} {
+		e := NewExec()
+		e.Commands = []string{command}
+		e.Timeout.Duration = 100 * time.Millisecond
+		e.SetParser(parser)
+
+		var acc testutil.Accumulator
+		start := time.Now()
+		err := acc.GatherError(e.Gather)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "timed out")
+		assert.Contains(t, err.Error(), command)
+		assert.True(t, time.Since(start) < 5*time.Second, "command %s was not killed", command)
+	}
+}
+
+func TestCommandEnvironment(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("Skipping test on windows")
+	}
+	parser, _ := parsers.NewInfluxParser()
+	os.Setenv("EXEC_TEST_REGION", "eu-west-1")
+	defer os.Unsetenv("EXEC_TEST_REGION")
+
+	e := NewExec()
+	e.Commands = []string{`sh -c 'echo "test,region=$REGION,token=$API_TOKEN,home=$HOME value=1"'`}
+	e.Environment = []string{"API_TOKEN=secret", "REGION=${EXEC_TEST_REGION}"}
+	e.SetParser(parser)
+
+	var acc testutil.Accumulator
+	require.NoError(t, acc.GatherError(e.Gather))
+	acc.AssertContainsTaggedFields(t, "test",
+		map[string]interface{}{"value": float64(1)},
+		map[string]string{
+			"region": "eu-west-1",
+			"token":  "secret",
+			"home":   os.Getenv("HOME"),
+		})
+}
+
+func TestExpandEnvironment(t *testing.T) {
+	os.Setenv("EXEC_TEST_REGION", "eu-west-1")
+	defer os.Unsetenv("EXEC_TEST_REGION")
+	os.Unsetenv("EXEC_TEST_UNSET")
+
+	env := expandEnvironment([]string{
+		"REGION=${EXEC_TEST_REGION}",
+		"ZONE=${EXEC_TEST_REGION}a",
+		"PASSWORD=pa$$word$EXEC_TEST_REGION",
+		"TOKEN=${EXEC_TEST_UNSET}",
+		"${EXEC_TEST_REGION}=key",
+		"EMPTY=",
+		"INVALID",
+	})
+	assert.Equal(t, []string{
+		"REGION=eu-west-1",
+		"ZONE=eu-west-1a",
+		"PASSWORD=pa$$word$EXEC_TEST_REGION",
+		"TOKEN=${EXEC_TEST_UNSET}",
+		"${EXEC_TEST_REGION}=key",
+		"EMPTY=",
+		"INVALID",
+	}, env)
+}
+
+func TestExecIsNotServiceInput(t *testing.T) {
+	// service inputs are skipped by --test
+	_, ok := inputs.Inputs["exec"]().(telegraf.ServiceInput)
+	assert.False(t, ok)
+}
+
 func TestLineProtocolParse(t *testing.T) {
 	parser, _ := parsers.NewInfluxParser()
 	e := &Exec{
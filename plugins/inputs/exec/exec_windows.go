@@ -0,0 +1,16 @@
+// +build windows
+
+package exec
+
+import (
+	"os"
+	"os/exec"
+)
+
+func setProcessGroup(cmd *exec.Cmd) {
+}
+
+// KillProcessGroup kills the process along with the processes it started.
+func KillProcessGroup(p *os.Process) error {
+	return p.Kill()
+}
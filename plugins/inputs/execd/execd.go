@@ -0,0 +1,186 @@
+package execd
+
+import (
+	"bufio"
+	"fmt"
+	"log"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/internal"
+	"github.com/influxdata/telegraf/plugins/inputs"
+	"github.com/influxdata/telegraf/plugins/inputs/exec"
+	"github.com/influxdata/telegraf/plugins/parsers"
+)
+
+const execdSampleConfig = `
+  ## Commands array, the commands are started with telegraf and kept running
+  commands = ["/usr/bin/mycollector --foo=bar"]
+
+  ## Environment variables added to the environment of the commands, values
+  ## may reference the variables of the telegraf process with ${VAR}, other
+  ## uses of $ and references to unset variables are left as they are.
+  # environment = ["API_TOKEN=mytoken", "REGION=${AWS_REGION}"]
+
+  ## Delay before restarting a command which exited.
+  # restart_delay = "10s"
+
+  ## Data format to consume, every line of output is parsed as it is printed
+  ## so only line based formats, such as influx or graphite, can be used.
+  ## Each data format has its own unique set of configuration options, read
+  ## more about them here:
+  ## https://github.com/influxdata/telegraf/blob/master/docs/DATA_FORMATS_INPUT.md
+  data_format = "influx"
+`
+
+// maxLineSize is the longest line of output read from a command.
+const maxLineSize = 1024 * 1024
+
+// Execd keeps long running commands running and parses the metrics they
+// print on each line of output.
+type Execd struct {
+	Commands     []string
+	Environment  []string
+	RestartDelay internal.Duration
+
+	parser parsers.Parser
+
+	wg   sync.WaitGroup
+	done chan struct{}
+}
+
+func NewExecd() *Execd {
+	return &Execd{
+		RestartDelay: internal.Duration{Duration: time.Second * 10},
+	}
+}
+
+func (e *Execd) SampleConfig() string {
+	return execdSampleConfig
+}
+
+func (e *Execd) Description() string {
+	return "Run long running commands and read the metrics they output to stdout"
+}
+
+func (e *Execd) SetParser(parser parsers.Parser) {
+	e.parser = parser
+}
+
+// Gather does nothing, the metrics are added as the commands print them.
+func (e *Execd) Gather(_ telegraf.Accumulator) error {
+	return nil
+}
+
+// Start starts the commands.
+func (e *Execd) Start(acc telegraf.Accumulator) error {
+	e.done = make(chan struct{})
+	for _, command := range exec.ExpandCommands(e.Commands, acc) {
+		e.wg.Add(1)
+		go e.run(command, acc)
+	}
+	return nil
+}
+
+// Stop kills the running commands.
+func (e *Execd) Stop() {
+	if e.done == nil {
+		return
+	}
+	close(e.done)
+	e.wg.Wait()
+	e.done = nil
+}
+
+// run runs command until the plugin is stopped, restarting it when it exits.
+func (e *Execd) run(command string, acc telegraf.Accumulator) {
+	defer e.wg.Done()
+	for {
+		if err := e.runStreaming(command, acc); err != nil {
+			acc.AddError(fmt.Errorf("execd: %s for command '%s'", err, command))
+		}
+
+		select {
+		case <-e.done:
+			return
+		case <-time.After(e.RestartDelay.Duration):
+			log.Printf("D! [inputs.execd] Restarting command '%s'", command)
+		}
+	}
+}
+
+// runStreaming runs command, parsing the metrics on each line of its output,
+// until it exits or the plugin is stopped.
+func (e *Execd) runStreaming(command string, acc telegraf.Accumulator) error {
+	cmd, err := exec.NewCommand(command, e.Environment)
+	if err != nil {
+		return err
+	}
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return err
+	}
+	if err := cmd.Start(); err != nil {
+		return err
+	}
+
+	done, exited := e.done, make(chan struct{})
+	go func() {
+		select {
+		case <-done:
+			exec.KillProcessGroup(cmd.Process)
+		case <-exited:
+		}
+	}()
+
+	scanner := bufio.NewScanner(stdout)
+	scanner.Buffer(make([]byte, 4096), maxLineSize)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+			continue
+		}
+		metric, err := e.parser.ParseLine(line)
+		if err != nil {
+			acc.AddError(fmt.Errorf("execd: %s for command '%s'", err, command))
+			continue
+		}
+		switch metric.Type() {
+		case telegraf.Counter:
+			acc.AddCounter(metric.Name(), metric.Fields(), metric.Tags(), metric.Time())
+		case telegraf.Gauge:
+			acc.AddGauge(metric.Name(), metric.Fields(), metric.Tags(), metric.Time())
+		case telegraf.Summary:
+			acc.AddSummary(metric.Name(), metric.Fields(), metric.Tags(), metric.Time())
+		case telegraf.Histogram:
+			acc.AddHistogram(metric.Name(), metric.Fields(), metric.Tags(), metric.Time())
+		default:
+			acc.AddFields(metric.Name(), metric.Fields(), metric.Tags(), metric.Time())
+		}
+	}
+	scanErr := scanner.Err()
+	if scanErr != nil {
+		// stop the command, its output can no longer be read
+		exec.KillProcessGroup(cmd.Process)
+	}
+
+	err = cmd.Wait()
+	close(exited)
+	select {
+	case <-e.done:
+		return nil
+	default:
+	}
+	if scanErr != nil {
+		return scanErr
+	}
+	return err
+}
+
+func init() {
+	inputs.Add("execd", func() telegraf.Input {
+		return NewExecd()
+	})
+}
@@ -0,0 +1,152 @@
+package execd
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"runtime"
+	"testing"
+	"time"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/metric"
+	"github.com/influxdata/telegraf/plugins/parsers"
+	"github.com/influxdata/telegraf/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestExecd(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("Skipping test on windows")
+	}
+	script, err := ioutil.TempFile("", "exec")
+	require.NoError(t, err)
+	defer os.Remove(script.Name())
+	fmt.Fprint(script, "i=0\nwhile true; do\n  i=$((i+1))\n  echo \"test value=$i\"\n  sleep 0.05\ndone\n")
+	require.NoError(t, script.Close())
+
+	parser, _ := parsers.NewInfluxParser()
+	e := NewExecd()
+	e.Commands = []string{"sh " + script.Name()}
+	e.SetParser(parser)
+
+	var acc testutil.Accumulator
+	require.NoError(t, e.Start(&acc))
+	acc.Wait(3)
+	e.Stop()
+
+	// gathering does not run the commands
+	require.NoError(t, e.Gather(&acc))
+	require.True(t, len(acc.Metrics) >= 3)
+	for i := 0; i < 3; i++ {
+		assert.Equal(t, "test", acc.Metrics[i].Measurement)
+		assert.Equal(t, float64(i+1), acc.Metrics[i].Fields["value"])
+	}
+	assert.Empty(t, acc.Errors)
+}
+
+func TestExecdRestart(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("Skipping test on windows")
+	}
+	parser, _ := parsers.NewInfluxParser()
+	e := NewExecd()
+	e.Commands = []string{`sh -c 'echo "test value=1"'`}
+	e.RestartDelay.Duration = 10 * time.Millisecond
+	e.SetParser(parser)
+
+	var acc testutil.Accumulator
+	require.NoError(t, e.Start(&acc))
+	acc.Wait(3)
+	e.Stop()
+}
+
+func TestExecdLongLine(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("Skipping test on windows")
+	}
+	parser, _ := parsers.NewInfluxParser()
+	e := NewExecd()
+	e.Commands = []string{fmt.Sprintf("head -c %d /dev/zero", 2*maxLineSize)}
+	e.RestartDelay.Duration = time.Hour
+	e.SetParser(parser)
+
+	var acc testutil.Accumulator
+	require.NoError(t, e.Start(&acc))
+	acc.WaitError(1)
+	e.Stop()
+	require.NotEmpty(t, acc.Errors)
+	assert.Contains(t, acc.Errors[0].Error(), "too long")
+}
+
+// typedParser parses every line as a metric of the given type
+type typedParser struct {
+	valueType telegraf.ValueType
+}
+
+func (p *typedParser) Parse(buf []byte) ([]telegraf.Metric, error) {
+	m, err := p.ParseLine(string(buf))
+	return []telegraf.Metric{m}, err
+}
+
+func (p *typedParser) ParseLine(line string) (telegraf.Metric, error) {
+	return metric.New(line, nil, map[string]interface{}{"value": 1}, time.Now(), p.valueType)
+}
+
+func (p *typedParser) SetDefaultTags(tags map[string]string) {}
+
+// typeAccumulator records the type of the metrics added
+type typeAccumulator struct {
+	testutil.Accumulator
+	types chan telegraf.ValueType
+}
+
+func (a *typeAccumulator) AddFields(measurement string, fields map[string]interface{}, tags map[string]string, t ...time.Time) {
+	a.types <- telegraf.Untyped
+}
+
+func (a *typeAccumulator) AddCounter(measurement string, fields map[string]interface{}, tags map[string]string, t ...time.Time) {
+	a.types <- telegraf.Counter
+}
+
+func (a *typeAccumulator) AddGauge(measurement string, fields map[string]interface{}, tags map[string]string, t ...time.Time) {
+	a.types <- telegraf.Gauge
+}
+
+func (a *typeAccumulator) AddSummary(measurement string, fields map[string]interface{}, tags map[string]string, t ...time.Time) {
+	a.types <- telegraf.Summary
+}
+
+func (a *typeAccumulator) AddHistogram(measurement string, fields map[string]interface{}, tags map[string]string, t ...time.Time) {
+	a.types <- telegraf.Histogram
+}
+
+func TestExecdMetricType(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("Skipping test on windows")
+	}
+	valueTypes := []telegraf.ValueType{
+		telegraf.Untyped,
+		telegraf.Counter,
+		telegraf.Gauge,
+		telegraf.Summary,
+		telegraf.Histogram,
+	}
+	for _, valueType := range valueTypes {
+		e := NewExecd()
+		e.Commands = []string{"echo test"}
+		e.RestartDelay.Duration = time.Hour
+		e.SetParser(&typedParser{valueType: valueType})
+
+		acc := &typeAccumulator{types: make(chan telegraf.ValueType, 1)}
+		require.NoError(t, e.Start(acc))
+		select {
+		case got := <-acc.types:
+			assert.Equal(t, valueType, got)
+		case <-time.After(5 * time.Second):
+			t.Fatalf("no metric added for type %d", valueType)
+		}
+		e.Stop()
+	}
+}
@@ -14,6 +14,7 @@ import (
 	"time"
 
 	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/filter"
 	"github.com/influxdata/telegraf/internal"
 	"github.com/influxdata/telegraf/plugins/inputs"
 )
@@ -27,6 +28,12 @@ type haproxy struct {
 
 	KeepFieldNames bool
 
+	// Proxies, matched against pxname, to gather stats from
+	ProxyInclude []string `toml:"proxy_include"`
+	ProxyExclude []string `toml:"proxy_exclude"`
+
+	proxyFilter filter.Filter
+
 	// Path to CA file
 	SSLCA string `toml:"ssl_ca"`
 	// Path to host cert file
@@ -56,6 +63,12 @@ var sampleConfig = `
   ## field names.
   # keep_field_names = false
 
+  ## Only gather the proxies, matched by pxname, in proxy_include and not in
+  ## proxy_exclude; globs are supported.  The FRONTEND and BACKEND rows of a
+  ## proxy are filtered along with its servers.
+  # proxy_include = []
+  # proxy_exclude = []
+
   ## Optional SSL Config
   # ssl_ca = "/etc/telegraf/ca.pem"
   # ssl_cert = "/etc/telegraf/cert.pem"
@@ -75,6 +88,14 @@ func (r *haproxy) Description() string {
 // Reads stats from all configured servers accumulates stats.
 // Returns one of the errors encountered while gather stats (if any).
 func (g *haproxy) Gather(acc telegraf.Accumulator) error {
+	if g.proxyFilter == nil {
+		proxyFilter, err := filter.NewIncludeExcludeFilter(g.ProxyInclude, g.ProxyExclude)
+		if err != nil {
+			return fmt.Errorf("error compiling proxy filters: %s", err)
+		}
+		g.proxyFilter = proxyFilter
+	}
+
 	if len(g.Servers) == 0 {
 		return g.gatherServer("http://127.0.0.1:1936/haproxy?stats", acc)
 	}
@@ -120,14 +141,22 @@ func (g *haproxy) Gather(acc telegraf.Accumulator) error {
 	return nil
 }
 
+// socketTimeout limits both connecting to and reading from a stats socket.
+var socketTimeout = 2 * time.Second
+
 func (g *haproxy) gatherServerSocket(addr string, acc telegraf.Accumulator) error {
 	socketPath := getSocketAddr(addr)
 
-	c, err := net.Dial("unix", socketPath)
+	c, err := net.DialTimeout("unix", socketPath, socketTimeout)
 
 	if err != nil {
 		return fmt.Errorf("Could not connect to socket '%s': %s", addr, err)
 	}
+	defer c.Close()
+
+	if err := c.SetDeadline(time.Now().Add(socketTimeout)); err != nil {
+		return fmt.Errorf("Could not set deadline on socket '%s': %s", addr, err)
+	}
 
 	_, errw := c.Write([]byte("show stat\n"))
 
@@ -230,6 +259,14 @@ func (g *haproxy) importCsvResult(r io.Reader, acc telegraf.Accumulator, host st
 	}
 	headers[0] = headers[0][2:]
 
+	pxnameIndex := -1
+	for i, header := range headers {
+		if header == "pxname" {
+			pxnameIndex = i
+			break
+		}
+	}
+
 	for {
 		row, err := csvr.Read()
 		if err == io.EOF {
@@ -247,6 +284,9 @@ func (g *haproxy) importCsvResult(r io.Reader, acc telegraf.Accumulator, host st
 		if len(row) != len(headers) {
 			return fmt.Errorf("number of columns does not match number of headers. headers=%d columns=%d", len(headers), len(row))
 		}
+		if g.proxyFilter != nil && pxnameIndex >= 0 && !g.proxyFilter.Match(row[pxnameIndex]) {
+			continue
+		}
 		for i, v := range row {
 			if v == "" {
 				continue
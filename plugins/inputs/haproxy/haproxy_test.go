@@ -7,8 +7,10 @@ import (
 	"net"
 	"net/http"
 	"net/http/httptest"
+	"sort"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/influxdata/telegraf/testutil"
 	"github.com/stretchr/testify/assert"
@@ -37,6 +39,81 @@ func (s statServer) serverSocket(l net.Listener) {
 	}
 }
 
+func TestHaproxySocketPartialReads(t *testing.T) {
+	sockname := fmt.Sprintf("/tmp/test-haproxy-partial%d.sock", time.Now().UnixNano())
+	sock, err := net.Listen("unix", sockname)
+	require.NoError(t, err)
+	defer sock.Close()
+
+	go func() {
+		conn, err := sock.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		buf := make([]byte, 1024)
+		conn.Read(buf)
+		// write the response in small chunks to force partial reads
+		data := []byte(csvOutputSample)
+		for len(data) > 0 {
+			n := 100
+			if n > len(data) {
+				n = len(data)
+			}
+			conn.Write(data[:n])
+			data = data[n:]
+			time.Sleep(time.Millisecond)
+		}
+	}()
+
+	r := &haproxy{
+		Servers: []string{"socket:" + sockname},
+	}
+
+	var acc testutil.Accumulator
+	require.NoError(t, r.Gather(&acc))
+	require.Empty(t, acc.Errors)
+
+	tags := map[string]string{
+		"server": sockname,
+		"proxy":  "git",
+		"sv":     "www",
+		"type":   "server",
+	}
+	acc.AssertContainsTaggedFields(t, "haproxy", HaproxyGetFieldValues(), tags)
+}
+
+func TestHaproxySocketTimeout(t *testing.T) {
+	defer func(timeout time.Duration) { socketTimeout = timeout }(socketTimeout)
+	socketTimeout = 100 * time.Millisecond
+
+	sockname := fmt.Sprintf("/tmp/test-haproxy-timeout%d.sock", time.Now().UnixNano())
+	sock, err := net.Listen("unix", sockname)
+	require.NoError(t, err)
+	defer sock.Close()
+
+	done := make(chan struct{})
+	defer close(done)
+	go func() {
+		conn, err := sock.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		// never answer
+		<-done
+	}()
+
+	r := &haproxy{
+		Servers: []string{sockname},
+	}
+
+	var acc testutil.Accumulator
+	require.NoError(t, r.Gather(&acc))
+	require.Len(t, acc.Errors, 1)
+	assert.Contains(t, acc.Errors[0].Error(), "timeout")
+}
+
 func TestHaproxyGeneratesMetricsWithAuthentication(t *testing.T) {
 	//We create a fake server to return test data
 	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
@@ -162,6 +239,29 @@ func TestHaproxyGeneratesMetricsUsingSocket(t *testing.T) {
 	require.NotEmpty(t, acc.Errors)
 }
 
+func TestHaproxyProxyFilter(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, csvOutputSample)
+	}))
+	defer ts.Close()
+
+	r := &haproxy{
+		Servers:      []string{ts.URL},
+		ProxyInclude: []string{"w*", "http-in"},
+		ProxyExclude: []string{"http-in"},
+	}
+
+	var acc testutil.Accumulator
+	require.NoError(t, r.Gather(&acc))
+
+	var rows []string
+	for _, m := range acc.Metrics {
+		rows = append(rows, m.Tags["proxy"]+"/"+m.Tags["sv"])
+	}
+	sort.Strings(rows)
+	assert.Equal(t, []string{"www/BACKEND", "www/bck", "www/www"}, rows)
+}
+
 //When not passing server config, we default to localhost
 //We just want to make sure we did request stat from localhost
 func TestHaproxyDefaultGetFromLocalhost(t *testing.T) {
@@ -29,6 +29,8 @@ type HttpJson struct {
 	ResponseTimeout internal.Duration
 	Parameters      map[string]string
 	Headers         map[string]string
+	Body            string
+	ContentType     string
 
 	// Path to CA file
 	SSLCA string `toml:"ssl_ca"`
@@ -107,6 +109,13 @@ var sampleConfig = `
   #   event_type = "cpu_spike"
   #   threshold = "0.75"
 
+  ## Request body sent with the method, instead of the parameters which are
+  ## then included in the query. References to environment variables such
+  ## as $API_KEY are replaced when the configuration is loaded.
+  # body = '{"query": "stats", "api_key": "$API_KEY"}'
+  ## Content type of the body (default application/json)
+  # content_type = "application/json"
+
   ## HTTP Headers (all values must be strings)
   # [inputs.httpjson.headers]
   #   X-Auth-Token = "my-xauth-token"
@@ -227,7 +236,7 @@ func (h *HttpJson) sendRequest(serverURL string) (string, float64, error) {
 
 	data := url.Values{}
 	switch {
-	case h.Method == "GET":
+	case h.Body != "" || h.Method == "GET":
 		params := requestURL.Query()
 		for k, v := range h.Parameters {
 			params.Add(k, v)
@@ -241,17 +250,31 @@ func (h *HttpJson) sendRequest(serverURL string) (string, float64, error) {
 		}
 	}
 
+	reqBody := data.Encode()
+	if h.Body != "" {
+		reqBody = h.Body
+	}
+
 	// Create + send request
 	req, err := http.NewRequest(h.Method, requestURL.String(),
-		strings.NewReader(data.Encode()))
+		strings.NewReader(reqBody))
 	if err != nil {
 		return "", -1, err
 	}
+	if h.Body != "" {
+		contentType := h.ContentType
+		if contentType == "" {
+			contentType = "application/json"
+		}
+		req.Header.Set("Content-Type", contentType)
+	}
 
 	// Add header parameters
 	for k, v := range h.Headers {
 		if strings.ToLower(k) == "host" {
 			req.Host = v
+		} else if strings.ToLower(k) == "content-type" {
+			req.Header.Set(k, v)
 		} else {
 			req.Header.Add(k, v)
 		}
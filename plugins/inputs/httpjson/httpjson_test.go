@@ -5,9 +5,11 @@ import (
 	"io/ioutil"
 	"net/http"
 	"net/http/httptest"
+	"os"
 	"strings"
 	"testing"
 
+	"github.com/influxdata/telegraf/internal/config"
 	"github.com/influxdata/telegraf/testutil"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -443,6 +445,94 @@ func TestHttpJsonPOST(t *testing.T) {
 	acc.AssertContainsFields(t, "httpjson", fields)
 }
 
+// Test that the body is sent as is with its content type, and the parameters in the query
+func TestHttpJsonPOSTBody(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, err := ioutil.ReadAll(r.Body)
+		assert.NoError(t, err)
+		assert.Equal(t, `{"query": "stats", "price": {"$gt": 10}}`, string(body))
+		assert.Equal(t, "application/json", r.Header.Get("Content-Type"))
+		assert.Equal(t, "test", r.URL.Query().Get("source"))
+		w.WriteHeader(http.StatusOK)
+		fmt.Fprintln(w, validJSON2)
+	}))
+	defer ts.Close()
+
+	a := HttpJson{
+		Servers:    []string{ts.URL},
+		Method:     "POST",
+		Parameters: map[string]string{"source": "test"},
+		Body:       `{"query": "stats", "price": {"$gt": 10}}`,
+		client:     &RealHTTPClient{client: &http.Client{}},
+	}
+
+	var acc testutil.Accumulator
+	err := acc.GatherError(a.Gather)
+	require.NoError(t, err)
+	assert.True(t, acc.HasFloatField("httpjson", "market_btc_usd"))
+}
+
+// Test that $VAR references in the body are replaced when the configuration
+// is loaded
+func TestHttpJsonBodyEnvironment(t *testing.T) {
+	os.Setenv("HTTPJSON_TEST_API_KEY", "mykey")
+	defer os.Unsetenv("HTTPJSON_TEST_API_KEY")
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, err := ioutil.ReadAll(r.Body)
+		assert.NoError(t, err)
+		assert.Equal(t, `{"api_key": "mykey", "price": {"$gt": 10}}`, string(body))
+		w.WriteHeader(http.StatusOK)
+		fmt.Fprintln(w, validJSON2)
+	}))
+	defer ts.Close()
+
+	conf, err := ioutil.TempFile("", "httpjson")
+	require.NoError(t, err)
+	defer os.Remove(conf.Name())
+	fmt.Fprintf(conf, `
+[[inputs.httpjson]]
+  servers = ["%s"]
+  method = "POST"
+  body = '{"api_key": "$HTTPJSON_TEST_API_KEY", "price": {"$gt": 10}}'
+`, ts.URL)
+	require.NoError(t, conf.Close())
+
+	c := config.NewConfig()
+	require.NoError(t, c.LoadConfig(conf.Name()))
+	require.Len(t, c.Inputs, 1)
+
+	var acc testutil.Accumulator
+	require.NoError(t, acc.GatherError(c.Inputs[0].Input.Gather))
+	assert.True(t, acc.HasFloatField("httpjson", "market_btc_usd"))
+}
+
+// Test that the content type may be changed, with the configured method
+func TestHttpJsonPUTBodyContentType(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, err := ioutil.ReadAll(r.Body)
+		assert.NoError(t, err)
+		assert.Equal(t, "PUT", r.Method)
+		assert.Equal(t, "query=stats", string(body))
+		assert.Equal(t, "application/x-www-form-urlencoded", r.Header.Get("Content-Type"))
+		w.WriteHeader(http.StatusOK)
+		fmt.Fprintln(w, validJSON2)
+	}))
+	defer ts.Close()
+
+	a := HttpJson{
+		Servers:     []string{ts.URL},
+		Method:      "PUT",
+		Body:        "query=stats",
+		ContentType: "application/x-www-form-urlencoded",
+		client:      &RealHTTPClient{client: &http.Client{}},
+	}
+
+	var acc testutil.Accumulator
+	err := acc.GatherError(a.Gather)
+	require.NoError(t, err)
+}
+
 // Test response to HTTP 500
 func TestHttpJson500(t *testing.T) {
 	httpjson := genMockHttpJson(validJSON, 500)
@@ -5,6 +5,8 @@ import (
 	"errors"
 	"fmt"
 	"net/http"
+	"path"
+	"strings"
 	"sync"
 	"time"
 
@@ -14,7 +16,9 @@ import (
 )
 
 type InfluxDB struct {
-	URLs []string `toml:"urls"`
+	URLs     []string `toml:"urls"`
+	Username string
+	Password string
 	// Path to CA file
 	SSLCA string `toml:"ssl_ca"`
 	// Path to host cert file
@@ -45,6 +49,10 @@ func (*InfluxDB) SampleConfig() string {
     "http://localhost:8086/debug/vars"
   ]
 
+  ## Username and password to send using HTTP Basic Authentication.
+  # username = ""
+  # password = ""
+
   ## Optional SSL Config
   # ssl_ca = "/etc/telegraf/ca.pem"
   # ssl_cert = "/etc/telegraf/cert.pem"
@@ -143,12 +151,25 @@ func (i *InfluxDB) gatherURL(
 	shardCounter := 0
 	now := time.Now()
 
-	resp, err := i.client.Get(url)
+	req, err := http.NewRequest("GET", url, nil)
+	if err != nil {
+		return err
+	}
+
+	if i.Username != "" || i.Password != "" {
+		req.SetBasicAuth(i.Username, i.Password)
+	}
+
+	resp, err := i.client.Do(req)
 	if err != nil {
 		return err
 	}
 	defer resp.Body.Close()
 
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("%s returned HTTP status %s", url, resp.Status)
+	}
+
 	// It would be nice to be able to decode into a map[string]point, but
 	// we'll get a decoder error like:
 	// `json: cannot unmarshal array into Go value of type influxdb.point`
@@ -177,55 +198,65 @@ func (i *InfluxDB) gatherURL(
 			return err
 		}
 
-		if keyStr, ok := key.(string); ok {
-			if keyStr == "memstats" {
-				var m memstats
-				if err := dec.Decode(&m); err != nil {
-					continue
-				}
-				acc.AddFields("influxdb_memstats",
-					map[string]interface{}{
-						"alloc":           m.Alloc,
-						"total_alloc":     m.TotalAlloc,
-						"sys":             m.Sys,
-						"lookups":         m.Lookups,
-						"mallocs":         m.Mallocs,
-						"frees":           m.Frees,
-						"heap_alloc":      m.HeapAlloc,
-						"heap_sys":        m.HeapSys,
-						"heap_idle":       m.HeapIdle,
-						"heap_inuse":      m.HeapInuse,
-						"heap_released":   m.HeapReleased,
-						"heap_objects":    m.HeapObjects,
-						"stack_inuse":     m.StackInuse,
-						"stack_sys":       m.StackSys,
-						"mspan_inuse":     m.MSpanInuse,
-						"mspan_sys":       m.MSpanSys,
-						"mcache_inuse":    m.MCacheInuse,
-						"mcache_sys":      m.MCacheSys,
-						"buck_hash_sys":   m.BuckHashSys,
-						"gc_sys":          m.GCSys,
-						"other_sys":       m.OtherSys,
-						"next_gc":         m.NextGC,
-						"last_gc":         m.LastGC,
-						"pause_total_ns":  m.PauseTotalNs,
-						"pause_ns":        m.PauseNs[(m.NumGC+255)%256],
-						"num_gc":          m.NumGC,
-						"gcc_pu_fraction": m.GCCPUFraction,
-					},
-					map[string]string{
-						"url": url,
-					})
+		keyStr, _ := key.(string)
+
+		// Decode the whole value, it might be a non-object, like a string
+		// or array, in which case it is ignored.
+		var raw json.RawMessage
+		if err := dec.Decode(&raw); err != nil {
+			return err
+		}
+
+		if keyStr == "memstats" {
+			var m memstats
+			if err := json.Unmarshal(raw, &m); err != nil {
+				continue
 			}
+			acc.AddFields("influxdb_memstats",
+				map[string]interface{}{
+					"alloc":           m.Alloc,
+					"total_alloc":     m.TotalAlloc,
+					"sys":             m.Sys,
+					"lookups":         m.Lookups,
+					"mallocs":         m.Mallocs,
+					"frees":           m.Frees,
+					"heap_alloc":      m.HeapAlloc,
+					"heap_sys":        m.HeapSys,
+					"heap_idle":       m.HeapIdle,
+					"heap_inuse":      m.HeapInuse,
+					"heap_released":   m.HeapReleased,
+					"heap_objects":    m.HeapObjects,
+					"stack_inuse":     m.StackInuse,
+					"stack_sys":       m.StackSys,
+					"mspan_inuse":     m.MSpanInuse,
+					"mspan_sys":       m.MSpanSys,
+					"mcache_inuse":    m.MCacheInuse,
+					"mcache_sys":      m.MCacheSys,
+					"buck_hash_sys":   m.BuckHashSys,
+					"gc_sys":          m.GCSys,
+					"other_sys":       m.OtherSys,
+					"next_gc":         m.NextGC,
+					"last_gc":         m.LastGC,
+					"pause_total_ns":  m.PauseTotalNs,
+					"pause_ns":        m.PauseNs[(m.NumGC+255)%256],
+					"num_gc":          m.NumGC,
+					"gcc_pu_fraction": m.GCCPUFraction,
+				},
+				map[string]string{
+					"url": url,
+				})
+			continue
 		}
 
-		// Attempt to parse a whole object into a point.
-		// It might be a non-object, like a string or array.
-		// If we fail to decode it into a point, ignore it and move on.
+		// Attempt to parse the object into a point, objects of older
+		// versions only hold the values and carry the tags in their key.
 		var p point
-		if err := dec.Decode(&p); err != nil {
+		if err := json.Unmarshal(raw, &p); err != nil {
 			continue
 		}
+		if p.Name == "" {
+			p = legacyPoint(keyStr, raw)
+		}
 
 		if p.Tags == nil {
 			p.Tags = make(map[string]string)
@@ -239,6 +270,7 @@ func (i *InfluxDB) gatherURL(
 
 		if p.Name == "shard" {
 			shardCounter++
+			addShardTags(p.Tags)
 		}
 
 		// Add a tag to indicate the source of the data.
@@ -263,6 +295,71 @@ func (i *InfluxDB) gatherURL(
 	return nil
 }
 
+// legacyPoint builds a point from the httpd, write and shard objects of
+// older versions, which only hold the values, e.g.
+//   "httpd::8086": {"req": 7, "reqActive": 1}
+//   "shard:/var/lib/influxdb/data/telegraf/default/1:1": {"writeReq": 2}
+// Other objects return an empty point.
+func legacyPoint(key string, raw json.RawMessage) point {
+	parts := strings.SplitN(key, ":", 2)
+	name := parts[0]
+
+	tags := make(map[string]string)
+	switch name {
+	case "httpd":
+		if len(parts) == 2 {
+			tags["bind"] = parts[1]
+		}
+	case "shard":
+		if len(parts) == 2 {
+			if i := strings.LastIndex(parts[1], ":"); i >= 0 {
+				tags["path"] = parts[1][:i]
+				tags["id"] = parts[1][i+1:]
+			} else {
+				tags["path"] = parts[1]
+			}
+		}
+	case "write":
+	default:
+		return point{}
+	}
+
+	var values map[string]interface{}
+	if err := json.Unmarshal(raw, &values); err != nil {
+		return point{}
+	}
+	for k, v := range values {
+		if _, ok := v.(float64); !ok {
+			delete(values, k)
+		}
+	}
+
+	return point{Name: name, Tags: tags, Values: values}
+}
+
+// addShardTags sets the database, retentionPolicy and id tags of a shard
+// from its path, <data dir>/<database>/<retention policy>/<id>, when they
+// are missing.
+func addShardTags(tags map[string]string) {
+	if tags["path"] == "" {
+		return
+	}
+
+	dir, id := path.Split(path.Clean(tags["path"]))
+	dir, rp := path.Split(path.Clean(dir))
+	_, db := path.Split(path.Clean(dir))
+
+	for k, v := range map[string]string{
+		"database":        db,
+		"retentionPolicy": rp,
+		"id":              id,
+	} {
+		if _, ok := tags[k]; !ok && v != "" && v != "." && v != "/" {
+			tags[k] = v
+		}
+	}
+}
+
 func init() {
 	inputs.Add("influxdb", func() telegraf.Input {
 		return &InfluxDB{
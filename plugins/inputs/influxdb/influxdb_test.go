@@ -4,7 +4,9 @@ import (
 	"net/http"
 	"net/http/httptest"
 	"testing"
+	"time"
 
+	"github.com/influxdata/telegraf/internal"
 	"github.com/influxdata/telegraf/plugins/inputs/influxdb"
 	"github.com/influxdata/telegraf/testutil"
 	"github.com/stretchr/testify/require"
@@ -313,3 +315,159 @@ const influxReturn2 = `
 "tsm1_wal:/Users/csparr/.influxdb/wal/udp/default/1": {"name": "tsm1_wal", "tags": {"database": "udp", "path": "/Users/csparr/.influxdb/wal/udp/default/1", "retentionPolicy": "default"}, "values": {"currentSegmentDiskBytes": 193728, "oldSegmentsDiskBytes": 1008330}},
 "write": {"name": "write", "tags": null, "values": {"pointReq": 3613, "pointReqLocal": 3613, "req": 110, "subWriteOk": 110, "writeOk": 110}}
 }`
+
+func TestInfluxDBStatsMeasurements(t *testing.T) {
+	fakeInfluxServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/endpoint" {
+			_, _ = w.Write([]byte(influxReturn))
+		} else {
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer fakeInfluxServer.Close()
+
+	url := fakeInfluxServer.URL + "/endpoint"
+	plugin := &influxdb.InfluxDB{
+		URLs: []string{url},
+	}
+
+	var acc testutil.Accumulator
+	require.NoError(t, acc.GatherError(plugin.Gather))
+
+	acc.AssertContainsTaggedFields(t, "influxdb_httpd",
+		map[string]interface{}{
+			"req":           float64(7),
+			"reqActive":     float64(1),
+			"reqDurationNs": float64(4488799),
+		},
+		map[string]string{
+			"bind": ":8086",
+			"url":  url,
+		})
+
+	acc.AssertContainsTaggedFields(t, "influxdb_write",
+		map[string]interface{}{
+			"pointReq":      float64(3613),
+			"pointReqLocal": float64(3613),
+			"req":           float64(110),
+			"subWriteOk":    float64(110),
+			"writeOk":       float64(110),
+		},
+		map[string]string{
+			"url": url,
+		})
+
+	m, ok := acc.Get("influxdb_shard")
+	require.True(t, ok)
+	require.Equal(t, "udp", m.Tags["database"])
+	require.Equal(t, "default", m.Tags["retentionPolicy"])
+	require.Equal(t, "1", m.Tags["id"])
+}
+
+func TestInfluxDBLegacyLayout(t *testing.T) {
+	fakeInfluxServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/endpoint" {
+			_, _ = w.Write([]byte(influxReturnLegacy))
+		} else {
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer fakeInfluxServer.Close()
+
+	url := fakeInfluxServer.URL + "/endpoint"
+	plugin := &influxdb.InfluxDB{
+		URLs: []string{url},
+	}
+
+	var acc testutil.Accumulator
+	require.NoError(t, acc.GatherError(plugin.Gather))
+
+	acc.AssertContainsTaggedFields(t, "influxdb_httpd",
+		map[string]interface{}{
+			"req":         float64(12),
+			"queryReq":    float64(5),
+			"writeReq":    float64(7),
+			"pointsWrite": float64(3100),
+		},
+		map[string]string{
+			"bind": ":8086",
+			"url":  url,
+		})
+
+	acc.AssertContainsTaggedFields(t, "influxdb_write",
+		map[string]interface{}{
+			"pointReq":      float64(3100),
+			"pointReqLocal": float64(3100),
+			"req":           float64(7),
+			"writeOk":       float64(7),
+		},
+		map[string]string{
+			"url": url,
+		})
+
+	acc.AssertContainsTaggedFields(t, "influxdb_shard",
+		map[string]interface{}{
+			"fieldsCreate":  float64(12),
+			"seriesCreate":  float64(40),
+			"writePointsOk": float64(3100),
+			"writeReq":      float64(7),
+		},
+		map[string]string{
+			"database":        "telegraf",
+			"retentionPolicy": "default",
+			"id":              "3",
+			"path":            "/var/lib/influxdb/data/telegraf/default/3",
+			"url":             url,
+		})
+
+	acc.AssertContainsTaggedFields(t, "influxdb",
+		map[string]interface{}{
+			"n_shards": 1,
+		}, map[string]string{})
+}
+
+// InfluxDB 0.9 without name, tags and values, the tags are in the key.
+const influxReturnLegacy = `
+{
+"cmdline": ["influxd", "-config", "/etc/influxdb/influxdb.conf"],
+"httpd::8086": {"req": 12, "queryReq": 5, "writeReq": 7, "pointsWrite": 3100},
+"shard:/var/lib/influxdb/data/telegraf/default/3:3": {"fieldsCreate": 12, "seriesCreate": 40, "writePointsOk": 3100, "writeReq": 7},
+"write": {"pointReq": 3100, "pointReqLocal": 3100, "req": 7, "writeOk": 7},
+"engine:/var/lib/influxdb/data/telegraf/default/3": {"blksWrite": 3}
+}`
+
+func TestInfluxDBBasicAuthTLS(t *testing.T) {
+	fakeInfluxServer := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		username, password, ok := r.BasicAuth()
+		if !ok || username != "telegraf" || password != "secret" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		_, _ = w.Write([]byte(basicJSON))
+	}))
+	defer fakeInfluxServer.Close()
+
+	plugin := &influxdb.InfluxDB{
+		URLs:               []string{fakeInfluxServer.URL},
+		Username:           "telegraf",
+		Password:           "secret",
+		InsecureSkipVerify: true,
+		Timeout:            internal.Duration{Duration: time.Second},
+	}
+
+	var acc testutil.Accumulator
+	require.NoError(t, acc.GatherError(plugin.Gather))
+	require.Len(t, acc.Metrics, 3)
+
+	plugin = &influxdb.InfluxDB{
+		URLs:               []string{fakeInfluxServer.URL},
+		Username:           "telegraf",
+		Password:           "wrong",
+		InsecureSkipVerify: true,
+	}
+
+	acc = testutil.Accumulator{}
+	err := acc.GatherError(plugin.Gather)
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "401")
+}
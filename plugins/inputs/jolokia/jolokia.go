@@ -25,6 +25,12 @@ type Server struct {
 	Username string
 	Password string
 	Port     string
+
+	// Target of the requests in proxy mode, the service URL defaults to the
+	// RMI URL of Host and Port, and the credentials to Username and Password.
+	ServiceURL     string `toml:"service_url"`
+	TargetUser     string `toml:"target_user"`
+	TargetPassword string `toml:"target_password"`
 }
 
 type Metric struct {
@@ -50,11 +56,21 @@ type Jolokia struct {
 	jClient   JolokiaClient
 	Context   string
 	Mode      string
+	Scheme    string
 	Servers   []Server
 	Metrics   []Metric
 	Proxy     Server
 	Delimiter string
 
+	// Path to CA file
+	SSLCA string `toml:"ssl_ca"`
+	// Path to host cert file
+	SSLCert string `toml:"ssl_cert"`
+	// Path to cert key file
+	SSLKey string `toml:"ssl_key"`
+	// Use SSL but skip chain & host verification
+	InsecureSkipVerify bool
+
 	ResponseHeaderTimeout internal.Duration `toml:"response_header_timeout"`
 	ClientTimeout         internal.Duration `toml:"client_timeout"`
 }
@@ -69,7 +85,7 @@ const sampleConfig = `
   ## NOTE that your jolokia security policy must allow for POST requests.
   context = "/jolokia/"
 
-  ## This specifies the mode used
+  ## This specifies the mode used, "agent" or "proxy"
   # mode = "proxy"
   #
   ## When in proxy mode this section is used to specify further
@@ -89,6 +105,15 @@ const sampleConfig = `
   ## Includes connection time, any redirects, and reading the response body.
   # client_timeout = "4s"
 
+  ## Scheme used to reach the servers, or the proxy, "http" or "https"
+  # scheme = "https"
+  ## Optional SSL Config
+  # ssl_ca = "/etc/telegraf/ca.pem"
+  # ssl_cert = "/etc/telegraf/cert.pem"
+  # ssl_key = "/etc/telegraf/key.pem"
+  ## Use SSL but skip chain & host verification
+  # insecure_skip_verify = false
+
   ## Attribute delimiter
   ##
   ## When multiple attributes are returned for a single
@@ -103,6 +128,12 @@ const sampleConfig = `
     port = "8080"
     # username = "myuser"
     # password = "mypassword"
+    ## In proxy mode, the JMX service URL of the server, by default the RMI
+    ## URL of host and port, and its credentials, by default username and
+    ## password.
+    # service_url = "service:jmx:rmi:///jndi/rmi://127.0.0.1:8080/jmxrmi"
+    # target_user = "myuser"
+    # target_password = "mypassword"
 
   ## List of metrics collected on above servers
   ## Each metric consists in a name, a jmx path and either
@@ -161,6 +192,11 @@ func (j *Jolokia) doRequest(req *http.Request) ([]map[string]interface{}, error)
 	// Unmarshal json
 	var jsonOut []map[string]interface{}
 	if err = json.Unmarshal([]byte(body), &jsonOut); err != nil {
+		// A request rejected as a whole is answered with a single error object
+		var errorOut map[string]interface{}
+		if json.Unmarshal([]byte(body), &errorOut) == nil && errorOut["error"] != nil {
+			return nil, fmt.Errorf("Error response from url \"%s\": %v", req.URL, errorOut["error"])
+		}
 		return nil, fmt.Errorf("Error decoding JSON response: %s: %s", err, body)
 	}
 
@@ -188,19 +224,27 @@ func (j *Jolokia) prepareRequest(server Server, metrics []Metric) (*http.Request
 
 		// Add target, only in proxy mode
 		if j.Mode == "proxy" {
-			serviceUrl := fmt.Sprintf("service:jmx:rmi:///jndi/rmi://%s:%s/jmxrmi",
-				server.Host, server.Port)
+			serviceUrl := server.ServiceURL
+			if serviceUrl == "" {
+				serviceUrl = fmt.Sprintf("service:jmx:rmi:///jndi/rmi://%s:%s/jmxrmi",
+					server.Host, server.Port)
+			}
 
 			target := map[string]string{
 				"url": serviceUrl,
 			}
 
-			if server.Username != "" {
-				target["user"] = server.Username
+			user, password := server.TargetUser, server.TargetPassword
+			if user == "" && password == "" {
+				user, password = server.Username, server.Password
 			}
 
-			if server.Password != "" {
-				target["password"] = server.Password
+			if user != "" {
+				target["user"] = user
+			}
+
+			if password != "" {
+				target["password"] = password
 			}
 
 			bodyContent["target"] = target
@@ -208,7 +252,7 @@ func (j *Jolokia) prepareRequest(server Server, metrics []Metric) (*http.Request
 			proxy := j.Proxy
 
 			// Prepare ProxyURL
-			proxyUrl, err := url.Parse("http://" + proxy.Host + ":" + proxy.Port + context)
+			proxyUrl, err := url.Parse(j.scheme() + "://" + proxy.Host + ":" + proxy.Port + context)
 			if err != nil {
 				return nil, err
 			}
@@ -219,7 +263,7 @@ func (j *Jolokia) prepareRequest(server Server, metrics []Metric) (*http.Request
 			jolokiaUrl = proxyUrl
 
 		} else {
-			serverUrl, err := url.Parse("http://" + server.Host + ":" + server.Port + context)
+			serverUrl, err := url.Parse(j.scheme() + "://" + server.Host + ":" + server.Port + context)
 			if err != nil {
 				return nil, err
 			}
@@ -246,6 +290,13 @@ func (j *Jolokia) prepareRequest(server Server, metrics []Metric) (*http.Request
 	return req, nil
 }
 
+func (j *Jolokia) scheme() string {
+	if j.Scheme == "" {
+		return "http"
+	}
+	return j.Scheme
+}
+
 func (j *Jolokia) extractValues(measurement string, value interface{}, fields map[string]interface{}) {
 	if mapValues, ok := value.(map[string]interface{}); ok {
 		for k2, v2 := range mapValues {
@@ -257,13 +308,28 @@ func (j *Jolokia) extractValues(measurement string, value interface{}, fields ma
 }
 
 func (j *Jolokia) Gather(acc telegraf.Accumulator) error {
+	if j.Mode != "" && j.Mode != "agent" && j.Mode != "proxy" {
+		return fmt.Errorf("invalid mode %q, expected \"agent\" or \"proxy\"", j.Mode)
+	}
+	if j.Scheme != "" && j.Scheme != "http" && j.Scheme != "https" {
+		return fmt.Errorf("invalid scheme %q, expected \"http\" or \"https\"", j.Scheme)
+	}
 
 	if j.jClient == nil {
 		log.Println("W! DEPRECATED: the jolokia plugin has been deprecated " +
 			"in favor of the jolokia2 plugin " +
 			"(https://github.com/influxdata/telegraf/tree/master/plugins/inputs/jolokia2)")
 
-		tr := &http.Transport{ResponseHeaderTimeout: j.ResponseHeaderTimeout.Duration}
+		tlsCfg, err := internal.GetTLSConfig(
+			j.SSLCert, j.SSLKey, j.SSLCA, j.InsecureSkipVerify)
+		if err != nil {
+			return err
+		}
+
+		tr := &http.Transport{
+			ResponseHeaderTimeout: j.ResponseHeaderTimeout.Duration,
+			TLSClientConfig:       tlsCfg,
+		}
 		j.jClient = &JolokiaClientImpl{&http.Client{
 			Transport: tr,
 			Timeout:   j.ClientTimeout.Duration,
@@ -272,12 +338,13 @@ func (j *Jolokia) Gather(acc telegraf.Accumulator) error {
 
 	servers := j.Servers
 	metrics := j.Metrics
-	tags := make(map[string]string)
 
 	for _, server := range servers {
-		tags["jolokia_name"] = server.Name
-		tags["jolokia_port"] = server.Port
-		tags["jolokia_host"] = server.Host
+		tags := map[string]string{
+			"jolokia_name": server.Name,
+			"jolokia_port": server.Port,
+			"jolokia_host": server.Host,
+		}
 		fields := make(map[string]interface{})
 
 		req, err := j.prepareRequest(server, metrics)
@@ -295,10 +362,12 @@ func (j *Jolokia) Gather(acc telegraf.Accumulator) error {
 			acc.AddError(fmt.Errorf("did not receive the correct number of metrics in response. expected %d, received %d", len(metrics), len(out)))
 			continue
 		}
+		// The responses are in the order of the requests, a failed read only
+		// skips the fields of its metric.
 		for i, resp := range out {
 			if status, ok := resp["status"]; ok && status != float64(200) {
-				acc.AddError(fmt.Errorf("Not expected status value in response body (%s:%s mbean=\"%s\" attribute=\"%s\"): %3.f",
-					server.Host, server.Port, metrics[i].Mbean, metrics[i].Attribute, status))
+				acc.AddError(fmt.Errorf("Not expected status value in response body (%s:%s mbean=\"%s\" attribute=\"%s\"): %3.f: %v",
+					server.Host, server.Port, metrics[i].Mbean, metrics[i].Attribute, status, resp["error"]))
 				continue
 			} else if !ok {
 				acc.AddError(fmt.Errorf("Missing status in response body"))
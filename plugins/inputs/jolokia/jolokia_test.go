@@ -1,15 +1,22 @@
 package jolokia
 
 import (
+	"encoding/json"
+	"encoding/pem"
 	_ "fmt"
 	"io/ioutil"
+	"net"
 	"net/http"
+	"net/http/httptest"
+	"os"
 	"strings"
 	"testing"
+	"time"
 
+	"github.com/influxdata/telegraf/internal"
 	"github.com/influxdata/telegraf/testutil"
 	"github.com/stretchr/testify/assert"
-	_ "github.com/stretchr/testify/require"
+	"github.com/stretchr/testify/require"
 )
 
 const validThreeLevelMultiValueJSON = `
@@ -113,6 +120,42 @@ const validSingleValueJSON = `
   }
 ]`
 
+const partialBulkResponseJSON = `
+[
+  {
+    "request":{
+      "mbean":"java.lang:type=Memory",
+      "attribute":"HeapMemoryUsage",
+      "type":"read"
+    },
+    "value":{
+      "init":67108864,
+      "committed":456130560,
+      "max":477626368,
+      "used":203288528
+    },
+    "timestamp":1446129191,
+    "status":200
+  },
+  {
+    "request":{
+      "mbean":"java.lang:type=Missing",
+      "attribute":"NonHeapMemoryUsage",
+      "type":"read"
+    },
+    "error_type":"javax.management.InstanceNotFoundException",
+    "error":"javax.management.InstanceNotFoundException : java.lang:type=Missing",
+    "status":404
+  }
+]`
+
+const errorResponseJSON = `
+{
+  "error_type":"java.lang.IllegalArgumentException",
+  "error":"java.lang.IllegalArgumentException : Invalid object name",
+  "status":400
+}`
+
 const invalidJSON = "I don't think this is JSON"
 
 const empty = ""
@@ -266,3 +309,208 @@ func TestHttpInvalidJson(t *testing.T) {
 	assert.Equal(t, 0, len(acc.Metrics))
 	assert.Contains(t, err.Error(), "Error decoding JSON response")
 }
+
+// Test that failed reads in a bulk response only skip their own metric
+func TestHttpJsonBulkResponsePartialFailure(t *testing.T) {
+	missingMetric := Metric{Name: "missing",
+		Mbean: "java.lang:type=Missing", Attribute: "NonHeapMemoryUsage"}
+	jolokia := genJolokiaClientStub(partialBulkResponseJSON, 200, Servers, []Metric{HeapMetric, missingMetric})
+
+	var acc testutil.Accumulator
+	err := jolokia.Gather(&acc)
+
+	assert.Nil(t, err)
+	assert.Equal(t, 1, len(acc.Metrics))
+	require.Len(t, acc.Errors, 1)
+	assert.Contains(t, acc.Errors[0].Error(), "java.lang:type=Missing")
+	assert.Contains(t, acc.Errors[0].Error(), "InstanceNotFoundException")
+
+	fields := map[string]interface{}{
+		"heap_memory_usage_init":      67108864.0,
+		"heap_memory_usage_committed": 456130560.0,
+		"heap_memory_usage_max":       477626368.0,
+		"heap_memory_usage_used":      203288528.0,
+	}
+	tags := map[string]string{
+		"jolokia_host": "127.0.0.1",
+		"jolokia_port": "8080",
+		"jolokia_name": "as1",
+	}
+	acc.AssertContainsTaggedFields(t, "jolokia", fields, tags)
+}
+
+// Test that each server keeps its own tags
+func TestHttpJsonMultipleServers(t *testing.T) {
+	servers := []Server{
+		{Name: "as1", Host: "127.0.0.1", Port: "8080"},
+		{Name: "as2", Host: "127.0.0.2", Port: "8081"},
+	}
+	jolokia := genJolokiaClientStub(validMultiValueJSON, 200, servers, []Metric{HeapMetric})
+
+	var acc testutil.Accumulator
+	require.NoError(t, acc.GatherError(jolokia.Gather))
+	require.Equal(t, 2, len(acc.Metrics))
+
+	for i, server := range servers {
+		assert.Equal(t, map[string]string{
+			"jolokia_host": server.Host,
+			"jolokia_port": server.Port,
+			"jolokia_name": server.Name,
+		}, acc.Metrics[i].Tags)
+	}
+}
+
+// Test that a request rejected as a whole reports the Jolokia error
+func TestHttpErrorResponse(t *testing.T) {
+	jolokia := genJolokiaClientStub(errorResponseJSON, 200, Servers,
+		[]Metric{UsedHeapMetric})
+
+	var acc testutil.Accumulator
+	err := acc.GatherError(jolokia.Gather)
+
+	assert.Error(t, err)
+	assert.Equal(t, 0, len(acc.Metrics))
+	assert.Contains(t, err.Error(), "Invalid object name")
+}
+
+// Test that proxy mode wraps each read with the target of the server
+func TestPrepareRequestProxy(t *testing.T) {
+	jolokia := &Jolokia{
+		Context: "/jolokia/",
+		Mode:    "proxy",
+		Proxy:   Server{Host: "proxy", Port: "8080"},
+	}
+	servers := []Server{
+		{Host: "as1", Port: "9010", Username: "user", Password: "pass"},
+		{
+			Host:           "as2",
+			Port:           "9010",
+			Username:       "user",
+			ServiceURL:     "service:jmx:remote+http://as2:9990",
+			TargetUser:     "jmxuser",
+			TargetPassword: "jmxpass",
+		},
+	}
+	targets := []map[string]interface{}{
+		{"url": "service:jmx:rmi:///jndi/rmi://as1:9010/jmxrmi", "user": "user", "password": "pass"},
+		{"url": "service:jmx:remote+http://as2:9990", "user": "jmxuser", "password": "jmxpass"},
+	}
+
+	for i, server := range servers {
+		req, err := jolokia.prepareRequest(server, []Metric{HeapMetric, NonHeapMetric})
+		require.NoError(t, err)
+		assert.Equal(t, "http://proxy:8080/jolokia/", req.URL.String())
+
+		var body []map[string]interface{}
+		require.NoError(t, json.NewDecoder(req.Body).Decode(&body))
+		require.Len(t, body, 2)
+		for _, read := range body {
+			assert.Equal(t, targets[i], read["target"])
+		}
+	}
+}
+
+// Test that agent mode does not send a target
+func TestPrepareRequestAgent(t *testing.T) {
+	jolokia := &Jolokia{Context: "/jolokia/", Mode: "agent"}
+	server := Server{Host: "as1", Port: "8080", ServiceURL: "service:jmx:remote+http://as1:9990"}
+
+	req, err := jolokia.prepareRequest(server, []Metric{HeapMetric})
+	require.NoError(t, err)
+	assert.Equal(t, "http://as1:8080/jolokia/", req.URL.String())
+
+	var body []map[string]interface{}
+	require.NoError(t, json.NewDecoder(req.Body).Decode(&body))
+	require.Len(t, body, 1)
+	assert.NotContains(t, body[0], "target")
+}
+
+func TestInvalidMode(t *testing.T) {
+	jolokia := genJolokiaClientStub(validMultiValueJSON, 200, Servers, []Metric{HeapMetric})
+	jolokia.Mode = "bogus"
+
+	var acc testutil.Accumulator
+	assert.Error(t, acc.GatherError(jolokia.Gather))
+}
+
+// Test requests over HTTPS to a server enforcing basic authentication
+func TestHttpsBasicAuth(t *testing.T) {
+	ts := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		user, password, ok := r.BasicAuth()
+		if !ok || user != "myuser" || password != "mypassword" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		w.Write([]byte(validMultiValueJSON))
+	}))
+	defer ts.Close()
+
+	caFile, err := ioutil.TempFile("", "jolokia-ca")
+	require.NoError(t, err)
+	defer os.Remove(caFile.Name())
+	require.NoError(t, pem.Encode(caFile, &pem.Block{Type: "CERTIFICATE", Bytes: ts.Certificate().Raw}))
+	require.NoError(t, caFile.Close())
+
+	host, port, err := net.SplitHostPort(strings.TrimPrefix(ts.URL, "https://"))
+	require.NoError(t, err)
+
+	newJolokia := func(username string) *Jolokia {
+		return &Jolokia{
+			Context:   "/jolokia/",
+			Scheme:    "https",
+			SSLCA:     caFile.Name(),
+			Delimiter: "_",
+			Servers: []Server{{
+				Name:     "as1",
+				Host:     host,
+				Port:     port,
+				Username: username,
+				Password: "mypassword",
+			}},
+			Metrics:       []Metric{HeapMetric},
+			ClientTimeout: DefaultClientTimeout,
+		}
+	}
+
+	var acc testutil.Accumulator
+	require.NoError(t, acc.GatherError(newJolokia("myuser").Gather))
+	require.Equal(t, 1, len(acc.Metrics))
+	assert.Equal(t, 203288528.0, acc.Metrics[0].Fields["heap_memory_usage_used"])
+
+	acc = testutil.Accumulator{}
+	err = acc.GatherError(newJolokia("wronguser").Gather)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "has status code 401")
+
+	// the server certificate is not trusted without the CA
+	jolokia := newJolokia("myuser")
+	jolokia.SSLCA = ""
+	acc = testutil.Accumulator{}
+	assert.Error(t, acc.GatherError(jolokia.Gather))
+}
+
+// Test that a server which does not answer fails the request
+func TestClientTimeout(t *testing.T) {
+	done := make(chan struct{})
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-done
+	}))
+	defer ts.Close()
+	defer close(done)
+
+	host, port, err := net.SplitHostPort(strings.TrimPrefix(ts.URL, "http://"))
+	require.NoError(t, err)
+
+	jolokia := &Jolokia{
+		Context:               "/jolokia/",
+		Servers:               []Server{{Name: "as1", Host: host, Port: port}},
+		Metrics:               []Metric{HeapMetric},
+		ResponseHeaderTimeout: DefaultResponseHeaderTimeout,
+		ClientTimeout:         internal.Duration{Duration: 100 * time.Millisecond},
+	}
+
+	var acc testutil.Accumulator
+	start := time.Now()
+	assert.Error(t, acc.GatherError(jolokia.Gather))
+	assert.True(t, time.Since(start) < 2*time.Second)
+}
@@ -3,6 +3,8 @@ package kafka_consumer
 import (
 	"fmt"
 	"log"
+	"regexp"
+	"strconv"
 	"strings"
 	"sync"
 
@@ -16,10 +18,12 @@ import (
 )
 
 type Kafka struct {
-	ConsumerGroup string
-	Topics        []string
-	Brokers       []string
-	MaxMessageLen int
+	ConsumerGroup        string
+	Topics               []string
+	TopicRegexps         []string
+	TopicRefreshInterval internal.Duration
+	Brokers              []string
+	MaxMessageLen        int
 
 	Cluster *cluster.Consumer
 
@@ -45,6 +49,12 @@ type Kafka struct {
 	Offset string
 	parser parsers.Parser
 
+	// Report the lag of the consumer group on every gather
+	GatherOffsetLag bool
+	client          sarama.Client
+	// the topics subscribed through topic_regexps
+	topicRegexp *regexp.Regexp
+
 	sync.Mutex
 
 	// channel for all incoming kafka messages
@@ -66,6 +76,11 @@ var sampleConfig = `
   brokers = ["localhost:9092"]
   ## topic(s) to consume
   topics = ["telegraf"]
+  ## regular expressions of additional topics to consume, topics created
+  ## later are picked up when the metadata is refreshed
+  # topic_regexps = ["^events\\..*"]
+  ## how often the topics of the brokers are refreshed (default 10m)
+  # topic_refresh_interval = "10m"
 
   ## Optional SSL Config
   # ssl_ca = "/etc/telegraf/ca.pem"
@@ -74,7 +89,7 @@ var sampleConfig = `
   ## Use SSL but skip chain & host verification
   # insecure_skip_verify = false
 
-  ## Optional SASL Config
+  ## Optional SASL Config, only the PLAIN mechanism is supported
   # sasl_username = "kafka"
   # sasl_password = "secret"
 
@@ -92,6 +107,10 @@ var sampleConfig = `
   ## Maximum length of a message to consume, in bytes (default 0/unlimited);
   ## larger messages are dropped
   max_message_len = 65536
+
+  ## Report the offset lag of the consumer group for every partition of the
+  ## topics on each interval
+  # gather_offset_lag = false
 `
 
 func (k *Kafka) SampleConfig() string {
@@ -113,13 +132,56 @@ func (k *Kafka) Start(acc telegraf.Accumulator) error {
 
 	k.acc = acc
 
+	config, err := k.newConfig()
+	if err != nil {
+		return err
+	}
+
+	if k.Cluster == nil {
+		k.Cluster, clusterErr = cluster.NewConsumer(
+			k.Brokers,
+			k.ConsumerGroup,
+			k.Topics,
+			config,
+		)
+
+		if clusterErr != nil {
+			log.Printf("E! Error when creating Kafka Consumer, brokers: %v, topics: %v, topic regexps: %v\n",
+				k.Brokers, k.Topics, k.TopicRegexps)
+			return clusterErr
+		}
+
+		// Setup message and error channels
+		k.in = k.Cluster.Messages()
+		k.errs = k.Cluster.Errors()
+	}
+
+	k.topicRegexp = config.Group.Topics.Whitelist
+	if k.GatherOffsetLag && k.client == nil {
+		k.client, err = sarama.NewClient(k.Brokers, &config.Config)
+		if err != nil {
+			log.Printf("E! Error when creating Kafka client, brokers: %v\n", k.Brokers)
+			return err
+		}
+	}
+
+	k.done = make(chan struct{})
+	// Start the kafka message reader
+	go k.receiver()
+	log.Printf("I! Started the kafka consumer service, brokers: %v, topics: %v, topic regexps: %v\n",
+		k.Brokers, k.Topics, k.TopicRegexps)
+	return nil
+}
+
+// newConfig creates the consumer config from the TLS, SASL and offset options
+func (k *Kafka) newConfig() (*cluster.Config, error) {
 	config := cluster.NewConfig()
 	config.Consumer.Return.Errors = true
 
 	tlsConfig, err := internal.GetTLSConfig(
 		k.SSLCert, k.SSLKey, k.SSLCA, k.InsecureSkipVerify)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
 	if tlsConfig != nil {
@@ -135,6 +197,18 @@ func (k *Kafka) Start(acc telegraf.Accumulator) error {
 		config.Net.SASL.Enable = true
 	}
 
+	if len(k.TopicRegexps) > 0 {
+		whitelist, err := compileTopicRegexps(k.TopicRegexps)
+		if err != nil {
+			return nil, err
+		}
+		config.Group.Topics.Whitelist = whitelist
+		config.Metadata.Full = true
+	}
+	if k.TopicRefreshInterval.Duration > 0 {
+		config.Metadata.RefreshFrequency = k.TopicRefreshInterval.Duration
+	}
+
 	switch strings.ToLower(k.Offset) {
 	case "oldest", "":
 		config.Consumer.Offsets.Initial = sarama.OffsetOldest
@@ -146,31 +220,20 @@ func (k *Kafka) Start(acc telegraf.Accumulator) error {
 		config.Consumer.Offsets.Initial = sarama.OffsetOldest
 	}
 
-	if k.Cluster == nil {
-		k.Cluster, clusterErr = cluster.NewConsumer(
-			k.Brokers,
-			k.ConsumerGroup,
-			k.Topics,
-			config,
-		)
+	return config, nil
+}
 
-		if clusterErr != nil {
-			log.Printf("E! Error when creating Kafka Consumer, brokers: %v, topics: %v\n",
-				k.Brokers, k.Topics)
-			return clusterErr
+// compileTopicRegexps combines the regular expressions into one matching a
+// topic if any of them matches
+func compileTopicRegexps(exprs []string) (*regexp.Regexp, error) {
+	parts := make([]string, 0, len(exprs))
+	for _, expr := range exprs {
+		if _, err := regexp.Compile(expr); err != nil {
+			return nil, fmt.Errorf("invalid topic regexp %q: %s", expr, err)
 		}
-
-		// Setup message and error channels
-		k.in = k.Cluster.Messages()
-		k.errs = k.Cluster.Errors()
+		parts = append(parts, "(?:"+expr+")")
 	}
-
-	k.done = make(chan struct{})
-	// Start the kafka message reader
-	go k.receiver()
-	log.Printf("I! Started the kafka consumer service, brokers: %v, topics: %v\n",
-		k.Brokers, k.Topics)
-	return nil
+	return regexp.Compile(strings.Join(parts, "|"))
 }
 
 // receiver() reads all incoming messages from the consumer, and parses them into
@@ -217,12 +280,118 @@ func (k *Kafka) Stop() {
 	if err := k.Cluster.Close(); err != nil {
 		k.acc.AddError(fmt.Errorf("Error closing consumer: %s\n", err.Error()))
 	}
+	if k.client != nil {
+		if err := k.client.Close(); err != nil {
+			k.acc.AddError(fmt.Errorf("Error closing client: %s\n", err.Error()))
+		}
+	}
 }
 
 func (k *Kafka) Gather(acc telegraf.Accumulator) error {
+	if k.GatherOffsetLag && k.client != nil {
+		return k.gatherOffsetLag(acc)
+	}
+	return nil
+}
+
+// gatherOffsetLag reports how far the committed offsets of the consumer group
+// are behind the newest offset of every partition. Partitions without a
+// committed offset are compared against their oldest offset.
+func (k *Kafka) gatherOffsetLag(acc telegraf.Accumulator) error {
+	coordinator, err := k.client.Coordinator(k.ConsumerGroup)
+	if err != nil {
+		return err
+	}
+
+	type topicPartition struct {
+		topic     string
+		partition int32
+	}
+	topics, err := k.subscribedTopics()
+	if err != nil {
+		return err
+	}
+
+	var partitions []topicPartition
+	request := &sarama.OffsetFetchRequest{ConsumerGroup: k.ConsumerGroup, Version: 1}
+	for _, topic := range topics {
+		ids, err := k.client.Partitions(topic)
+		if err != nil {
+			acc.AddError(fmt.Errorf("Error getting partitions of topic %s: %s", topic, err))
+			continue
+		}
+		for _, id := range ids {
+			request.AddPartition(topic, id)
+			partitions = append(partitions, topicPartition{topic, id})
+		}
+	}
+	if len(partitions) == 0 {
+		return nil
+	}
+
+	response, err := coordinator.FetchOffset(request)
+	if err != nil {
+		return err
+	}
+
+	for _, tp := range partitions {
+		newest, err := k.client.GetOffset(tp.topic, tp.partition, sarama.OffsetNewest)
+		if err != nil {
+			acc.AddError(err)
+			continue
+		}
+
+		current := int64(-1)
+		if block := response.GetBlock(tp.topic, tp.partition); block != nil && block.Err == sarama.ErrNoError {
+			current = block.Offset
+		}
+		if current < 0 {
+			current, err = k.client.GetOffset(tp.topic, tp.partition, sarama.OffsetOldest)
+			if err != nil {
+				acc.AddError(err)
+				continue
+			}
+		}
+
+		tags := map[string]string{
+			"consumer_group": k.ConsumerGroup,
+			"topic":          tp.topic,
+			"partition":      strconv.Itoa(int(tp.partition)),
+		}
+		fields := map[string]interface{}{
+			"lag":            newest - current,
+			"current_offset": current,
+		}
+		acc.AddFields("kafka_consumer_lag", fields, tags)
+	}
 	return nil
 }
 
+// subscribedTopics returns the topics of the consumer, the listed ones and
+// the known topics matching topic_regexps
+func (k *Kafka) subscribedTopics() ([]string, error) {
+	if k.topicRegexp == nil {
+		return k.Topics, nil
+	}
+
+	known, err := k.client.Topics()
+	if err != nil {
+		return nil, err
+	}
+
+	topics := append([]string(nil), k.Topics...)
+	listed := make(map[string]bool, len(k.Topics))
+	for _, topic := range k.Topics {
+		listed[topic] = true
+	}
+	for _, topic := range known {
+		if !listed[topic] && k.topicRegexp.MatchString(topic) {
+			topics = append(topics, topic)
+		}
+	}
+	return topics, nil
+}
+
 func init() {
 	inputs.Add("kafka_consumer", func() telegraf.Input {
 		return &Kafka{}
@@ -1,14 +1,18 @@
 package kafka_consumer
 
 import (
+	"sort"
 	"strings"
 	"testing"
+	"time"
 
+	"github.com/influxdata/telegraf/internal"
 	"github.com/influxdata/telegraf/plugins/parsers"
 	"github.com/influxdata/telegraf/testutil"
 
 	"github.com/Shopify/sarama"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 const (
@@ -140,6 +144,181 @@ func TestRunParserAndGatherJSON(t *testing.T) {
 		})
 }
 
+func TestNewConfig(t *testing.T) {
+	k := &Kafka{
+		InsecureSkipVerify: true,
+		SASLUsername:       "telegraf",
+		SASLPassword:       "secret",
+		Offset:             "newest",
+	}
+
+	config, err := k.newConfig()
+	require.NoError(t, err)
+
+	assert.True(t, config.Net.TLS.Enable)
+	require.NotNil(t, config.Net.TLS.Config)
+	assert.True(t, config.Net.TLS.Config.InsecureSkipVerify)
+
+	assert.True(t, config.Net.SASL.Enable)
+	assert.True(t, config.Net.SASL.Handshake)
+	assert.Equal(t, "telegraf", config.Net.SASL.User)
+	assert.Equal(t, "secret", config.Net.SASL.Password)
+
+	assert.Equal(t, sarama.OffsetNewest, config.Consumer.Offsets.Initial)
+	assert.True(t, config.Consumer.Return.Errors)
+	require.NoError(t, config.Validate())
+}
+
+func TestNewConfigPlaintext(t *testing.T) {
+	k := &Kafka{}
+
+	config, err := k.newConfig()
+	require.NoError(t, err)
+
+	assert.False(t, config.Net.TLS.Enable)
+	assert.Nil(t, config.Net.TLS.Config)
+	assert.False(t, config.Net.SASL.Enable)
+	assert.Equal(t, sarama.OffsetOldest, config.Consumer.Offsets.Initial)
+}
+
+func TestNewConfigInvalidCA(t *testing.T) {
+	k := &Kafka{SSLCA: "/nonexistent/ca.pem"}
+
+	_, err := k.newConfig()
+	require.Error(t, err)
+}
+
+func TestGatherOffsetLag(t *testing.T) {
+	broker := sarama.NewMockBroker(t, 1)
+	defer broker.Close()
+
+	broker.SetHandlerByMap(map[string]sarama.MockResponse{
+		"MetadataRequest": sarama.NewMockMetadataResponse(t).
+			SetBroker(broker.Addr(), broker.BrokerID()).
+			SetLeader("telegraf", 0, broker.BrokerID()).
+			SetLeader("telegraf", 1, broker.BrokerID()),
+		"OffsetRequest": sarama.NewMockOffsetResponse(t).
+			SetOffset("telegraf", 0, sarama.OffsetNewest, 100).
+			SetOffset("telegraf", 0, sarama.OffsetOldest, 0).
+			SetOffset("telegraf", 1, sarama.OffsetNewest, 50).
+			SetOffset("telegraf", 1, sarama.OffsetOldest, 10),
+		"ConsumerMetadataRequest": sarama.NewMockConsumerMetadataResponse(t).
+			SetCoordinator("test", broker),
+		"OffsetFetchRequest": sarama.NewMockOffsetFetchResponse(t).
+			SetOffset("test", "telegraf", 0, 80, "", sarama.ErrNoError).
+			SetOffset("test", "telegraf", 1, -1, "", sarama.ErrNoError),
+	})
+
+	client, err := sarama.NewClient([]string{broker.Addr()}, sarama.NewConfig())
+	require.NoError(t, err)
+	defer client.Close()
+
+	k, _ := newTestKafka()
+	k.GatherOffsetLag = true
+	k.client = client
+
+	acc := testutil.Accumulator{}
+	require.NoError(t, acc.GatherError(k.Gather))
+
+	acc.AssertContainsTaggedFields(t, "kafka_consumer_lag",
+		map[string]interface{}{"lag": int64(20), "current_offset": int64(80)},
+		map[string]string{"consumer_group": "test", "topic": "telegraf", "partition": "0"})
+	// no committed offset, the lag is counted from the oldest offset
+	acc.AssertContainsTaggedFields(t, "kafka_consumer_lag",
+		map[string]interface{}{"lag": int64(40), "current_offset": int64(10)},
+		map[string]string{"consumer_group": "test", "topic": "telegraf", "partition": "1"})
+}
+
+func TestGatherOffsetLagTopicRegexps(t *testing.T) {
+	broker := sarama.NewMockBroker(t, 1)
+	defer broker.Close()
+
+	broker.SetHandlerByMap(map[string]sarama.MockResponse{
+		"MetadataRequest": sarama.NewMockMetadataResponse(t).
+			SetBroker(broker.Addr(), broker.BrokerID()).
+			SetLeader("telegraf", 0, broker.BrokerID()).
+			SetLeader("events.clicks", 0, broker.BrokerID()).
+			SetLeader("logs", 0, broker.BrokerID()),
+		"OffsetRequest": sarama.NewMockOffsetResponse(t).
+			SetOffset("telegraf", 0, sarama.OffsetNewest, 100).
+			SetOffset("events.clicks", 0, sarama.OffsetNewest, 30).
+			SetOffset("logs", 0, sarama.OffsetNewest, 70),
+		"ConsumerMetadataRequest": sarama.NewMockConsumerMetadataResponse(t).
+			SetCoordinator("test", broker),
+		"OffsetFetchRequest": sarama.NewMockOffsetFetchResponse(t).
+			SetOffset("test", "telegraf", 0, 80, "", sarama.ErrNoError).
+			SetOffset("test", "events.clicks", 0, 25, "", sarama.ErrNoError).
+			SetOffset("test", "logs", 0, 10, "", sarama.ErrNoError),
+	})
+
+	client, err := sarama.NewClient([]string{broker.Addr()}, sarama.NewConfig())
+	require.NoError(t, err)
+	defer client.Close()
+
+	k, _ := newTestKafka()
+	k.TopicRegexps = []string{`^events\.`}
+	k.topicRegexp, err = compileTopicRegexps(k.TopicRegexps)
+	require.NoError(t, err)
+	k.GatherOffsetLag = true
+	k.client = client
+
+	acc := testutil.Accumulator{}
+	require.NoError(t, acc.GatherError(k.Gather))
+
+	acc.AssertContainsTaggedFields(t, "kafka_consumer_lag",
+		map[string]interface{}{"lag": int64(20), "current_offset": int64(80)},
+		map[string]string{"consumer_group": "test", "topic": "telegraf", "partition": "0"})
+	acc.AssertContainsTaggedFields(t, "kafka_consumer_lag",
+		map[string]interface{}{"lag": int64(5), "current_offset": int64(25)},
+		map[string]string{"consumer_group": "test", "topic": "events.clicks", "partition": "0"})
+	// topics which aren't subscribed are skipped
+	assert.Len(t, acc.Metrics, 2)
+}
+
+func TestTopicRegexps(t *testing.T) {
+	broker := sarama.NewMockBroker(t, 1)
+	defer broker.Close()
+
+	metadata := sarama.NewMockMetadataResponse(t).SetBroker(broker.Addr(), broker.BrokerID())
+	for _, topic := range []string{"telegraf", "events.clicks", "events.views", "audit-eu", "audit-us", "logs"} {
+		metadata.SetLeader(topic, 0, broker.BrokerID())
+	}
+	broker.SetHandlerByMap(map[string]sarama.MockResponse{"MetadataRequest": metadata})
+
+	k := &Kafka{
+		Topics:               []string{"telegraf"},
+		TopicRegexps:         []string{`^events\.`, `^audit-(eu|us)$`},
+		TopicRefreshInterval: internal.Duration{Duration: time.Minute},
+	}
+	config, err := k.newConfig()
+	require.NoError(t, err)
+	require.NoError(t, config.Validate())
+	assert.Equal(t, time.Minute, config.Metadata.RefreshFrequency)
+	require.NotNil(t, config.Group.Topics.Whitelist)
+
+	client, err := sarama.NewClient([]string{broker.Addr()}, &config.Config)
+	require.NoError(t, err)
+	defer client.Close()
+
+	topics, err := client.Topics()
+	require.NoError(t, err)
+
+	var matched []string
+	for _, topic := range topics {
+		if config.Group.Topics.Whitelist.MatchString(topic) {
+			matched = append(matched, topic)
+		}
+	}
+	sort.Strings(matched)
+	assert.Equal(t, []string{"audit-eu", "audit-us", "events.clicks", "events.views"}, matched)
+}
+
+func TestTopicRegexpsInvalid(t *testing.T) {
+	k := &Kafka{TopicRegexps: []string{`^events\.`, `(`}}
+	_, err := k.newConfig()
+	require.Error(t, err)
+}
+
 func saramaMsg(val string) *sarama.ConsumerMessage {
 	return &sarama.ConsumerMessage{
 		Key:       nil,
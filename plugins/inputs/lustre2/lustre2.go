@@ -9,6 +9,7 @@ for HPC environments. It stores statistics about its activity in
 package lustre2
 
 import (
+	"log"
 	"path/filepath"
 	"strconv"
 	"strings"
@@ -24,6 +25,11 @@ type Lustre2 struct {
 	Ost_procfiles []string
 	Mds_procfiles []string
 
+	// GatherExports enables the per-client stats of the exports of every
+	// target, read from Export_procfiles
+	GatherExports    bool
+	Export_procfiles []string
+
 	// allFields maps and OST name to the metric fields associated with that OST
 	allFields map[string]map[string]interface{}
 }
@@ -41,6 +47,14 @@ var sampleConfig = `
   #   "/proc/fs/lustre/mdt/*/md_stats",
   #   "/proc/fs/lustre/mdt/*/job_stats",
   # ]
+
+  ## Gather the stats of every client (export) of the targets, tagged with
+  ## the target and the client nid
+  # gather_exports = false
+  # export_procfiles = [
+  #   "/proc/fs/lustre/obdfilter/*/exports/*/stats",
+  #   "/proc/fs/lustre/mdt/*/exports/*/stats",
+  # ]
 `
 
 /* The wanted fields would be a []string if not for the
@@ -411,6 +425,69 @@ func (l *Lustre2) GetLustreProcStats(fileglob string, wanted_fields []*mapping,
 	return nil
 }
 
+// GetLustreExportStats reads the stats of the clients of the targets, in
+// <target>/exports/<nid>/stats. The operations are counted in the samples
+// column, the bytes are summed in the seventh column when present, older
+// releases omit the min/max/sum columns of counters without samples.
+func (l *Lustre2) GetLustreExportStats(fileglob string, acc telegraf.Accumulator) error {
+	files, err := filepath.Glob(fileglob)
+	if err != nil {
+		return err
+	}
+
+	for _, file := range files {
+		path := strings.Split(file, "/")
+		if len(path) < 4 || path[len(path)-3] != "exports" {
+			continue
+		}
+		nid := path[len(path)-2]
+		target := path[len(path)-4]
+
+		// exports come and go with the clients, a missing file isn't an error
+		lines, err := internal.ReadLines(file)
+		if err != nil {
+			log.Printf("D! [inputs.lustre2] unable to read %s: %s", file, err)
+			continue
+		}
+
+		fields := make(map[string]interface{})
+		var rpc uint64
+		for _, line := range lines {
+			parts := strings.Fields(line)
+			if len(parts) < 3 || parts[2] != "samples" {
+				continue
+			}
+			samples, err := strconv.ParseUint(parts[1], 10, 64)
+			if err != nil {
+				continue
+			}
+			rpc += samples
+
+			switch parts[0] {
+			case "read_bytes", "write_bytes":
+				op := strings.TrimSuffix(parts[0], "_bytes")
+				fields[op+"_calls"] = samples
+				bytes := uint64(0)
+				if len(parts) > 6 {
+					bytes, err = strconv.ParseUint(parts[6], 10, 64)
+					if err != nil {
+						continue
+					}
+				}
+				fields[parts[0]] = bytes
+			}
+		}
+		fields["rpc"] = rpc
+
+		tags := map[string]string{
+			"target": target,
+			"nid":    nid,
+		}
+		acc.AddFields("lustre2_export", fields, tags)
+	}
+	return nil
+}
+
 // SampleConfig returns sample configuration message
 func (l *Lustre2) SampleConfig() string {
 	return sampleConfig
@@ -483,6 +560,22 @@ func (l *Lustre2) Gather(acc telegraf.Accumulator) error {
 		}
 	}
 
+	if l.GatherExports {
+		exportfiles := l.Export_procfiles
+		if len(exportfiles) == 0 {
+			exportfiles = []string{
+				"/proc/fs/lustre/obdfilter/*/exports/*/stats",
+				"/proc/fs/lustre/mdt/*/exports/*/stats",
+			}
+		}
+		for _, procfile := range exportfiles {
+			err := l.GetLustreExportStats(procfile, acc)
+			if err != nil {
+				return err
+			}
+		}
+	}
+
 	for name, fields := range l.allFields {
 		tags := map[string]string{
 			"name": name,
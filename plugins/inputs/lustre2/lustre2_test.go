@@ -6,6 +6,7 @@ import (
 	"testing"
 
 	"github.com/influxdata/telegraf/testutil"
+	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
 
@@ -246,3 +247,95 @@ func TestLustre2GeneratesJobstatsMetrics(t *testing.T) {
 	err = os.RemoveAll(os.TempDir() + "/telegraf")
 	require.NoError(t, err)
 }
+
+// Lustre 2.5 export stats, the min/max/sum columns of the bytes counters
+const obdfilterExportContents = `snapshot_time             1438693064.430544 secs.usecs
+read_bytes                2032 samples [bytes] 4096 1048576 780261176
+write_bytes               718 samples [bytes] 1 1048576 152015008
+get_info                  11 samples [reqs]
+statfs                    35 samples [reqs]
+punch                     8 samples [reqs]
+`
+
+// Lustre 2.12 adds the sum of squares, and may omit the sums without samples
+const obdfilterExportContentsNew = `snapshot_time             1565087133.283651926 secs.nsecs
+start_time                1565086815.153285281 secs.nsecs
+elapsed_time              318.130366645 secs.nsecs
+write_bytes               4 samples [bytes]
+ping                      12 samples [reqs] 1 1 12 12
+`
+
+const mdtExportContents = `snapshot_time             1438693238.20113 secs.usecs
+open                      102 samples [reqs]
+close                     87 samples [reqs]
+getattr                   150 samples [reqs]
+`
+
+func TestLustre2GeneratesExportMetrics(t *testing.T) {
+
+	tempdir := os.TempDir() + "/telegraf/proc/fs/lustre/"
+	ost_name := "OST0001"
+	mdt_name := "MDT0000"
+
+	obddir := tempdir + "/obdfilter/" + ost_name + "/exports/"
+	mdtdir := tempdir + "/mdt/" + mdt_name + "/exports/"
+	for _, dir := range []string{obddir + "192.168.1.10@tcp", obddir + "10.0.0.2@o2ib", mdtdir + "192.168.1.10@tcp"} {
+		err := os.MkdirAll(dir, 0755)
+		require.NoError(t, err)
+	}
+
+	err := ioutil.WriteFile(obddir+"192.168.1.10@tcp/stats", []byte(obdfilterExportContents), 0644)
+	require.NoError(t, err)
+	err = ioutil.WriteFile(obddir+"10.0.0.2@o2ib/stats", []byte(obdfilterExportContentsNew), 0644)
+	require.NoError(t, err)
+	err = ioutil.WriteFile(mdtdir+"192.168.1.10@tcp/stats", []byte(mdtExportContents), 0644)
+	require.NoError(t, err)
+	// the exports directory also holds a clear file, next to the clients
+	err = ioutil.WriteFile(obddir+"clear", []byte{}, 0644)
+	require.NoError(t, err)
+
+	m := &Lustre2{
+		Ost_procfiles: []string{tempdir + "/obdfilter/*/stats"},
+		Mds_procfiles: []string{tempdir + "/mdt/*/md_stats"},
+		GatherExports: true,
+		Export_procfiles: []string{
+			tempdir + "/obdfilter/*/exports/*/stats",
+			tempdir + "/mdt/*/exports/*/stats",
+			tempdir + "/missing/*/exports/*/stats",
+		},
+	}
+
+	var acc testutil.Accumulator
+
+	err = m.Gather(&acc)
+	require.NoError(t, err)
+
+	acc.AssertContainsTaggedFields(t, "lustre2_export",
+		map[string]interface{}{
+			"read_bytes":  uint64(780261176),
+			"read_calls":  uint64(2032),
+			"write_bytes": uint64(152015008),
+			"write_calls": uint64(718),
+			"rpc":         uint64(2804),
+		},
+		map[string]string{"target": ost_name, "nid": "192.168.1.10@tcp"})
+
+	acc.AssertContainsTaggedFields(t, "lustre2_export",
+		map[string]interface{}{
+			"write_bytes": uint64(0),
+			"write_calls": uint64(4),
+			"rpc":         uint64(16),
+		},
+		map[string]string{"target": ost_name, "nid": "10.0.0.2@o2ib"})
+
+	acc.AssertContainsTaggedFields(t, "lustre2_export",
+		map[string]interface{}{
+			"rpc": uint64(339),
+		},
+		map[string]string{"target": mdt_name, "nid": "192.168.1.10@tcp"})
+
+	assert.Equal(t, 3, len(acc.Metrics))
+
+	err = os.RemoveAll(os.TempDir() + "/telegraf")
+	require.NoError(t, err)
+}
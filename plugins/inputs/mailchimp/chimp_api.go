@@ -9,13 +9,18 @@ import (
 	"net/http"
 	"net/url"
 	"regexp"
-	"sync"
+	"strconv"
 	"time"
 )
 
 const (
 	reports_endpoint          string = "/3.0/reports"
 	reports_endpoint_campaign string = "/3.0/reports/%s"
+
+	// reports_page_size is the count of reports requested per page
+	reports_page_size = 100
+	// max_retries is the number of times a rate limited request is retried
+	max_retries = 3
 )
 
 var mailchimp_datacenter = regexp.MustCompile("[a-z]+[0-9]+$")
@@ -24,9 +29,8 @@ type ChimpAPI struct {
 	Transport http.RoundTripper
 	Debug     bool
 
-	sync.Mutex
-
-	url *url.URL
+	url      *url.URL
+	pageSize int
 }
 
 type ReportsParams struct {
@@ -82,13 +86,25 @@ func chimpErrorCheck(body []byte) error {
 	return nil
 }
 
-func (a *ChimpAPI) GetReports(params ReportsParams) (ReportsResponse, error) {
-	a.Lock()
-	defer a.Unlock()
-	a.url.Path = reports_endpoint
+// endpoint returns the URL of an API path, the requests may run concurrently
+// so the base URL isn't modified
+func (a *ChimpAPI) endpoint(path string) *url.URL {
+	u := *a.url
+	u.Path = path
+	return &u
+}
 
+// PageSize returns the count of reports requested per page of GetReports
+func (a *ChimpAPI) PageSize() int {
+	if a.pageSize <= 0 {
+		return reports_page_size
+	}
+	return a.pageSize
+}
+
+func (a *ChimpAPI) GetReports(params ReportsParams) (ReportsResponse, error) {
 	var response ReportsResponse
-	rawjson, err := runChimp(a, params)
+	rawjson, err := runChimp(a, a.endpoint(reports_endpoint), params)
 	if err != nil {
 		return response, err
 	}
@@ -102,12 +118,8 @@ func (a *ChimpAPI) GetReports(params ReportsParams) (ReportsResponse, error) {
 }
 
 func (a *ChimpAPI) GetReport(campaignID string) (Report, error) {
-	a.Lock()
-	defer a.Unlock()
-	a.url.Path = fmt.Sprintf(reports_endpoint_campaign, campaignID)
-
 	var response Report
-	rawjson, err := runChimp(a, ReportsParams{})
+	rawjson, err := runChimp(a, a.endpoint(fmt.Sprintf(reports_endpoint_campaign, campaignID)), ReportsParams{})
 	if err != nil {
 		return response, err
 	}
@@ -120,41 +132,73 @@ func (a *ChimpAPI) GetReport(campaignID string) (Report, error) {
 	return response, nil
 }
 
-func runChimp(api *ChimpAPI, params ReportsParams) ([]byte, error) {
+// runChimp requests the API, the requests rate limited with a 429 status are
+// retried after the delay of the Retry-After header.
+func runChimp(api *ChimpAPI, u *url.URL, params ReportsParams) ([]byte, error) {
 	client := &http.Client{
 		Transport: api.Transport,
 		Timeout:   time.Duration(4 * time.Second),
 	}
 
-	var b bytes.Buffer
-	req, err := http.NewRequest("GET", api.url.String(), &b)
-	if err != nil {
-		return nil, err
+	var body []byte
+	for retries := 0; ; retries++ {
+		var b bytes.Buffer
+		req, err := http.NewRequest("GET", u.String(), &b)
+		if err != nil {
+			return nil, err
+		}
+		req.URL.RawQuery = params.String()
+		req.Header.Set("User-Agent", "Telegraf-MailChimp-Plugin")
+		if api.Debug {
+			log.Printf("D! Request URL: %s", req.URL.String())
+		}
+
+		resp, err := client.Do(req)
+		if err != nil {
+			return nil, err
+		}
+		body, err = ioutil.ReadAll(resp.Body)
+		resp.Body.Close()
+		if err != nil {
+			return nil, err
+		}
+
+		if resp.StatusCode != http.StatusTooManyRequests || retries >= max_retries {
+			break
+		}
+		wait := retryAfter(resp.Header.Get("Retry-After"))
+		log.Printf("D! [inputs.mailchimp] rate limited, retrying in %s", wait)
+		time.Sleep(wait)
 	}
-	req.URL.RawQuery = params.String()
-	req.Header.Set("User-Agent", "Telegraf-MailChimp-Plugin")
 	if api.Debug {
-		log.Printf("D! Request URL: %s", req.URL.String())
+		log.Printf("D! Response Body:%s", string(body))
 	}
 
-	resp, err := client.Do(req)
-	if err != nil {
+	if err := chimpErrorCheck(body); err != nil {
 		return nil, err
 	}
-	defer resp.Body.Close()
+	return body, nil
+}
 
-	body, err := ioutil.ReadAll(resp.Body)
-	if err != nil {
-		return nil, err
+// retryAfter returns the delay of a Retry-After header, either in seconds or
+// a HTTP date. A second is waited without the header.
+func retryAfter(header string) time.Duration {
+	if header == "" {
+		return time.Second
 	}
-	if api.Debug {
-		log.Printf("D! Response Body:%s", string(body))
+	if seconds, err := strconv.Atoi(header); err == nil {
+		if seconds < 0 {
+			return 0
+		}
+		return time.Duration(seconds) * time.Second
 	}
-
-	if err = chimpErrorCheck(body); err != nil {
-		return nil, err
+	if date, err := http.ParseTime(header); err == nil {
+		if wait := date.Sub(time.Now()); wait > 0 {
+			return wait
+		}
+		return 0
 	}
-	return body, nil
+	return time.Second
 }
 
 type ReportsResponse struct {
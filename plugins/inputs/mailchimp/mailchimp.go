@@ -1,7 +1,9 @@
 package mailchimp
 
 import (
-	"fmt"
+	"log"
+	"strconv"
+	"sync"
 	"time"
 
 	"github.com/influxdata/telegraf"
@@ -14,8 +16,14 @@ type MailChimp struct {
 	ApiKey     string
 	DaysOld    int
 	CampaignId string
+
+	MaxConcurrentRequests int
 }
 
+// defaultMaxConcurrentRequests is the number of pages of reports requested at
+// the same time, Mailchimp allows up to 10 simultaneous connections
+const defaultMaxConcurrentRequests = 4
+
 var sampleConfig = `
   ## MailChimp API key
   ## get from https://admin.mailchimp.com/account/api/
@@ -25,6 +33,9 @@ var sampleConfig = `
   days_old = 0
   ## Campaign ID to get, if empty gets all campaigns, this option overrides days_old
   # campaign_id = ""
+  ## Number of pages of reports requested at the same time, the requests
+  ## rate limited by MailChimp are retried after the Retry-After delay
+  # max_concurrent_requests = 4
 `
 
 func (m *MailChimp) SampleConfig() string {
@@ -43,21 +54,22 @@ func (m *MailChimp) Gather(acc telegraf.Accumulator) error {
 
 	if m.CampaignId == "" {
 		since := ""
+		var sinceTime time.Time
 		if m.DaysOld > 0 {
-			now := time.Now()
-			d, _ := time.ParseDuration(fmt.Sprintf("%dh", 24*m.DaysOld))
-			since = now.Add(-d).Format(time.RFC3339)
+			sinceTime = time.Now().UTC().AddDate(0, 0, -m.DaysOld)
+			since = sinceTime.Format(time.RFC3339)
 		}
 
-		reports, err := m.api.GetReports(ReportsParams{
-			SinceSendTime: since,
-		})
+		reports, err := m.getReports(since)
 		if err != nil {
 			return err
 		}
 		now := time.Now()
 
-		for _, report := range reports.Reports {
+		for _, report := range reports {
+			if m.DaysOld > 0 && sentBefore(report, sinceTime) {
+				continue
+			}
 			gatherReport(acc, report, now)
 		}
 	} else {
@@ -72,6 +84,79 @@ func (m *MailChimp) Gather(acc telegraf.Accumulator) error {
 	return nil
 }
 
+// getReports returns the reports of all the campaigns. The first page gives
+// the number of reports, the other pages are then requested concurrently.
+func (m *MailChimp) getReports(since string) ([]Report, error) {
+	pageSize := m.api.PageSize()
+	params := func(offset int) ReportsParams {
+		return ReportsParams{
+			Count:         strconv.Itoa(pageSize),
+			Offset:        strconv.Itoa(offset),
+			SinceSendTime: since,
+		}
+	}
+
+	first, err := m.api.GetReports(params(0))
+	if err != nil {
+		return nil, err
+	}
+	if first.TotalItems <= len(first.Reports) {
+		return first.Reports, nil
+	}
+
+	pages := (first.TotalItems + pageSize - 1) / pageSize
+	results := make([][]Report, pages)
+	results[0] = first.Reports
+	errs := make([]error, pages)
+
+	workers := m.MaxConcurrentRequests
+	if workers <= 0 {
+		workers = defaultMaxConcurrentRequests
+	}
+	offsets := make(chan int)
+	var wg sync.WaitGroup
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for page := range offsets {
+				response, err := m.api.GetReports(params(page * pageSize))
+				results[page], errs[page] = response.Reports, err
+			}
+		}()
+	}
+	for page := 1; page < pages; page++ {
+		offsets <- page
+	}
+	close(offsets)
+	wg.Wait()
+
+	var reports []Report
+	for page := range results {
+		if errs[page] != nil {
+			return nil, errs[page]
+		}
+		reports = append(reports, results[page]...)
+	}
+	return reports, nil
+}
+
+// sentBefore tells if the campaign of the report was sent before the time,
+// the API is asked for the campaigns sent since then but may return older
+// ones. The send_time is ISO 8601, reports without one are kept.
+func sentBefore(report Report, since time.Time) bool {
+	if report.SendTime == "" {
+		return false
+	}
+	sent, err := time.Parse(time.RFC3339, report.SendTime)
+	if err != nil {
+		log.Printf("D! [inputs.mailchimp] unable to parse send_time %q of campaign %s: %s",
+			report.SendTime, report.ID, err)
+		return false
+	}
+	return sent.Before(since)
+}
+
 func gatherReport(acc telegraf.Accumulator, report Report, now time.Time) {
 	tags := make(map[string]string)
 	tags["id"] = report.ID
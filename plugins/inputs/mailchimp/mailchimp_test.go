@@ -1,14 +1,19 @@
 package mailchimp
 
 import (
+	"encoding/json"
 	"fmt"
 	"net/http"
 	"net/http/httptest"
 	"net/url"
+	"strconv"
+	"sync"
 	"testing"
+	"time"
 
 	"github.com/influxdata/telegraf/testutil"
 
+	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
 
@@ -140,6 +145,151 @@ func TestMailChimpGatherReport(t *testing.T) {
 
 }
 
+func TestMailChimpGatherReportsPages(t *testing.T) {
+	var campaigns []Report
+	for i := 0; i < 7; i++ {
+		campaigns = append(campaigns, Report{
+			ID:            fmt.Sprintf("campaign%d", i),
+			CampaignTitle: fmt.Sprintf("Campaign %d", i),
+			EmailsSent:    100 + i,
+		})
+	}
+
+	var mu sync.Mutex
+	var active, maxActive, limited int
+	ts := httptest.NewServer(
+		http.HandlerFunc(
+			func(w http.ResponseWriter, r *http.Request) {
+				mu.Lock()
+				active++
+				if active > maxActive {
+					maxActive = active
+				}
+				mu.Unlock()
+				defer func() {
+					mu.Lock()
+					active--
+					mu.Unlock()
+				}()
+
+				count, _ := strconv.Atoi(r.URL.Query().Get("count"))
+				offset, _ := strconv.Atoi(r.URL.Query().Get("offset"))
+
+				// the second page is rate limited once
+				mu.Lock()
+				limit := offset == 2 && limited == 0
+				if limit {
+					limited++
+				}
+				mu.Unlock()
+				if limit {
+					w.Header().Set("Retry-After", "0")
+					w.WriteHeader(http.StatusTooManyRequests)
+					fmt.Fprint(w, sampleRateLimited)
+					return
+				}
+
+				end := offset + count
+				if end > len(campaigns) {
+					end = len(campaigns)
+				}
+				json.NewEncoder(w).Encode(ReportsResponse{
+					Reports:    campaigns[offset:end],
+					TotalItems: len(campaigns),
+				})
+			},
+		))
+	defer ts.Close()
+
+	u, err := url.ParseRequestURI(ts.URL)
+	require.NoError(t, err)
+
+	m := MailChimp{
+		api: &ChimpAPI{
+			url:      u,
+			pageSize: 2,
+		},
+		MaxConcurrentRequests: 2,
+	}
+
+	var acc testutil.Accumulator
+	err = m.Gather(&acc)
+	require.NoError(t, err)
+
+	assert.Equal(t, 1, limited)
+	assert.True(t, maxActive <= 2)
+	require.Equal(t, len(campaigns), len(acc.Metrics))
+	for _, campaign := range campaigns {
+		emails, ok := acc.Metrics[0].Fields["emails_sent"]
+		require.True(t, ok)
+		found := false
+		for _, metric := range acc.Metrics {
+			if metric.Tags["id"] == campaign.ID {
+				emails = metric.Fields["emails_sent"]
+				found = true
+			}
+		}
+		assert.True(t, found, campaign.ID)
+		assert.Equal(t, campaign.EmailsSent, emails)
+	}
+}
+
+func TestMailChimpGatherDaysOld(t *testing.T) {
+	now := time.Now().UTC()
+	reports := []Report{
+		{ID: "recent", SendTime: now.AddDate(0, 0, -2).Format(time.RFC3339)},
+		// within the window once in UTC
+		{ID: "offset", SendTime: now.AddDate(0, 0, -7).Add(time.Hour).
+			In(time.FixedZone("", -5*3600)).Format(time.RFC3339)},
+		{ID: "old", SendTime: now.AddDate(0, 0, -30).Format(time.RFC3339)},
+		{ID: "boundary", SendTime: now.AddDate(0, 0, -7).Add(-time.Hour).Format(time.RFC3339)},
+	}
+
+	var since string
+	ts := httptest.NewServer(
+		http.HandlerFunc(
+			func(w http.ResponseWriter, r *http.Request) {
+				since = r.URL.Query().Get("since_send_time")
+				// the campaigns sent before since_send_time aren't filtered
+				json.NewEncoder(w).Encode(ReportsResponse{
+					Reports:    reports,
+					TotalItems: len(reports),
+				})
+			},
+		))
+	defer ts.Close()
+
+	u, err := url.ParseRequestURI(ts.URL)
+	require.NoError(t, err)
+
+	m := MailChimp{
+		api:     &ChimpAPI{url: u},
+		DaysOld: 7,
+	}
+
+	var acc testutil.Accumulator
+	err = m.Gather(&acc)
+	require.NoError(t, err)
+
+	sinceTime, err := time.Parse(time.RFC3339, since)
+	require.NoError(t, err)
+	assert.WithinDuration(t, now.AddDate(0, 0, -7), sinceTime, time.Minute)
+
+	var gathered []string
+	for _, metric := range acc.Metrics {
+		gathered = append(gathered, metric.Tags["id"])
+	}
+	assert.Equal(t, []string{"recent", "offset"}, gathered)
+}
+
+func TestMailChimpRetryAfter(t *testing.T) {
+	assert.Equal(t, 30*time.Second, retryAfter("30"))
+	assert.Equal(t, time.Duration(0), retryAfter("-1"))
+	assert.Equal(t, time.Second, retryAfter(""))
+	assert.Equal(t, time.Second, retryAfter("soon"))
+	assert.Equal(t, time.Duration(0), retryAfter("Wed, 21 Oct 2015 07:28:00 GMT"))
+}
+
 func TestMailChimpGatherErroror(t *testing.T) {
 	ts := httptest.NewServer(
 		http.HandlerFunc(
@@ -167,6 +317,8 @@ func TestMailChimpGatherErroror(t *testing.T) {
 	require.Error(t, err)
 }
 
+var sampleRateLimited = `{"type":"http://developer.mailchimp.com/documentation/mailchimp/guides/error-glossary/","title":"Too Many Requests","status":429,"detail":"You have exceeded the limit of 10 simultaneous connections.","instance":""}`
+
 var sampleReports = `
 {
   "reports": [
@@ -6,6 +6,7 @@ import (
 	"fmt"
 	"net"
 	"strconv"
+	"strings"
 	"time"
 
 	"github.com/influxdata/telegraf"
@@ -16,13 +17,20 @@ import (
 type Memcached struct {
 	Servers     []string
 	UnixSockets []string
+	GatherSlabs bool
 }
 
 var sampleConfig = `
   ## An array of address to gather stats about. Specify an ip on hostname
-  ## with optional port. ie localhost, 10.0.0.1:11211, etc.
+  ## with optional port. ie localhost, 10.0.0.1:11211, etc. Unix socket
+  ## paths may also be given, ie /var/run/memcached.sock or
+  ## unix:///var/run/memcached.sock.
   servers = ["localhost:11211"]
   # unix_sockets = ["/var/run/memcached.sock"]
+
+  ## Gather the statistics of each slab class in the memcached_slab
+  ## measurement.
+  # gather_slabs = false
 `
 
 var defaultTimeout = 5 * time.Second
@@ -56,6 +64,19 @@ var sendMetrics = []string{
 	"conn_yields",
 }
 
+// The list of slab metrics that should be sent, from "stats slabs" and
+// "stats items"
+var sendSlabMetrics = []string{
+	"chunk_size",
+	"used_chunks",
+	"free_chunks",
+}
+
+var sendItemMetrics = []string{
+	"evicted",
+	"expired_unfetched",
+}
+
 // SampleConfig returns sample configuration message
 func (m *Memcached) SampleConfig() string {
 	return sampleConfig
@@ -73,7 +94,14 @@ func (m *Memcached) Gather(acc telegraf.Accumulator) error {
 	}
 
 	for _, serverAddress := range m.Servers {
-		acc.AddError(m.gatherServer(serverAddress, false, acc))
+		// socket paths are also accepted in servers
+		if strings.HasPrefix(serverAddress, "unix://") {
+			acc.AddError(m.gatherServer(strings.TrimPrefix(serverAddress, "unix://"), true, acc))
+		} else if strings.HasPrefix(serverAddress, "/") {
+			acc.AddError(m.gatherServer(serverAddress, true, acc))
+		} else {
+			acc.AddError(m.gatherServer(serverAddress, false, acc))
+		}
 	}
 
 	for _, unixAddress := range m.UnixSockets {
@@ -119,15 +147,7 @@ func (m *Memcached) gatherServer(
 	// Read and write buffer
 	rw := bufio.NewReadWriter(bufio.NewReader(conn), bufio.NewWriter(conn))
 
-	// Send command
-	if _, err := fmt.Fprint(rw, "stats\r\n"); err != nil {
-		return err
-	}
-	if err := rw.Flush(); err != nil {
-		return err
-	}
-
-	values, err := parseResponse(rw.Reader)
+	values, err := sendCommand(rw, "stats")
 	if err != nil {
 		return err
 	}
@@ -148,9 +168,69 @@ func (m *Memcached) gatherServer(
 		}
 	}
 	acc.AddFields("memcached", fields, tags)
+
+	if m.GatherSlabs {
+		slabs, err := sendCommand(rw, "stats slabs")
+		if err != nil {
+			return err
+		}
+		items, err := sendCommand(rw, "stats items")
+		if err != nil {
+			return err
+		}
+		for slabID, fields := range slabFields(slabs, items) {
+			acc.AddFields("memcached_slab", fields,
+				map[string]string{"server": address, "slab_id": slabID})
+		}
+	}
 	return nil
 }
 
+// sendCommand sends a stats command and parses its response.
+func sendCommand(rw *bufio.ReadWriter, command string) (map[string]string, error) {
+	if _, err := fmt.Fprint(rw, command+"\r\n"); err != nil {
+		return nil, err
+	}
+	if err := rw.Flush(); err != nil {
+		return nil, err
+	}
+	return parseResponse(rw.Reader)
+}
+
+// slabFields groups the values of the "stats slabs" and "stats items"
+// responses, named "<slab>:<metric>" and "items:<slab>:<metric>", by slab.
+func slabFields(slabs, items map[string]string) map[string]map[string]interface{} {
+	result := make(map[string]map[string]interface{})
+	add := func(slabID, key, value string, metrics []string) {
+		for _, metric := range metrics {
+			if key != metric {
+				continue
+			}
+			iValue, err := strconv.ParseInt(value, 10, 64)
+			if err != nil {
+				return
+			}
+			if result[slabID] == nil {
+				result[slabID] = make(map[string]interface{})
+			}
+			result[slabID][key] = iValue
+		}
+	}
+
+	for name, value := range slabs {
+		// skips the totals, such as active_slabs
+		if parts := strings.SplitN(name, ":", 2); len(parts) == 2 {
+			add(parts[0], parts[1], value, sendSlabMetrics)
+		}
+	}
+	for name, value := range items {
+		if parts := strings.SplitN(name, ":", 3); len(parts) == 3 && parts[0] == "items" {
+			add(parts[1], parts[2], value, sendItemMetrics)
+		}
+	}
+	return result
+}
+
 func parseResponse(r *bufio.Reader) (map[string]string, error) {
 	values := make(map[string]string)
 
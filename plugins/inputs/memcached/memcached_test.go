@@ -2,6 +2,10 @@ package memcached
 
 import (
 	"bufio"
+	"io/ioutil"
+	"net"
+	"os"
+	"path/filepath"
 	"strings"
 	"testing"
 
@@ -36,6 +40,90 @@ func TestMemcachedGeneratesMetrics(t *testing.T) {
 	}
 }
 
+// serveStats answers the stats commands on l until it is closed.
+func serveStats(l net.Listener) {
+	for {
+		conn, err := l.Accept()
+		if err != nil {
+			return
+		}
+		go func() {
+			defer conn.Close()
+			r := bufio.NewReader(conn)
+			for {
+				line, err := r.ReadString('\n')
+				if err != nil {
+					return
+				}
+				switch strings.TrimSpace(line) {
+				case "stats":
+					conn.Write([]byte(memcachedStats))
+				case "stats slabs":
+					conn.Write([]byte(memcachedStatsSlabs))
+				case "stats items":
+					conn.Write([]byte(memcachedStatsItems))
+				default:
+					conn.Write([]byte("ERROR\r\n"))
+				}
+			}
+		}()
+	}
+}
+
+func TestMemcachedUnixSocket(t *testing.T) {
+	dir, err := ioutil.TempDir("", "memcached")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+	socket := filepath.Join(dir, "memcached.sock")
+
+	l, err := net.Listen("unix", socket)
+	require.NoError(t, err)
+	defer l.Close()
+	go serveStats(l)
+
+	for _, server := range []string{socket, "unix://" + socket} {
+		m := &Memcached{
+			Servers:     []string{server},
+			GatherSlabs: true,
+		}
+
+		var acc testutil.Accumulator
+		require.NoError(t, acc.GatherError(m.Gather))
+
+		assert.True(t, acc.HasInt64Field("memcached", "uptime"))
+		assert.Equal(t, 3, len(acc.Metrics))
+		for _, metric := range acc.Metrics {
+			assert.Equal(t, socket, metric.Tags["server"])
+		}
+		assert.True(t, acc.HasTag("memcached_slab", "slab_id"))
+	}
+}
+
+func TestMemcachedSlabFields(t *testing.T) {
+	slabs, err := parseResponse(bufio.NewReader(strings.NewReader(memcachedStatsSlabs)))
+	require.NoError(t, err)
+	items, err := parseResponse(bufio.NewReader(strings.NewReader(memcachedStatsItems)))
+	require.NoError(t, err)
+
+	fields := slabFields(slabs, items)
+	assert.Equal(t, map[string]map[string]interface{}{
+		"1": {
+			"chunk_size":        int64(96),
+			"used_chunks":       int64(5),
+			"free_chunks":       int64(10917),
+			"evicted":           int64(0),
+			"expired_unfetched": int64(2),
+		},
+		"5": {
+			"chunk_size":        int64(240),
+			"used_chunks":       int64(4368),
+			"free_chunks":       int64(1),
+			"evicted":           int64(133),
+			"expired_unfetched": int64(0),
+		},
+	}, fields)
+}
+
 func TestMemcachedParseMetrics(t *testing.T) {
 	r := bufio.NewReader(strings.NewReader(memcachedStats))
 	values, err := parseResponse(r)
@@ -158,3 +246,63 @@ STAT evictions 0
 STAT reclaimed 0
 END
 `
+
+var memcachedStatsSlabs = `STAT 1:chunk_size 96
+STAT 1:chunks_per_page 10922
+STAT 1:total_pages 1
+STAT 1:total_chunks 10922
+STAT 1:used_chunks 5
+STAT 1:free_chunks 10917
+STAT 1:free_chunks_end 0
+STAT 1:mem_requested 377
+STAT 1:get_hits 4
+STAT 1:cmd_set 7
+STAT 1:delete_hits 0
+STAT 1:incr_hits 0
+STAT 1:decr_hits 0
+STAT 1:cas_hits 0
+STAT 1:cas_badval 0
+STAT 1:touch_hits 0
+STAT 5:chunk_size 240
+STAT 5:chunks_per_page 4369
+STAT 5:total_pages 1
+STAT 5:total_chunks 4369
+STAT 5:used_chunks 4368
+STAT 5:free_chunks 1
+STAT 5:free_chunks_end 0
+STAT 5:mem_requested 917280
+STAT 5:get_hits 1208
+STAT 5:cmd_set 4501
+STAT 5:delete_hits 0
+STAT 5:incr_hits 0
+STAT 5:decr_hits 0
+STAT 5:cas_hits 0
+STAT 5:cas_badval 0
+STAT 5:touch_hits 0
+STAT active_slabs 2
+STAT total_malloced 2097152
+END
+`
+
+var memcachedStatsItems = `STAT items:1:number 5
+STAT items:1:age 1127
+STAT items:1:evicted 0
+STAT items:1:evicted_nonzero 0
+STAT items:1:evicted_time 0
+STAT items:1:outofmemory 0
+STAT items:1:tailrepairs 0
+STAT items:1:reclaimed 2
+STAT items:1:expired_unfetched 2
+STAT items:1:evicted_unfetched 0
+STAT items:5:number 4368
+STAT items:5:age 412
+STAT items:5:evicted 133
+STAT items:5:evicted_nonzero 0
+STAT items:5:evicted_time 96
+STAT items:5:outofmemory 0
+STAT items:5:tailrepairs 0
+STAT items:5:reclaimed 0
+STAT items:5:expired_unfetched 0
+STAT items:5:evicted_unfetched 133
+END
+`
@@ -1,8 +1,10 @@
 package mongodb
 
 import (
+	"bytes"
 	"crypto/tls"
 	"crypto/x509"
+	"crypto/x509/pkix"
 	"fmt"
 	"log"
 	"net"
@@ -23,6 +25,15 @@ type MongoDB struct {
 	mongos           map[string]*Server
 	GatherPerdbStats bool
 
+	// Databases to collect per database stats from; all when empty
+	Databases []string
+	// Databases excluded from per database stats
+	IgnoredDatabases []string
+
+	GatherColStats bool
+	// Databases to collect collection stats from; all when empty
+	ColStatsDbs []string
+
 	// Path to CA file
 	SSLCA string `toml:"ssl_ca"`
 	// Path to host cert file
@@ -31,6 +42,9 @@ type MongoDB struct {
 	SSLKey string `toml:"ssl_key"`
 	// Use SSL but skip chain & host verification
 	InsecureSkipVerify bool
+
+	// Authentication mechanism, overrides the authMechanism URL option
+	AuthMechanism string
 }
 
 type Ssl struct {
@@ -47,12 +61,29 @@ var sampleConfig = `
   servers = ["mongodb://127.0.0.1:27017"]
   gather_perdb_stats = false
 
+  ## When gathering per database stats, only collect the listed databases;
+  ## if empty, all databases are collected.
+  # databases = []
+  ## Databases excluded from per database stats.
+  # ignored_databases = ["local", "config", "admin"]
+
+  ## When true, collect per collection stats
+  # gather_col_stats = false
+  ## List of databases where collection stats are collected;
+  ## if empty, all databases are collected.
+  # col_stats_dbs = []
+
   ## Optional SSL Config
   # ssl_ca = "/etc/telegraf/ca.pem"
   # ssl_cert = "/etc/telegraf/cert.pem"
   # ssl_key = "/etc/telegraf/key.pem"
   ## Use SSL but skip chain & host verification
   # insecure_skip_verify = false
+
+  ## Authentication mechanism, for example "MONGODB-X509" to authenticate
+  ## with the client certificate; the username defaults to the certificate
+  ## subject and the $external database is used.
+  # auth_mechanism = ""
 `
 
 func (m *MongoDB) SampleConfig() string {
@@ -107,7 +138,9 @@ func (m *MongoDB) Gather(acc telegraf.Accumulator) error {
 func (m *MongoDB) getMongoServer(url *url.URL) *Server {
 	if _, ok := m.mongos[url.Host]; !ok {
 		m.mongos[url.Host] = &Server{
-			Url: url,
+			Url:              url,
+			databases:        m.Databases,
+			ignoredDatabases: m.IgnoredDatabases,
 		}
 	}
 	return m.mongos[url.Host]
@@ -115,66 +148,147 @@ func (m *MongoDB) getMongoServer(url *url.URL) *Server {
 
 func (m *MongoDB) gatherServer(server *Server, acc telegraf.Accumulator) error {
 	if server.Session == nil {
-		var dialAddrs []string
-		if server.Url.User != nil {
-			dialAddrs = []string{server.Url.String()}
-		} else {
-			dialAddrs = []string{server.Url.Host}
+		dialInfo, err := m.getDialInfo(server.Url)
+		if err != nil {
+			return err
 		}
-		dialInfo, err := mgo.ParseURL(dialAddrs[0])
+
+		sess, err := mgo.DialWithInfo(dialInfo)
 		if err != nil {
-			return fmt.Errorf("Unable to parse URL (%s), %s\n",
-				dialAddrs[0], err.Error())
+			return fmt.Errorf("Unable to connect to MongoDB, %s\n", err.Error())
 		}
-		dialInfo.Direct = true
-		dialInfo.Timeout = 5 * time.Second
-
-		var tlsConfig *tls.Config
-
-		if m.Ssl.Enabled {
-			// Deprecated SSL config
-			tlsConfig = &tls.Config{}
-			if len(m.Ssl.CaCerts) > 0 {
-				roots := x509.NewCertPool()
-				for _, caCert := range m.Ssl.CaCerts {
-					ok := roots.AppendCertsFromPEM([]byte(caCert))
-					if !ok {
-						return fmt.Errorf("failed to parse root certificate")
-					}
+		server.Session = sess
+	}
+	return server.gatherData(acc, m.GatherPerdbStats, m.GatherColStats, m.ColStatsDbs)
+}
+
+// getDialInfo builds the mgo dial info, including TLS and authentication
+// settings, for the given server URL.
+func (m *MongoDB) getDialInfo(u *url.URL) (*mgo.DialInfo, error) {
+	var dialAddrs []string
+	if u.User != nil {
+		dialAddrs = []string{u.String()}
+	} else {
+		dialAddrs = []string{u.Host}
+	}
+	dialInfo, err := mgo.ParseURL(dialAddrs[0])
+	if err != nil {
+		return nil, fmt.Errorf("Unable to parse URL (%s), %s\n",
+			dialAddrs[0], err.Error())
+	}
+	dialInfo.Direct = true
+	dialInfo.Timeout = 5 * time.Second
+
+	var tlsConfig *tls.Config
+
+	if m.Ssl.Enabled {
+		// Deprecated SSL config
+		tlsConfig = &tls.Config{}
+		if len(m.Ssl.CaCerts) > 0 {
+			roots := x509.NewCertPool()
+			for _, caCert := range m.Ssl.CaCerts {
+				ok := roots.AppendCertsFromPEM([]byte(caCert))
+				if !ok {
+					return nil, fmt.Errorf("failed to parse root certificate")
 				}
-				tlsConfig.RootCAs = roots
-			} else {
-				tlsConfig.InsecureSkipVerify = true
 			}
+			tlsConfig.RootCAs = roots
 		} else {
-			tlsConfig, err = internal.GetTLSConfig(
-				m.SSLCert, m.SSLKey, m.SSLCA, m.InsecureSkipVerify)
+			tlsConfig.InsecureSkipVerify = true
 		}
+	} else {
+		tlsConfig, err = internal.GetTLSConfig(
+			m.SSLCert, m.SSLKey, m.SSLCA, m.InsecureSkipVerify)
+		if err != nil {
+			return nil, err
+		}
+	}
 
-		// If configured to use TLS, add a dial function
-		if tlsConfig != nil {
-			dialInfo.DialServer = func(addr *mgo.ServerAddr) (net.Conn, error) {
-				conn, err := tls.Dial("tcp", addr.String(), tlsConfig)
-				if err != nil {
-					fmt.Printf("error in Dial, %s\n", err.Error())
-				}
-				return conn, err
+	// If configured to use TLS, add a dial function
+	if tlsConfig != nil {
+		dialInfo.DialServer = func(addr *mgo.ServerAddr) (net.Conn, error) {
+			conn, err := tls.Dial("tcp", addr.String(), tlsConfig)
+			if err != nil {
+				fmt.Printf("error in Dial, %s\n", err.Error())
 			}
+			return conn, err
 		}
+	}
 
-		sess, err := mgo.DialWithInfo(dialInfo)
-		if err != nil {
-			return fmt.Errorf("Unable to connect to MongoDB, %s\n", err.Error())
+	if m.AuthMechanism != "" {
+		dialInfo.Mechanism = m.AuthMechanism
+	}
+	if dialInfo.Mechanism == "MONGODB-X509" {
+		// x509 users are defined in the $external database, with the
+		// subject of the client certificate as username.
+		dialInfo.Source = "$external"
+		if dialInfo.Username == "" {
+			if tlsConfig == nil || len(tlsConfig.Certificates) == 0 {
+				return nil, fmt.Errorf("MONGODB-X509 authentication requires ssl_cert and ssl_key")
+			}
+			cert, err := x509.ParseCertificate(tlsConfig.Certificates[0].Certificate[0])
+			if err != nil {
+				return nil, fmt.Errorf("Unable to parse client certificate, %s", err.Error())
+			}
+			dialInfo.Username = certSubject(cert.Subject)
 		}
-		server.Session = sess
 	}
-	return server.gatherData(acc, m.GatherPerdbStats)
+
+	return dialInfo, nil
+}
+
+var attributeTypeNames = map[string]string{
+	"2.5.4.3":                    "CN",
+	"2.5.4.5":                    "SERIALNUMBER",
+	"2.5.4.6":                    "C",
+	"2.5.4.7":                    "L",
+	"2.5.4.8":                    "ST",
+	"2.5.4.9":                    "STREET",
+	"2.5.4.10":                   "O",
+	"2.5.4.11":                   "OU",
+	"2.5.4.17":                   "POSTALCODE",
+	"0.9.2342.19200300.100.1.1":  "UID",
+	"0.9.2342.19200300.100.1.25": "DC",
+}
+
+// certSubject formats a certificate subject as an RFC 2253 distinguished
+// name, which is how MongoDB identifies x509 users.
+func certSubject(name pkix.Name) string {
+	rdns := name.ToRDNSequence()
+	parts := make([]string, 0, len(rdns))
+	for i := len(rdns) - 1; i >= 0; i-- {
+		attrs := make([]string, 0, len(rdns[i]))
+		for _, atv := range rdns[i] {
+			typ, ok := attributeTypeNames[atv.Type.String()]
+			if !ok {
+				typ = atv.Type.String()
+			}
+			attrs = append(attrs, typ+"="+escapeDNValue(fmt.Sprint(atv.Value)))
+		}
+		parts = append(parts, strings.Join(attrs, "+"))
+	}
+	return strings.Join(parts, ",")
+}
+
+func escapeDNValue(value string) string {
+	var buf bytes.Buffer
+	for i, r := range value {
+		switch {
+		case strings.ContainsRune(",+\"<>;\\", r),
+			i == 0 && (r == ' ' || r == '#'),
+			i == len(value)-1 && r == ' ':
+			buf.WriteRune('\\')
+		}
+		buf.WriteRune(r)
+	}
+	return buf.String()
 }
 
 func init() {
 	inputs.Add("mongodb", func() telegraf.Input {
 		return &MongoDB{
-			mongos: make(map[string]*Server),
+			mongos:           make(map[string]*Server),
+			IgnoredDatabases: []string{"local", "config", "admin"},
 		}
 	})
 }
@@ -13,6 +13,8 @@ type MongodbData struct {
 	Fields   map[string]interface{}
 	Tags     map[string]string
 	DbData   []DbData
+	ReplData []ReplData
+	ColData  []ColData
 }
 
 type DbData struct {
@@ -20,12 +22,26 @@ type DbData struct {
 	Fields map[string]interface{}
 }
 
+type ColData struct {
+	Name   string
+	DbName string
+	Fields map[string]interface{}
+}
+
+type ReplData struct {
+	Name     string
+	StateStr string
+	Fields   map[string]interface{}
+}
+
 func NewMongodbData(statLine *StatLine, tags map[string]string) *MongodbData {
 	return &MongodbData{
 		StatLine: statLine,
 		Tags:     tags,
 		Fields:   make(map[string]interface{}),
 		DbData:   []DbData{},
+		ReplData: []ReplData{},
+		ColData:  []ColData{},
 	}
 }
 
@@ -120,6 +136,49 @@ func (d *MongodbData) AddDbStats() {
 	}
 }
 
+var ColDataStats = map[string]string{
+	"count":            "Count",
+	"size":             "Size",
+	"avg_obj_size":     "AvgObjSize",
+	"storage_size":     "StorageSize",
+	"total_index_size": "TotalIndexSize",
+	"ok":               "Ok",
+}
+
+func (d *MongodbData) AddColStats() {
+	for _, colstat := range d.StatLine.ColStatsLines {
+		colStatLine := reflect.ValueOf(&colstat).Elem()
+		newColData := &ColData{
+			Name:   colstat.Name,
+			DbName: colstat.DbName,
+			Fields: make(map[string]interface{}),
+		}
+		newColData.Fields["type"] = "col_stat"
+		for key, value := range ColDataStats {
+			val := colStatLine.FieldByName(value).Interface()
+			newColData.Fields[key] = val
+		}
+		d.ColData = append(d.ColData, *newColData)
+	}
+}
+
+func (d *MongodbData) AddReplMemberStats() {
+	for _, member := range d.StatLine.ReplMemberLines {
+		fields := map[string]interface{}{
+			"health": member.Health,
+			"state":  member.State,
+		}
+		if member.OptimeLag >= 0 {
+			fields["optime_lag_seconds"] = member.OptimeLag
+		}
+		d.ReplData = append(d.ReplData, ReplData{
+			Name:     member.Name,
+			StateStr: member.StateStr,
+			Fields:   fields,
+		})
+	}
+}
+
 func (d *MongodbData) AddDefaultStats() {
 	statLine := reflect.ValueOf(d.StatLine).Elem()
 	d.addStat(statLine, DefaultStats)
@@ -160,6 +219,36 @@ func (d *MongodbData) flush(acc telegraf.Accumulator) {
 	)
 	d.Fields = make(map[string]interface{})
 
+	for _, member := range d.ReplData {
+		tags := make(map[string]string)
+		for k, v := range d.Tags {
+			tags[k] = v
+		}
+		tags["name"] = member.Name
+		tags["state_str"] = member.StateStr
+		acc.AddFields(
+			"mongodb_repl",
+			member.Fields,
+			tags,
+			d.StatLine.Time,
+		)
+	}
+
+	for _, col := range d.ColData {
+		tags := make(map[string]string)
+		for k, v := range d.Tags {
+			tags[k] = v
+		}
+		tags["db_name"] = col.DbName
+		tags["collection"] = col.Name
+		acc.AddFields(
+			"mongodb_col_stats",
+			col.Fields,
+			tags,
+			d.StatLine.Time,
+		)
+	}
+
 	for _, db := range d.DbData {
 		d.Tags["db_name"] = db.Name
 		acc.AddFields(
@@ -4,8 +4,11 @@ import (
 	"testing"
 	"time"
 
+	"github.com/influxdata/telegraf/plugins/inputs"
 	"github.com/influxdata/telegraf/testutil"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/mgo.v2/bson"
 )
 
 var tags = make(map[string]string)
@@ -150,3 +153,258 @@ func TestStateTag(t *testing.T) {
 	}
 	acc.AssertContainsTaggedFields(t, "mongodb", fields, stateTags)
 }
+
+// dbStats output recorded from a MongoDB 3.4 server
+var sampleDbStats = bson.M{
+	"db":          "telegraf",
+	"collections": 3,
+	"views":       0,
+	"objects":     1024,
+	"avgObjSize":  187.5,
+	"dataSize":    192000.0,
+	"storageSize": 98304.0,
+	"numExtents":  0,
+	"indexes":     4,
+	"indexSize":   81920.0,
+	"ok":          1.0,
+}
+
+func TestAddDbStats(t *testing.T) {
+	raw, err := bson.Marshal(sampleDbStats)
+	require.NoError(t, err)
+	dbStatsData := &DbStatsData{}
+	require.NoError(t, bson.Unmarshal(raw, dbStatsData))
+
+	status := MongoStatus{
+		ServerStatus: &ServerStatus{
+			Mem: &MemStats{Supported: false},
+		},
+		ReplSetStatus: &ReplSetStatus{},
+		ClusterStatus: &ClusterStatus{},
+		DbStats: &DbStats{
+			Dbs: []Db{{Name: "telegraf", DbStatsData: dbStatsData}},
+		},
+	}
+	d := NewMongodbData(
+		NewStatLine(status, status, "localhost", true, 1),
+		map[string]string{"hostname": "localhost"},
+	)
+
+	var acc testutil.Accumulator
+
+	d.AddDefaultStats()
+	d.AddDbStats()
+	d.flush(&acc)
+
+	fields := map[string]interface{}{
+		"type":         "db_stat",
+		"collections":  int64(3),
+		"objects":      int64(1024),
+		"avg_obj_size": float64(187.5),
+		"data_size":    int64(192000),
+		"storage_size": int64(98304),
+		"num_extents":  int64(0),
+		"indexes":      int64(4),
+		"index_size":   int64(81920),
+		"ok":           int64(1),
+	}
+	acc.AssertContainsTaggedFields(t, "mongodb_db_stats", fields,
+		map[string]string{"hostname": "localhost", "db_name": "telegraf"})
+}
+
+func TestFilterDatabases(t *testing.T) {
+	names := []string{"admin", "config", "local", "telegraf", "users"}
+
+	m := inputs.Inputs["mongodb"]().(*MongoDB)
+	assert.Equal(t, []string{"telegraf", "users"},
+		filterDatabases(names, m.Databases, m.IgnoredDatabases))
+
+	assert.Equal(t, []string{"users"},
+		filterDatabases(names, []string{"users", "missing"}, m.IgnoredDatabases))
+
+	assert.Equal(t, names, filterDatabases(names, nil, nil))
+}
+
+// replSetGetStatus output recorded from a three member replica set
+var sampleReplSetGetStatus = bson.M{
+	"set":     "rs0",
+	"myState": 1,
+	"members": []bson.M{
+		{
+			"_id":        0,
+			"name":       "mongo1:27017",
+			"health":     1.0,
+			"state":      1,
+			"stateStr":   "PRIMARY",
+			"optimeDate": time.Unix(1514764800, 0),
+			"self":       true,
+		},
+		{
+			"_id":        1,
+			"name":       "mongo2:27017",
+			"health":     1.0,
+			"state":      2,
+			"stateStr":   "SECONDARY",
+			"optimeDate": time.Unix(1514764795, 0),
+		},
+		{
+			"_id":        2,
+			"name":       "mongo3:27017",
+			"health":     0.0,
+			"state":      8,
+			"stateStr":   "(not reachable/healthy)",
+			"optimeDate": time.Unix(1514764500, 0),
+		},
+	},
+	"ok": 1.0,
+}
+
+func TestAddReplMemberStats(t *testing.T) {
+	raw, err := bson.Marshal(sampleReplSetGetStatus)
+	require.NoError(t, err)
+	replSetStatus := &ReplSetStatus{}
+	require.NoError(t, bson.Unmarshal(raw, replSetStatus))
+
+	status := MongoStatus{
+		ServerStatus: &ServerStatus{
+			Mem: &MemStats{Supported: false},
+			Repl: &ReplStatus{
+				SetName:   "rs0",
+				IsMaster:  true,
+				Secondary: false,
+				Me:        "mongo1:27017",
+			},
+		},
+		ReplSetStatus: replSetStatus,
+		ClusterStatus: &ClusterStatus{},
+		DbStats:       &DbStats{},
+	}
+	d := NewMongodbData(
+		NewStatLine(status, status, "localhost", true, 1),
+		map[string]string{"hostname": "localhost"},
+	)
+
+	var acc testutil.Accumulator
+
+	d.AddDefaultStats()
+	d.AddReplMemberStats()
+	d.flush(&acc)
+
+	acc.AssertContainsTaggedFields(t, "mongodb_repl",
+		map[string]interface{}{
+			"health":             int64(1),
+			"state":              int64(1),
+			"optime_lag_seconds": int64(0),
+		},
+		map[string]string{
+			"hostname":  "localhost",
+			"name":      "mongo1:27017",
+			"state_str": "PRIMARY",
+		})
+	acc.AssertContainsTaggedFields(t, "mongodb_repl",
+		map[string]interface{}{
+			"health":             int64(1),
+			"state":              int64(2),
+			"optime_lag_seconds": int64(5),
+		},
+		map[string]string{
+			"hostname":  "localhost",
+			"name":      "mongo2:27017",
+			"state_str": "SECONDARY",
+		})
+	acc.AssertContainsTaggedFields(t, "mongodb_repl",
+		map[string]interface{}{
+			"health":             int64(0),
+			"state":              int64(8),
+			"optime_lag_seconds": int64(300),
+		},
+		map[string]string{
+			"hostname":  "localhost",
+			"name":      "mongo3:27017",
+			"state_str": "(not reachable/healthy)",
+		})
+}
+
+func TestAddReplMemberStatsStandalone(t *testing.T) {
+	status := MongoStatus{
+		ServerStatus:  &ServerStatus{Mem: &MemStats{Supported: false}},
+		ReplSetStatus: &ReplSetStatus{},
+		ClusterStatus: &ClusterStatus{},
+		DbStats:       &DbStats{},
+	}
+	d := NewMongodbData(
+		NewStatLine(status, status, "localhost", true, 1),
+		map[string]string{"hostname": "localhost"},
+	)
+
+	var acc testutil.Accumulator
+
+	d.AddDefaultStats()
+	d.AddReplMemberStats()
+	d.flush(&acc)
+
+	assert.False(t, acc.HasMeasurement("mongodb_repl"))
+	assert.True(t, acc.HasMeasurement("mongodb"))
+}
+
+// collStats output recorded from a MongoDB 3.4 server
+var sampleCollStats = bson.M{
+	"ns":             "telegraf.metrics",
+	"size":           524288.0,
+	"count":          2048,
+	"avgObjSize":     256,
+	"storageSize":    212992.0,
+	"capped":         false,
+	"nindexes":       2,
+	"totalIndexSize": 65536.0,
+	"indexSizes": bson.M{
+		"_id_":   36864,
+		"time_1": 28672,
+	},
+	"ok": 1.0,
+}
+
+func TestAddColStats(t *testing.T) {
+	raw, err := bson.Marshal(sampleCollStats)
+	require.NoError(t, err)
+	colStatsData := &ColStatsData{}
+	require.NoError(t, bson.Unmarshal(raw, colStatsData))
+
+	status := MongoStatus{
+		ServerStatus:  &ServerStatus{Mem: &MemStats{Supported: false}},
+		ReplSetStatus: &ReplSetStatus{},
+		ClusterStatus: &ClusterStatus{},
+		DbStats:       &DbStats{},
+		ColStats: &ColStats{
+			Stats: []ColStat{
+				{Name: "metrics", DbName: "telegraf", ColStatsData: colStatsData},
+			},
+		},
+	}
+	d := NewMongodbData(
+		NewStatLine(status, status, "localhost", true, 1),
+		map[string]string{"hostname": "localhost"},
+	)
+
+	var acc testutil.Accumulator
+
+	d.AddDefaultStats()
+	d.AddColStats()
+	d.flush(&acc)
+
+	fields := map[string]interface{}{
+		"type":             "col_stat",
+		"count":            int64(2048),
+		"size":             int64(524288),
+		"avg_obj_size":     float64(256),
+		"storage_size":     int64(212992),
+		"total_index_size": int64(65536),
+		"ok":               int64(1),
+	}
+	acc.AssertContainsTaggedFields(t, "mongodb_col_stats", fields,
+		map[string]string{
+			"hostname":   "localhost",
+			"db_name":    "telegraf",
+			"collection": "metrics",
+		})
+}
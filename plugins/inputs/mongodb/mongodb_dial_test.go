@@ -0,0 +1,122 @@
+package mongodb
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"io/ioutil"
+	"math/big"
+	"net/url"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// writeClientCert writes a self signed client certificate and its key to
+// dir and returns their paths.
+func writeClientCert(t *testing.T, dir string, subject pkix.Name) (string, string) {
+	key, err := rsa.GenerateKey(rand.Reader, 1024)
+	require.NoError(t, err)
+
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      subject,
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	require.NoError(t, err)
+
+	certFile := filepath.Join(dir, "client.pem")
+	keyFile := filepath.Join(dir, "client.key")
+	require.NoError(t, ioutil.WriteFile(certFile,
+		pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), 0600))
+	require.NoError(t, ioutil.WriteFile(keyFile,
+		pem.EncodeToMemory(&pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(key)}), 0600))
+	return certFile, keyFile
+}
+
+func TestGetDialInfoX509(t *testing.T) {
+	dir, err := ioutil.TempDir("", "mongodb")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	certFile, keyFile := writeClientCert(t, dir, pkix.Name{
+		Country:            []string{"US"},
+		Organization:       []string{"influxdata"},
+		OrganizationalUnit: []string{"telegraf"},
+		CommonName:         "client",
+	})
+
+	m := &MongoDB{
+		SSLCert:       certFile,
+		SSLKey:        keyFile,
+		AuthMechanism: "MONGODB-X509",
+	}
+	u, err := url.Parse("mongodb://127.0.0.1:27017")
+	require.NoError(t, err)
+
+	dialInfo, err := m.getDialInfo(u)
+	require.NoError(t, err)
+
+	assert.Equal(t, []string{"127.0.0.1:27017"}, dialInfo.Addrs)
+	assert.Equal(t, "MONGODB-X509", dialInfo.Mechanism)
+	assert.Equal(t, "$external", dialInfo.Source)
+	assert.Equal(t, "CN=client,OU=telegraf,O=influxdata,C=US", dialInfo.Username)
+	assert.NotNil(t, dialInfo.DialServer)
+}
+
+func TestGetDialInfoX509URLUsername(t *testing.T) {
+	m := &MongoDB{
+		InsecureSkipVerify: true,
+	}
+	u, err := url.Parse("mongodb://CN=client,O=influxdata@127.0.0.1:27017/?authMechanism=MONGODB-X509")
+	require.NoError(t, err)
+
+	dialInfo, err := m.getDialInfo(u)
+	require.NoError(t, err)
+
+	assert.Equal(t, "MONGODB-X509", dialInfo.Mechanism)
+	assert.Equal(t, "$external", dialInfo.Source)
+	assert.Equal(t, "CN=client,O=influxdata", dialInfo.Username)
+	assert.NotNil(t, dialInfo.DialServer)
+}
+
+func TestGetDialInfoX509NoCert(t *testing.T) {
+	m := &MongoDB{
+		AuthMechanism: "MONGODB-X509",
+	}
+	u, err := url.Parse("mongodb://127.0.0.1:27017")
+	require.NoError(t, err)
+
+	_, err = m.getDialInfo(u)
+	assert.Error(t, err)
+}
+
+func TestGetDialInfoPlaintext(t *testing.T) {
+	m := &MongoDB{}
+	u, err := url.Parse("mongodb://127.0.0.1:27017")
+	require.NoError(t, err)
+
+	dialInfo, err := m.getDialInfo(u)
+	require.NoError(t, err)
+
+	assert.Equal(t, "", dialInfo.Mechanism)
+	assert.Nil(t, dialInfo.DialServer)
+}
+
+func TestCertSubjectEscaping(t *testing.T) {
+	name := pkix.Name{
+		Organization: []string{"Acme, Inc."},
+		CommonName:   " client",
+	}
+	assert.Equal(t, `CN=\ client,O=Acme\, Inc.`, certSubject(name))
+}
@@ -3,6 +3,7 @@ package mongodb
 import (
 	"log"
 	"net/url"
+	"strings"
 	"time"
 
 	"github.com/influxdata/telegraf"
@@ -14,6 +15,9 @@ type Server struct {
 	Url        *url.URL
 	Session    *mgo.Session
 	lastResult *MongoStatus
+
+	databases        []string
+	ignoredDatabases []string
 }
 
 func (s *Server) getDefaultTags() map[string]string {
@@ -22,7 +26,7 @@ func (s *Server) getDefaultTags() map[string]string {
 	return tags
 }
 
-func (s *Server) gatherData(acc telegraf.Accumulator, gatherDbStats bool) error {
+func (s *Server) gatherData(acc telegraf.Accumulator, gatherDbStats bool, gatherColStats bool, colStatsDbs []string) error {
 	s.Session.SetMode(mgo.Eventual, true)
 	s.Session.SetSocketTimeout(0)
 	result_server := &ServerStatus{}
@@ -40,14 +44,16 @@ func (s *Server) gatherData(acc telegraf.Accumulator, gatherDbStats bool) error
 		return err
 	}
 	result_repl := &ReplSetStatus{}
-	// ignore error because it simply indicates that the db is not a member
-	// in a replica set, which is fine.
-	_ = s.Session.DB("admin").Run(bson.D{
+	err = s.Session.DB("admin").Run(bson.D{
 		{
 			Name:  "replSetGetStatus",
 			Value: 1,
 		},
 	}, result_repl)
+	// a standalone mongod is not a member of a replica set, which is fine.
+	if err != nil && !strings.Contains(err.Error(), "not running with --replSet") {
+		log.Println("D! Error getting replica set status (" + err.Error() + ")")
+	}
 
 	jumbo_chunks, _ := s.Session.DB("config").C("chunks").Find(bson.M{"jumbo": true}).Count()
 
@@ -63,6 +69,7 @@ func (s *Server) gatherData(acc telegraf.Accumulator, gatherDbStats bool) error
 		if err != nil {
 			log.Println("E! Error getting database names (" + err.Error() + ")")
 		}
+		names = filterDatabases(names, s.databases, s.ignoredDatabases)
 		for _, db_name := range names {
 			db_stat_line := &DbStatsData{}
 			err = s.Session.DB(db_name).Run(bson.D{
@@ -83,11 +90,21 @@ func (s *Server) gatherData(acc telegraf.Accumulator, gatherDbStats bool) error
 		}
 	}
 
+	result_col_stats := &ColStats{}
+
+	if gatherColStats {
+		result_col_stats, err = s.gatherCollectionStats(colStatsDbs)
+		if err != nil {
+			log.Println("E! Error getting collection stats (" + err.Error() + ")")
+		}
+	}
+
 	result := &MongoStatus{
 		ServerStatus:  result_server,
 		ReplSetStatus: result_repl,
 		ClusterStatus: result_cluster,
 		DbStats:       result_db_stats,
+		ColStats:      result_col_stats,
 	}
 
 	defer func() {
@@ -107,7 +124,78 @@ func (s *Server) gatherData(acc telegraf.Accumulator, gatherDbStats bool) error
 		)
 		data.AddDefaultStats()
 		data.AddDbStats()
+		data.AddReplMemberStats()
+		data.AddColStats()
 		data.flush(acc)
 	}
 	return nil
 }
+
+// gatherCollectionStats runs collStats for every collection in the given
+// databases, or in all databases when none are given.
+func (s *Server) gatherCollectionStats(colStatsDbs []string) (*ColStats, error) {
+	results := &ColStats{}
+	names := colStatsDbs
+	if len(names) == 0 {
+		var err error
+		names, err = s.Session.DatabaseNames()
+		if err != nil {
+			return results, err
+		}
+	}
+
+	for _, dbName := range names {
+		colNames, err := s.Session.DB(dbName).CollectionNames()
+		if err != nil {
+			log.Println("E! Error getting collection names from " + dbName + " (" + err.Error() + ")")
+			continue
+		}
+		for _, colName := range colNames {
+			if strings.HasPrefix(colName, "system.") {
+				continue
+			}
+			colStatLine := &ColStatsData{}
+			err = s.Session.DB(dbName).Run(bson.D{
+				{
+					Name:  "collStats",
+					Value: colName,
+				},
+			}, colStatLine)
+			if err != nil {
+				log.Println("E! Error getting col stats from " + dbName + "." + colName + " (" + err.Error() + ")")
+				continue
+			}
+			results.Stats = append(results.Stats, ColStat{
+				Name:         colName,
+				DbName:       dbName,
+				ColStatsData: colStatLine,
+			})
+		}
+	}
+	return results, nil
+}
+
+// filterDatabases returns the databases from names which are in the include
+// list, if any, and not in the ignored list.
+func filterDatabases(names, include, ignored []string) []string {
+	var filtered []string
+	for _, name := range names {
+		if len(include) > 0 && !stringInSlice(name, include) {
+			continue
+		}
+		if stringInSlice(name, ignored) {
+			continue
+		}
+		filtered = append(filtered, name)
+	}
+	return filtered
+}
+
+func stringInSlice(s string, list []string) bool {
+	for _, item := range list {
+		if item == s {
+			return true
+		}
+	}
+	return false
+}
@@ -34,6 +34,7 @@ type MongoStatus struct {
 	ReplSetStatus *ReplSetStatus
 	ClusterStatus *ClusterStatus
 	DbStats       *DbStats
+	ColStats      *ColStats
 }
 
 type ServerStatus struct {
@@ -90,6 +91,28 @@ type DbStatsData struct {
 	GleStats    interface{} `bson:"gleStats"`
 }
 
+// ColStats stores stats from all collections
+type ColStats struct {
+	Stats []ColStat
+}
+
+// ColStat represent a single collection
+type ColStat struct {
+	Name         string
+	DbName       string
+	ColStatsData *ColStatsData
+}
+
+// ColStatsData stores stats from a collection
+type ColStatsData struct {
+	Count          int64   `bson:"count"`
+	Size           int64   `bson:"size"`
+	AvgObjSize     float64 `bson:"avgObjSize"`
+	StorageSize    int64   `bson:"storageSize"`
+	TotalIndexSize int64   `bson:"totalIndexSize"`
+	Ok             int64   `bson:"ok"`
+}
+
 // ClusterStatus stores information related to the whole cluster
 type ClusterStatus struct {
 	JumboChunksCount int64
@@ -106,6 +129,7 @@ type ReplSetMember struct {
 	Name       string    `bson:"name"`
 	State      int64     `bson:"state"`
 	StateStr   string    `bson:"stateStr"`
+	Health     int64     `bson:"health"`
 	OptimeDate time.Time `bson:"optimeDate"`
 }
 
@@ -450,6 +474,12 @@ type StatLine struct {
 
 	// DB stats field
 	DbStatsLines []DbStatLine
+
+	// Replica set member fields
+	ReplMemberLines []ReplMemberLine
+
+	// Collection stats field
+	ColStatsLines []ColStatLine
 }
 
 type DbStatLine struct {
@@ -465,6 +495,27 @@ type DbStatLine struct {
 	Ok          int64
 }
 
+type ColStatLine struct {
+	Name           string
+	DbName         string
+	Count          int64
+	Size           int64
+	AvgObjSize     float64
+	StorageSize    int64
+	TotalIndexSize int64
+	Ok             int64
+}
+
+// ReplMemberLine stores the status of a single replica set member.
+type ReplMemberLine struct {
+	Name     string
+	StateStr string
+	State    int64
+	Health   int64
+	// Seconds behind the primary, -1 when there is no primary
+	OptimeLag int64
+}
+
 func parseLocks(stat ServerStatus) map[string]LockUsage {
 	returnVal := map[string]LockUsage{}
 	for namespace, lockInfo := range stat.Locks {
@@ -756,6 +807,8 @@ func NewStatLine(oldMongo, newMongo MongoStatus, key string, all bool, sampleSec
 				returnVal.ReplLag = lag
 			}
 		}
+
+		returnVal.ReplMemberLines = replMemberLines(newReplStat.Members)
 	}
 
 	newClusterStat := *newMongo.ClusterStatus
@@ -783,5 +836,53 @@ func NewStatLine(oldMongo, newMongo MongoStatus, key string, all bool, sampleSec
 		returnVal.DbStatsLines = append(returnVal.DbStatsLines, *dbStatLine)
 	}
 
+	if newMongo.ColStats != nil {
+		for _, col := range newMongo.ColStats.Stats {
+			colStatsData := col.ColStatsData
+			colStatLine := &ColStatLine{
+				Name:           col.Name,
+				DbName:         col.DbName,
+				Count:          colStatsData.Count,
+				Size:           colStatsData.Size,
+				AvgObjSize:     colStatsData.AvgObjSize,
+				StorageSize:    colStatsData.StorageSize,
+				TotalIndexSize: colStatsData.TotalIndexSize,
+				Ok:             colStatsData.Ok,
+			}
+			returnVal.ColStatsLines = append(returnVal.ColStatsLines, *colStatLine)
+		}
+	}
+
 	return returnVal
 }
+
+// replMemberLines computes the lag of every replica set member relative to
+// the primary.
+func replMemberLines(members []ReplSetMember) []ReplMemberLine {
+	var primary *ReplSetMember
+	for i := range members {
+		if members[i].State == 1 {
+			primary = &members[i]
+			break
+		}
+	}
+
+	lines := make([]ReplMemberLine, 0, len(members))
+	for _, member := range members {
+		line := ReplMemberLine{
+			Name:      member.Name,
+			StateStr:  member.StateStr,
+			State:     member.State,
+			Health:    member.Health,
+			OptimeLag: -1,
+		}
+		if primary != nil {
+			line.OptimeLag = primary.OptimeDate.Unix() - member.OptimeDate.Unix()
+			if line.OptimeLag < 0 {
+				line.OptimeLag = 0
+			}
+		}
+		lines = append(lines, line)
+	}
+	return lines
+}
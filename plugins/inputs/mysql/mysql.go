@@ -23,6 +23,7 @@ type Mysql struct {
 	PerfEventsStatementsLimit           int64    `toml:"perf_events_statements_limit"`
 	PerfEventsStatementsTimeLimit       int64    `toml:"perf_events_statements_time_limit"`
 	TableSchemaDatabases                []string `toml:"table_schema_databases"`
+	IgnoredDatabases                    []string `toml:"ignored_databases"`
 	GatherProcessList                   bool     `toml:"gather_process_list"`
 	GatherUserStatistics                bool     `toml:"gather_user_statistics"`
 	GatherInfoSchemaAutoInc             bool     `toml:"gather_info_schema_auto_inc"`
@@ -40,6 +41,8 @@ type Mysql struct {
 	SSLCA                               string   `toml:"ssl_ca"`
 	SSLCert                             string   `toml:"ssl_cert"`
 	SSLKey                              string   `toml:"ssl_key"`
+	InsecureSkipVerify                  bool     `toml:"insecure_skip_verify"`
+	TLSConfigName                       string   `toml:"tls_config_name"`
 }
 
 var sampleConfig = `
@@ -63,6 +66,10 @@ var sampleConfig = `
   ## gather metrics from INFORMATION_SCHEMA.TABLES for databases provided above list
   gather_table_schema                       = false
   #
+  ## databases skipped when gathering table schema metrics of all databases,
+  ## the databases of table_schema_databases are always gathered
+  # ignored_databases = ["information_schema", "performance_schema", "mysql"]
+  #
   ## gather thread state counts from INFORMATION_SCHEMA.PROCESSLIST
   gather_process_list                       = true
   #
@@ -75,7 +82,8 @@ var sampleConfig = `
   ## gather metrics from INFORMATION_SCHEMA.INNODB_METRICS
   gather_innodb_metrics                     = true
   #
-  ## gather metrics from SHOW SLAVE STATUS command output
+  ## gather metrics from SHOW SLAVE STATUS command output, on MariaDB
+  ## SHOW ALL SLAVES STATUS is used to cover every replication connection
   gather_slave_status                       = true
   #
   ## gather metrics from SHOW BINARY LOGS command output
@@ -102,10 +110,15 @@ var sampleConfig = `
   ## Some queries we may want to run less often (such as SHOW GLOBAL VARIABLES)
   interval_slow                   = "30m"
 
-  ## Optional SSL Config (will be used if tls=custom parameter specified in server uri)
-  ssl_ca = "/etc/telegraf/ca.pem"
-  ssl_cert = "/etc/telegraf/cert.pem"
-  ssl_key = "/etc/telegraf/key.pem"
+  ## Optional SSL Config, servers without a tls parameter in their uri are
+  ## connected to with tls=<tls_config_name> ("custom" if not set)
+  # ssl_ca = "/etc/telegraf/ca.pem"
+  # ssl_cert = "/etc/telegraf/cert.pem"
+  # ssl_key = "/etc/telegraf/key.pem"
+  # tls_config_name = "custom"
+  ## Use SSL but skip chain & host verification, on its own it sets
+  ## tls=skip-verify
+  # insecure_skip_verify = false
 `
 
 var defaultTimeout = time.Second * time.Duration(5)
@@ -145,19 +158,21 @@ func (m *Mysql) Gather(acc telegraf.Accumulator) error {
 		m.InitMysql()
 	}
 
-	tlsConfig, err := internal.GetTLSConfig(m.SSLCert, m.SSLKey, m.SSLCA, false)
+	// never fall back to a plaintext connection when TLS is configured
+	tlsName, err := m.registerTLSConfig()
 	if err != nil {
-		log.Printf("E! MySQL Error registering TLS config: %s", err)
-	}
-
-	if tlsConfig != nil {
-		mysql.RegisterTLSConfig("custom", tlsConfig)
+		return fmt.Errorf("registering TLS config: %s", err)
 	}
 
 	var wg sync.WaitGroup
 
 	// Loop through each server and collect metrics
 	for _, server := range m.Servers {
+		server, err := dsnAddTLS(server, tlsName)
+		if err != nil {
+			acc.AddError(err)
+			continue
+		}
 		wg.Add(1)
 		go func(s string) {
 			defer wg.Done()
@@ -224,6 +239,41 @@ var (
 		"writing to net":            uint32(0),
 		"other":                     uint32(0),
 	}
+	// commands of the process list, all other commands are counted as other
+	generalThreadCommands = map[string]uint32{
+		"binlog dump":    uint32(0),
+		"change user":    uint32(0),
+		"close stmt":     uint32(0),
+		"connect":        uint32(0),
+		"connect out":    uint32(0),
+		"create db":      uint32(0),
+		"daemon":         uint32(0),
+		"debug":          uint32(0),
+		"delayed insert": uint32(0),
+		"drop db":        uint32(0),
+		"error":          uint32(0),
+		"execute":        uint32(0),
+		"fetch":          uint32(0),
+		"field list":     uint32(0),
+		"init db":        uint32(0),
+		"kill":           uint32(0),
+		"long data":      uint32(0),
+		"ping":           uint32(0),
+		"prepare":        uint32(0),
+		"processlist":    uint32(0),
+		"query":          uint32(0),
+		"quit":           uint32(0),
+		"refresh":        uint32(0),
+		"register slave": uint32(0),
+		"reset stmt":     uint32(0),
+		"set option":     uint32(0),
+		"shutdown":       uint32(0),
+		"sleep":          uint32(0),
+		"statistics":     uint32(0),
+		"table dump":     uint32(0),
+		"time":           uint32(0),
+		"other":          uint32(0),
+	}
 	// plaintext statuses
 	stateStatusMappings = map[string]string{
 		"user sleep":                               "idle",
@@ -258,6 +308,8 @@ const (
 	globalStatusQuery          = `SHOW GLOBAL STATUS`
 	globalVariablesQuery       = `SHOW GLOBAL VARIABLES`
 	slaveStatusQuery           = `SHOW SLAVE STATUS`
+	allSlavesStatusQuery       = `SHOW ALL SLAVES STATUS`
+	versionQuery               = `SELECT VERSION()`
 	binaryLogsQuery            = `SHOW BINARY LOGS`
 	infoSchemaProcessListQuery = `
         SELECT COALESCE(command,''),COALESCE(state,''),count(*)
@@ -371,7 +423,7 @@ const (
             ifnull(VERSION, '0') as VERSION,
             ifnull(ROW_FORMAT, 'NONE') as ROW_FORMAT,
             ifnull(TABLE_ROWS, '0') as TABLE_ROWS,
-            ifnull(DATA_LENGTH, '0') as DATA_LENGTH,
+            DATA_LENGTH,
             ifnull(INDEX_LENGTH, '0') as INDEX_LENGTH,
             ifnull(DATA_FREE, '0') as DATA_FREE,
             ifnull(CREATE_OPTIONS, 'NONE') as CREATE_OPTIONS
@@ -382,7 +434,6 @@ const (
         SELECT
             SCHEMA_NAME
             FROM information_schema.schemata
-        WHERE SCHEMA_NAME NOT IN ('mysql', 'performance_schema', 'information_schema')
     `
 	perfSchemaTablesQuery = `
 		SELECT
@@ -558,12 +609,19 @@ func (m *Mysql) gatherGlobalVariables(db *sql.DB, serv string, acc telegraf.Accu
 }
 
 // gatherSlaveStatuses can be used to get replication analytics
-// When the server is slave, then it returns only one row.
-// If the multi-source replication is set, then everything works differently
-// This code does not work with multi-source replication.
+// When the server is slave, then it returns one row per replication channel.
+// The first row is also reported on the mysql measurement with all of its
+// columns prefixed with slave_, every row is reported on the
+// mysql_slave_status measurement.
 func (m *Mysql) gatherSlaveStatuses(db *sql.DB, serv string, acc telegraf.Accumulator) error {
+	// MariaDB only reports the default connection with SHOW SLAVE STATUS
+	query := slaveStatusQuery
+	if isMariaDB(db) {
+		query = allSlavesStatusQuery
+	}
+
 	// run query
-	rows, err := db.Query(slaveStatusQuery)
+	rows, err := db.Query(query)
 	if err != nil {
 		return err
 	}
@@ -571,17 +629,16 @@ func (m *Mysql) gatherSlaveStatuses(db *sql.DB, serv string, acc telegraf.Accumu
 
 	servtag := getDSNTag(serv)
 
-	tags := map[string]string{"server": servtag}
-	fields := make(map[string]interface{})
+	// get columns names, and create an array with its length
+	cols, err := rows.Columns()
+	if err != nil {
+		return err
+	}
 
 	// to save the column names as a field key
 	// scanning keys and values separately
-	if rows.Next() {
-		// get columns names, and create an array with its length
-		cols, err := rows.Columns()
-		if err != nil {
-			return err
-		}
+	first := true
+	for rows.Next() {
 		vals := make([]interface{}, len(cols))
 		// fill the array with sql.Rawbytes
 		for i := range vals {
@@ -590,17 +647,67 @@ func (m *Mysql) gatherSlaveStatuses(db *sql.DB, serv string, acc telegraf.Accumu
 		if err = rows.Scan(vals...); err != nil {
 			return err
 		}
-		// range over columns, and try to parse values
-		for i, col := range cols {
-			col = strings.ToLower(col)
-			if value, ok := parseValue(*vals[i].(*sql.RawBytes)); ok {
-				fields["slave_"+col] = value
+		raw := make([]sql.RawBytes, len(cols))
+		for i := range vals {
+			raw[i] = *vals[i].(*sql.RawBytes)
+		}
+
+		if first {
+			fields := make(map[string]interface{})
+			// range over columns, and try to parse values
+			for i, col := range cols {
+				col = strings.ToLower(col)
+				if value, ok := parseValue(raw[i]); ok {
+					fields["slave_"+col] = value
+				}
 			}
+			acc.AddFields("mysql", fields, map[string]string{"server": servtag})
+			first = false
 		}
-		acc.AddFields("mysql", fields, tags)
+
+		fields, channel := parseSlaveStatus(cols, raw)
+		tags := map[string]string{"server": servtag}
+		if channel != "" {
+			tags["channel"] = channel
+		}
+		acc.AddFields("mysql_slave_status", fields, tags)
 	}
 
-	return nil
+	return rows.Err()
+}
+
+// parseSlaveStatus picks the replication health fields out of a single
+// SHOW SLAVE STATUS row and returns them together with the name of the
+// replication channel, if any. Seconds_Behind_Master is NULL while the
+// replication is stopped and is left out in that case.
+func parseSlaveStatus(cols []string, vals []sql.RawBytes) (map[string]interface{}, string) {
+	fields := make(map[string]interface{})
+	var channel string
+	for i, col := range cols {
+		switch col = strings.ToLower(col); col {
+		case "seconds_behind_master", "relay_log_space", "exec_master_log_pos":
+			if v, err := strconv.ParseInt(string(vals[i]), 10, 64); err == nil {
+				fields[col] = v
+			}
+		case "slave_io_running", "slave_sql_running":
+			fields[col] = int64(0)
+			if strings.EqualFold(string(vals[i]), "yes") {
+				fields[col] = int64(1)
+			}
+		case "channel_name", "connection_name":
+			channel = string(vals[i])
+		}
+	}
+	return fields, channel
+}
+
+// isMariaDB reports whether the server behind db is a MariaDB server.
+func isMariaDB(db *sql.DB) bool {
+	var version string
+	if err := db.QueryRow(versionQuery).Scan(&version); err != nil {
+		return false
+	}
+	return strings.Contains(strings.ToLower(version), "mariadb")
 }
 
 // gatherBinaryLogs can be used to collect size and count of all binary files
@@ -804,6 +911,11 @@ func (m *Mysql) GatherProcessListStatuses(db *sql.DB, serv string, acc telegraf.
 	for k, v := range generalThreadStates {
 		stateCounts[k] = v
 	}
+	// mapping of command with its counts
+	commandCounts := make(map[string]uint32, len(generalThreadCommands))
+	for k, v := range generalThreadCommands {
+		commandCounts[k] = v
+	}
 
 	for rows.Next() {
 		err = rows.Scan(&command, &state, &count)
@@ -814,12 +926,16 @@ func (m *Mysql) GatherProcessListStatuses(db *sql.DB, serv string, acc telegraf.
 		foundState := findThreadState(command, state)
 		// count each state
 		stateCounts[foundState] += count
+		commandCounts[findThreadCommand(command)] += count
 	}
 
 	tags := map[string]string{"server": servtag}
 	for s, c := range stateCounts {
 		fields[newNamespace("threads", s)] = c
 	}
+	for c, n := range commandCounts {
+		fields[newNamespace("commands", c)] = n
+	}
 	acc.AddFields("mysql_process_list", fields, tags)
 	return nil
 }
@@ -1044,6 +1160,11 @@ func (m *Mysql) gatherInnoDBMetrics(db *sql.DB, serv string, acc telegraf.Accumu
 	// run query
 	rows, err := db.Query(innoDBMetricsQuery)
 	if err != nil {
+		// INNODB_METRICS is only available since MySQL 5.6
+		if isUnknownTableError(err) {
+			log.Printf("W! MySQL skipping InnoDB metrics of %s: %s", getDSNTag(serv), err)
+			return nil
+		}
 		return err
 	}
 	defer rows.Close()
@@ -1058,7 +1179,7 @@ func (m *Mysql) gatherInnoDBMetrics(db *sql.DB, serv string, acc telegraf.Accumu
 		if err := rows.Scan(&key, &val); err != nil {
 			return err
 		}
-		key = strings.ToLower(key)
+		key = innoDBMetricKey(key)
 		if value, ok := parseValue(val); ok {
 			fields[key] = value
 		}
@@ -1386,6 +1507,8 @@ func (m *Mysql) gatherTableSchema(db *sql.DB, serv string, acc telegraf.Accumula
 
 			dbList = append(dbList, database)
 		}
+		// the databases listed explicitly are gathered even if ignored
+		dbList = filterDatabases(dbList, m.IgnoredDatabases)
 	} else {
 		dbList = m.TableSchemaDatabases
 	}
@@ -1404,7 +1527,7 @@ func (m *Mysql) gatherTableSchema(db *sql.DB, serv string, acc telegraf.Accumula
 			version       float64
 			rowFormat     string
 			tableRows     float64
-			dataLength    float64
+			dataLength    sql.NullFloat64
 			indexLength   float64
 			dataFree      float64
 			createOptions string
@@ -1426,6 +1549,10 @@ func (m *Mysql) gatherTableSchema(db *sql.DB, serv string, acc telegraf.Accumula
 			if err != nil {
 				return err
 			}
+			// views have no storage of their own and report NULL lengths
+			if !dataLength.Valid {
+				continue
+			}
 			tags := map[string]string{"server": servtag}
 			tags["schema"] = tableSchema
 			tags["table"] = tableName
@@ -1434,7 +1561,7 @@ func (m *Mysql) gatherTableSchema(db *sql.DB, serv string, acc telegraf.Accumula
 				map[string]interface{}{"rows": tableRows}, tags)
 
 			acc.AddFields("mysql_table_schema",
-				map[string]interface{}{"data_length": dataLength}, tags)
+				map[string]interface{}{"data_length": dataLength.Float64}, tags)
 
 			acc.AddFields("mysql_table_schema",
 				map[string]interface{}{"index_length": indexLength}, tags)
@@ -1455,6 +1582,42 @@ func (m *Mysql) gatherTableSchema(db *sql.DB, serv string, acc telegraf.Accumula
 	return nil
 }
 
+// filterDatabases returns the databases which are not in the ignored list
+func filterDatabases(databases, ignored []string) []string {
+	var filtered []string
+	for _, database := range databases {
+		skip := false
+		for _, i := range ignored {
+			if database == i {
+				skip = true
+				break
+			}
+		}
+		if !skip {
+			filtered = append(filtered, database)
+		}
+	}
+	return filtered
+}
+
+var innoDBMetricKeyReplacer = strings.NewReplacer(".", "_", " ", "_")
+
+// innoDBMetricKey converts the name of an INNODB_METRICS counter to a field key
+func innoDBMetricKey(name string) string {
+	return innoDBMetricKeyReplacer.Replace(strings.ToLower(strings.TrimSpace(name)))
+}
+
+// isUnknownTableError reports whether err is the server error returned when
+// querying a table which does not exist
+func isUnknownTableError(err error) bool {
+	mysqlErr, ok := err.(*mysql.MySQLError)
+	if !ok {
+		return false
+	}
+	// ER_UNKNOWN_TABLE and ER_NO_SUCH_TABLE
+	return mysqlErr.Number == 1109 || mysqlErr.Number == 1146
+}
+
 // parseValue can be used to convert values such as "ON","OFF","Yes","No" to 0,1
 func parseValue(value sql.RawBytes) (interface{}, bool) {
 	if bytes.EqualFold(value, []byte("YES")) || bytes.Compare(value, []byte("ON")) == 0 {
@@ -1514,6 +1677,16 @@ func findThreadState(rawCommand, rawState string) string {
 	return "other"
 }
 
+// findThreadCommand normalizes the command of a process list row, unknown
+// and empty commands are reported as other
+func findThreadCommand(rawCommand string) string {
+	command := strings.Replace(strings.ToLower(strings.TrimSpace(rawCommand)), "_", " ", -1)
+	if _, ok := generalThreadCommands[command]; ok {
+		return command
+	}
+	return "other"
+}
+
 // newNamespace can be used to make a namespace
 func newNamespace(words ...string) string {
 	return strings.Replace(strings.Join(words, "_"), " ", "_", -1)
@@ -1527,6 +1700,50 @@ func copyTags(in map[string]string) map[string]string {
 	return out
 }
 
+// registerTLSConfig registers the configured TLS settings with the mysql
+// driver and returns the name to be used as tls parameter of the servers,
+// it is empty when no TLS is configured.
+func (m *Mysql) registerTLSConfig() (string, error) {
+	if m.SSLCA == "" && m.SSLCert == "" && m.SSLKey == "" {
+		if m.InsecureSkipVerify {
+			return "skip-verify", nil
+		}
+		return "", nil
+	}
+
+	tlsConfig, err := internal.GetTLSConfig(m.SSLCert, m.SSLKey, m.SSLCA, m.InsecureSkipVerify)
+	if err != nil {
+		return "", err
+	}
+
+	name := m.TLSConfigName
+	if name == "" {
+		name = "custom"
+	}
+	if err := mysql.RegisterTLSConfig(name, tlsConfig); err != nil {
+		return "", err
+	}
+	return name, nil
+}
+
+// dsnAddTLS sets the tls parameter of the dsn, unless it is already set
+func dsnAddTLS(dsn, tlsName string) (string, error) {
+	if tlsName == "" {
+		return dsn, nil
+	}
+
+	conf, err := mysql.ParseDSN(dsn)
+	if err != nil {
+		return "", err
+	}
+
+	if conf.TLSConfig == "" {
+		conf.TLSConfig = tlsName
+	}
+
+	return conf.FormatDSN(), nil
+}
+
 func dsnAddTimeout(dsn string) (string, error) {
 	conf, err := mysql.ParseDSN(dsn)
 	if err != nil {
@@ -1550,6 +1767,8 @@ func getDSNTag(dsn string) string {
 
 func init() {
 	inputs.Add("mysql", func() telegraf.Input {
-		return &Mysql{}
+		return &Mysql{
+			IgnoredDatabases: []string{"information_schema", "performance_schema", "mysql"},
+		}
 	})
 }
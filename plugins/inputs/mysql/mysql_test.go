@@ -3,8 +3,12 @@ package mysql
 import (
 	"database/sql"
 	"fmt"
+	"io/ioutil"
+	"os"
 	"testing"
 
+	"github.com/go-sql-driver/mysql"
+	"github.com/influxdata/telegraf/plugins/inputs"
 	"github.com/influxdata/telegraf/testutil"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -174,3 +178,190 @@ func TestNewNamespace(t *testing.T) {
 		}
 	}
 }
+
+func TestParseSlaveStatus(t *testing.T) {
+	cols := []string{"Slave_IO_State", "Slave_IO_Running", "Slave_SQL_Running",
+		"Exec_Master_Log_Pos", "Relay_Log_Space", "Seconds_Behind_Master", "Channel_Name"}
+	testCases := []struct {
+		name    string
+		vals    []sql.RawBytes
+		fields  map[string]interface{}
+		channel string
+	}{
+		{
+			"running",
+			[]sql.RawBytes{
+				sql.RawBytes("Waiting for master to send event"), sql.RawBytes("Yes"), sql.RawBytes("Yes"),
+				sql.RawBytes("154"), sql.RawBytes("527"), sql.RawBytes("3"), sql.RawBytes(""),
+			},
+			map[string]interface{}{
+				"slave_io_running":      int64(1),
+				"slave_sql_running":     int64(1),
+				"exec_master_log_pos":   int64(154),
+				"relay_log_space":       int64(527),
+				"seconds_behind_master": int64(3),
+			},
+			"",
+		},
+		{
+			"stopped",
+			[]sql.RawBytes{
+				sql.RawBytes("Connecting to master"), sql.RawBytes("Connecting"), sql.RawBytes("No"),
+				sql.RawBytes("154"), sql.RawBytes("527"), nil, sql.RawBytes("east"),
+			},
+			map[string]interface{}{
+				"slave_io_running":    int64(0),
+				"slave_sql_running":   int64(0),
+				"exec_master_log_pos": int64(154),
+				"relay_log_space":     int64(527),
+			},
+			"east",
+		},
+	}
+	for _, tt := range testCases {
+		t.Run(tt.name, func(t *testing.T) {
+			fields, channel := parseSlaveStatus(cols, tt.vals)
+			require.Equal(t, tt.fields, fields)
+			require.Equal(t, tt.channel, channel)
+		})
+	}
+}
+
+func TestFilterDatabases(t *testing.T) {
+	m := inputs.Inputs["mysql"]().(*Mysql)
+	databases := []string{"information_schema", "app", "mysql", "performance_schema", "billing"}
+
+	require.Equal(t, []string{"app", "billing"}, filterDatabases(databases, m.IgnoredDatabases))
+	require.Equal(t, databases, filterDatabases(databases, nil))
+	require.Empty(t, filterDatabases([]string{"mysql"}, m.IgnoredDatabases))
+}
+
+func TestFindThreadCommand(t *testing.T) {
+	testCases := []struct {
+		command string
+		found   string
+	}{
+		{"Query", "query"},
+		{"Sleep", "sleep"},
+		{"Connect", "connect"},
+		{"Binlog Dump", "binlog dump"},
+		{"Binlog_Dump", "binlog dump"},
+		{"", "other"},
+		{"Something new", "other"},
+	}
+	for _, cases := range testCases {
+		if got := findThreadCommand(cases.command); got != cases.found {
+			t.Errorf("want %s, got %s", cases.found, got)
+		}
+	}
+	require.Equal(t, "commands_binlog_dump", newNamespace("commands", findThreadCommand("Binlog Dump")))
+}
+
+func TestMysqlTLSConfigError(t *testing.T) {
+	m := &Mysql{
+		Servers: []string{"root@tcp(192.0.2.1:3306)/"},
+		SSLCA:   "/nonexistent/ca.pem",
+	}
+
+	var acc testutil.Accumulator
+	err := m.Gather(&acc)
+	require.Error(t, err)
+	require.Empty(t, acc.Errors)
+}
+
+func TestMysqlDSNAddTLS(t *testing.T) {
+	tests := []struct {
+		input   string
+		tlsName string
+		output  string
+	}{
+		{
+			"root:passwd@tcp(192.168.1.1:3306)/",
+			"",
+			"root:passwd@tcp(192.168.1.1:3306)/",
+		},
+		{
+			"root:passwd@tcp(192.168.1.1:3306)/",
+			"skip-verify",
+			"root:passwd@tcp(192.168.1.1:3306)/?tls=skip-verify",
+		},
+		{
+			"root:passwd@tcp(192.168.1.1:3306)/?timeout=10s",
+			"skip-verify",
+			"root:passwd@tcp(192.168.1.1:3306)/?timeout=10s&tls=skip-verify",
+		},
+		{
+			"root:passwd@tcp(192.168.1.1:3306)/?tls=false",
+			"skip-verify",
+			"root:passwd@tcp(192.168.1.1:3306)/?tls=false",
+		},
+	}
+
+	for _, test := range tests {
+		output, err := dsnAddTLS(test.input, test.tlsName)
+		require.NoError(t, err)
+		if output != test.output {
+			t.Errorf("Expected %s, got %s\n", test.output, output)
+		}
+	}
+}
+
+func TestMysqlRegisterTLSConfig(t *testing.T) {
+	m := &Mysql{}
+	name, err := m.registerTLSConfig()
+	require.NoError(t, err)
+	require.Equal(t, "", name)
+
+	m = &Mysql{InsecureSkipVerify: true}
+	name, err = m.registerTLSConfig()
+	require.NoError(t, err)
+	require.Equal(t, "skip-verify", name)
+
+	ca, err := ioutil.TempFile("", "mysql-ca")
+	require.NoError(t, err)
+	defer os.Remove(ca.Name())
+	ca.Close()
+
+	m = &Mysql{SSLCA: ca.Name()}
+	name, err = m.registerTLSConfig()
+	require.NoError(t, err)
+	require.Equal(t, "custom", name)
+
+	m = &Mysql{SSLCA: ca.Name(), TLSConfigName: "replica"}
+	name, err = m.registerTLSConfig()
+	require.NoError(t, err)
+	require.Equal(t, "replica", name)
+
+	dsn, err := dsnAddTLS("root@tcp(192.168.1.1:3306)/", name)
+	require.NoError(t, err)
+	dsn, err = dsnAddTimeout(dsn)
+	require.NoError(t, err)
+	require.Equal(t, "root@tcp(192.168.1.1:3306)/?timeout=5s&tls=replica", dsn)
+}
+
+func TestInnoDBMetricKey(t *testing.T) {
+	testCases := []struct {
+		name string
+		key  string
+	}{
+		{"buffer_pool_reads", "buffer_pool_reads"},
+		{"Buffer_Pool_Size", "buffer_pool_size"},
+		{"adaptive_hash.searches", "adaptive_hash_searches"},
+		{"lock row lock waits", "lock_row_lock_waits"},
+	}
+	for _, cases := range testCases {
+		if got := innoDBMetricKey(cases.name); got != cases.key {
+			t.Errorf("want %s, got %s", cases.key, got)
+		}
+	}
+}
+
+func TestIsUnknownTableError(t *testing.T) {
+	require.True(t, isUnknownTableError(&mysql.MySQLError{
+		Number:  1109,
+		Message: "Unknown table 'INNODB_METRICS' in information_schema",
+	}))
+	require.True(t, isUnknownTableError(&mysql.MySQLError{Number: 1146}))
+	require.False(t, isUnknownTableError(&mysql.MySQLError{Number: 1045}))
+	require.False(t, isUnknownTableError(fmt.Errorf("connection refused")))
+}
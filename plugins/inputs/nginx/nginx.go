@@ -2,7 +2,9 @@ package nginx
 
 import (
 	"bufio"
+	"encoding/json"
 	"fmt"
+	"io"
 	"net"
 	"net/http"
 	"net/url"
@@ -14,11 +16,15 @@ import (
 	"github.com/influxdata/telegraf"
 	"github.com/influxdata/telegraf/internal"
 	"github.com/influxdata/telegraf/plugins/inputs"
+	"github.com/influxdata/telegraf/plugins/inputs/nginx_plus"
 )
 
 type Nginx struct {
 	// List of status URLs
 	Urls []string
+	// HTTP Basic Auth Credentials
+	Username string
+	Password string
 	// Path to CA file
 	SSLCA string `toml:"ssl_ca"`
 	// Path to client cert file
@@ -34,16 +40,22 @@ type Nginx struct {
 }
 
 var sampleConfig = `
-  # An array of Nginx stub_status URI to gather stats.
+  ## An array of Nginx stub_status URI to gather stats, the NGINX Plus JSON
+  ## status is detected by its content type.
   urls = ["http://localhost/server_status"]
 
-  # TLS/SSL configuration
-  ssl_ca = "/etc/telegraf/ca.pem"
-  ssl_cert = "/etc/telegraf/cert.cer"
-  ssl_key = "/etc/telegraf/key.key"
-  insecure_skip_verify = false
+  ## Optional HTTP Basic Auth Credentials
+  # username = "username"
+  # password = "pa$$word"
 
-  # HTTP response timeout (default: 5s)
+  ## Optional SSL Config
+  # ssl_ca = "/etc/telegraf/ca.pem"
+  # ssl_cert = "/etc/telegraf/cert.pem"
+  # ssl_key = "/etc/telegraf/key.pem"
+  ## Use SSL but skip chain & host verification
+  # insecure_skip_verify = false
+
+  ## HTTP response timeout (default: 5s)
   response_timeout = "5s"
 `
 
@@ -108,7 +120,15 @@ func (n *Nginx) createHttpClient() (*http.Client, error) {
 }
 
 func (n *Nginx) gatherUrl(addr *url.URL, acc telegraf.Accumulator) error {
-	resp, err := n.client.Get(addr.String())
+	req, err := http.NewRequest("GET", addr.String(), nil)
+	if err != nil {
+		return err
+	}
+	if n.Username != "" || n.Password != "" {
+		req.SetBasicAuth(n.Username, n.Password)
+	}
+
+	resp, err := n.client.Do(req)
 	if err != nil {
 		return fmt.Errorf("error making HTTP request to %s: %s", addr.String(), err)
 	}
@@ -116,6 +136,9 @@ func (n *Nginx) gatherUrl(addr *url.URL, acc telegraf.Accumulator) error {
 	if resp.StatusCode != http.StatusOK {
 		return fmt.Errorf("%s returned HTTP status %s", addr.String(), resp.Status)
 	}
+	if strings.HasPrefix(resp.Header.Get("Content-Type"), "application/json") {
+		return gatherPlusStatus(resp.Body, getTags(addr), acc)
+	}
 	r := bufio.NewReader(resp.Body)
 
 	// Active connections
@@ -190,6 +213,17 @@ func (n *Nginx) gatherUrl(addr *url.URL, acc telegraf.Accumulator) error {
 	return nil
 }
 
+// gatherPlusStatus gathers the JSON status of NGINX Plus as the nginx_plus
+// input does, so that both report the same measurements
+func gatherPlusStatus(r io.Reader, tags map[string]string, acc telegraf.Accumulator) error {
+	status := &nginx_plus.Status{}
+	if err := json.NewDecoder(r).Decode(status); err != nil {
+		return fmt.Errorf("error decoding NGINX Plus status: %s", err)
+	}
+	status.Gather(tags, acc)
+	return nil
+}
+
 // Get tag(s) for the nginx plugin
 func getTags(addr *url.URL) map[string]string {
 	h := addr.Host
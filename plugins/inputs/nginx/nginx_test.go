@@ -111,3 +111,201 @@ func TestNginxGeneratesMetrics(t *testing.T) {
 	acc_nginx.AssertContainsTaggedFields(t, "nginx", fields_nginx, tags)
 	acc_tengine.AssertContainsTaggedFields(t, "nginx", fields_tengine, tags)
 }
+
+func TestNginxPlusStatus(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprintln(w, nginxPlusSampleResponse)
+	}))
+	defer ts.Close()
+
+	n := &Nginx{
+		Urls: []string{fmt.Sprintf("%s/status", ts.URL)},
+	}
+
+	var acc testutil.Accumulator
+	require.NoError(t, acc.GatherError(n.Gather))
+	assert.False(t, acc.HasMeasurement("nginx"))
+
+	addr, err := url.Parse(ts.URL)
+	require.NoError(t, err)
+	host, port, err := net.SplitHostPort(addr.Host)
+	require.NoError(t, err)
+
+	acc.AssertContainsTaggedFields(t, "nginx_plus_connections",
+		map[string]interface{}{
+			"accepted": int64(1234567),
+			"dropped":  int64(12),
+			"active":   int64(45),
+			"idle":     int64(120),
+		},
+		map[string]string{"server": host, "port": port})
+
+	acc.AssertContainsTaggedFields(t, "nginx_plus_zone",
+		map[string]interface{}{
+			"processing":      3,
+			"requests":        int64(98765),
+			"responses_1xx":   int64(0),
+			"responses_2xx":   int64(95000),
+			"responses_3xx":   int64(2500),
+			"responses_4xx":   int64(1200),
+			"responses_5xx":   int64(62),
+			"responses_total": int64(98762),
+			"discarded":       int64(3),
+			"received":        int64(23456789),
+			"sent":            int64(987654321),
+		},
+		map[string]string{"server": host, "port": port, "zone": "api"})
+
+	acc.AssertContainsTaggedFields(t, "nginx_plus_upstream",
+		map[string]interface{}{
+			"keepalive": 0,
+			"zombies":   0,
+		},
+		map[string]string{"server": host, "port": port, "upstream": "backend"})
+
+	acc.AssertContainsTaggedFields(t, "nginx_plus_upstream_peer",
+		map[string]interface{}{
+			"backup":                   false,
+			"weight":                   1,
+			"state":                    "up",
+			"active":                   2,
+			"requests":                 int64(50000),
+			"responses_1xx":            int64(0),
+			"responses_2xx":            int64(49000),
+			"responses_3xx":            int64(0),
+			"responses_4xx":            int64(900),
+			"responses_5xx":            int64(100),
+			"responses_total":          int64(50000),
+			"sent":                     int64(12000000),
+			"received":                 int64(450000000),
+			"fails":                    int64(4),
+			"unavail":                  int64(1),
+			"healthchecks_checks":      int64(2000),
+			"healthchecks_fails":       int64(3),
+			"healthchecks_unhealthy":   int64(1),
+			"healthchecks_last_passed": true,
+			"downtime":                 int64(6500),
+			"downstart":                int64(0),
+			"selected":                 int64(1492443541000),
+			"header_time":              int64(12),
+			"response_time":            int64(15),
+		},
+		map[string]string{"server": host, "port": port, "upstream": "backend",
+			"upstream_address": "10.0.0.11:8080", "id": "0"})
+	acc.AssertContainsTaggedFields(t, "nginx_plus_upstream_peer",
+		map[string]interface{}{
+			"backup":                   true,
+			"weight":                   1,
+			"state":                    "unhealthy",
+			"active":                   0,
+			"requests":                 int64(10),
+			"responses_1xx":            int64(0),
+			"responses_2xx":            int64(8),
+			"responses_3xx":            int64(0),
+			"responses_4xx":            int64(0),
+			"responses_5xx":            int64(2),
+			"responses_total":          int64(10),
+			"sent":                     int64(2400),
+			"received":                 int64(90000),
+			"fails":                    int64(7),
+			"unavail":                  int64(3),
+			"healthchecks_checks":      int64(2000),
+			"healthchecks_fails":       int64(1500),
+			"healthchecks_unhealthy":   int64(3),
+			"healthchecks_last_passed": false,
+			"downtime":                 int64(560000),
+			"downstart":                int64(1492443000000),
+			"selected":                 int64(0),
+		},
+		map[string]string{"server": host, "port": port, "upstream": "backend",
+			"upstream_address": "10.0.0.12:8080", "id": "1"})
+}
+
+func TestNginxPlusStatusInvalid(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprintln(w, `{"connections":`)
+	}))
+	defer ts.Close()
+
+	n := &Nginx{
+		Urls: []string{fmt.Sprintf("%s/status", ts.URL)},
+	}
+
+	var acc testutil.Accumulator
+	err := acc.GatherError(n.Gather)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "NGINX Plus status")
+}
+
+// nginxPlusSampleResponse is a status of NGINX Plus R12 (version 6), the
+// caches and streams are left out
+const nginxPlusSampleResponse = `{
+  "version": 6,
+  "nginx_version": "1.11.10",
+  "address": "10.0.0.10",
+  "generation": 1,
+  "load_timestamp": 1492439162061,
+  "timestamp": 1492443541871,
+  "pid": 11462,
+  "processes": {"respawned": 0},
+  "connections": {"accepted": 1234567, "dropped": 12, "active": 45, "idle": 120},
+  "ssl": {"handshakes": 7931, "handshakes_failed": 21, "session_reuses": 2013},
+  "requests": {"total": 3345678, "current": 46},
+  "server_zones": {
+    "api": {
+      "processing": 3,
+      "requests": 98765,
+      "responses": {"1xx": 0, "2xx": 95000, "3xx": 2500, "4xx": 1200, "5xx": 62, "total": 98762},
+      "discarded": 3,
+      "received": 23456789,
+      "sent": 987654321
+    }
+  },
+  "upstreams": {
+    "backend": {
+      "peers": [
+        {
+          "id": 0,
+          "server": "10.0.0.11:8080",
+          "backup": false,
+          "weight": 1,
+          "state": "up",
+          "active": 2,
+          "requests": 50000,
+          "responses": {"1xx": 0, "2xx": 49000, "3xx": 0, "4xx": 900, "5xx": 100, "total": 50000},
+          "sent": 12000000,
+          "received": 450000000,
+          "fails": 4,
+          "unavail": 1,
+          "health_checks": {"checks": 2000, "fails": 3, "unhealthy": 1, "last_passed": true},
+          "downtime": 6500,
+          "downstart": 0,
+          "selected": 1492443541000,
+          "header_time": 12,
+          "response_time": 15
+        },
+        {
+          "id": 1,
+          "server": "10.0.0.12:8080",
+          "backup": true,
+          "weight": 1,
+          "state": "unhealthy",
+          "active": 0,
+          "requests": 10,
+          "responses": {"1xx": 0, "2xx": 8, "3xx": 0, "4xx": 0, "5xx": 2, "total": 10},
+          "sent": 2400,
+          "received": 90000,
+          "fails": 7,
+          "unavail": 3,
+          "health_checks": {"checks": 2000, "fails": 1500, "unhealthy": 3, "last_passed": false},
+          "downtime": 560000,
+          "downstart": 1492443000000
+        }
+      ],
+      "keepalive": 0,
+      "zombies": 0
+    }
+  }
+}`
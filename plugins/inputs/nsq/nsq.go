@@ -26,9 +26,11 @@ import (
 	"encoding/json"
 	"fmt"
 	"io/ioutil"
+	"net"
 	"net/http"
 	"net/url"
 	"strconv"
+	"strings"
 	"sync"
 	"time"
 
@@ -36,18 +38,26 @@ import (
 	"github.com/influxdata/telegraf/plugins/inputs"
 )
 
-// Might add Lookupd endpoints for cluster discovery
 type NSQ struct {
 	Endpoints []string
+	Lookupd   []string
+
+	// last nsqd endpoints discovered from each lookupd
+	lookupdNodes map[string][]string
 }
 
 var sampleConfig = `
   ## An array of NSQD HTTP API endpoints
   endpoints = ["http://localhost:4151"]
+
+  ## An array of NSQLookupd HTTP API endpoints. The nsqd nodes registered
+  ## with them are discovered on every gather and added to the endpoints.
+  # lookupd = ["http://localhost:4161"]
 `
 
 const (
 	requestPattern = `%s/stats?format=json`
+	nodesPattern   = `%s/nodes`
 )
 
 func init() {
@@ -66,7 +76,7 @@ func (n *NSQ) Description() string {
 
 func (n *NSQ) Gather(acc telegraf.Accumulator) error {
 	var wg sync.WaitGroup
-	for _, e := range n.Endpoints {
+	for _, e := range n.endpoints(acc) {
 		wg.Add(1)
 		go func(e string) {
 			defer wg.Done()
@@ -143,6 +153,99 @@ func (n *NSQ) gatherEndpoint(e string, acc telegraf.Accumulator) error {
 	return nil
 }
 
+// endpoints returns the configured endpoints merged with the nsqd nodes
+// registered with the lookupds. The last known nodes of a lookupd are used
+// when it can't be reached.
+func (n *NSQ) endpoints(acc telegraf.Accumulator) []string {
+	if len(n.Lookupd) == 0 {
+		return n.Endpoints
+	}
+	if n.lookupdNodes == nil {
+		n.lookupdNodes = make(map[string][]string)
+	}
+
+	seen := make(map[string]bool)
+	endpoints := make([]string, 0, len(n.Endpoints))
+	add := func(e string) {
+		e = strings.TrimRight(e, "/")
+		if !seen[e] {
+			seen[e] = true
+			endpoints = append(endpoints, e)
+		}
+	}
+
+	for _, e := range n.Endpoints {
+		add(e)
+	}
+	for _, l := range n.Lookupd {
+		nodes, err := n.lookupNodes(l)
+		if err != nil {
+			acc.AddError(err)
+			nodes = n.lookupdNodes[l]
+		} else {
+			n.lookupdNodes[l] = nodes
+		}
+		for _, e := range nodes {
+			add(e)
+		}
+	}
+	return endpoints
+}
+
+// lookupNodes queries the /nodes endpoint of a lookupd for the HTTP
+// addresses of the registered nsqd nodes.
+func (n *NSQ) lookupNodes(l string) ([]string, error) {
+	u := fmt.Sprintf(nodesPattern, strings.TrimRight(l, "/"))
+	addr, err := url.Parse(u)
+	if err != nil {
+		return nil, fmt.Errorf("Unable to parse address '%s': %s", u, err)
+	}
+	r, err := client.Get(addr.String())
+	if err != nil {
+		return nil, fmt.Errorf("Error while polling %s: %s", addr.String(), err)
+	}
+	defer r.Body.Close()
+
+	if r.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("%s returned HTTP status %s", addr.String(), r.Status)
+	}
+
+	body, err := ioutil.ReadAll(r.Body)
+	if err != nil {
+		return nil, fmt.Errorf(`Error reading body: %s`, err)
+	}
+
+	data := &LookupdNodesData{}
+	err = json.Unmarshal(body, data)
+	if err != nil {
+		return nil, fmt.Errorf(`Error parsing response: %s`, err)
+	}
+	// lookupd < 1.0 wraps the response
+	if data.Producers == nil {
+		wrapper := &LookupdNodes{}
+		err = json.Unmarshal(body, wrapper)
+		if err != nil {
+			return nil, fmt.Errorf(`Error parsing response: %s`, err)
+		}
+		data = &wrapper.Data
+	}
+
+	scheme := addr.Scheme
+	if scheme == "" {
+		scheme = "http"
+	}
+	nodes := make([]string, 0, len(data.Producers))
+	for _, p := range data.Producers {
+		host := p.BroadcastAddress
+		if host == "" {
+			host = p.Hostname
+		}
+		nodes = append(nodes, fmt.Sprintf("%s://%s", scheme,
+			net.JoinHostPort(host, strconv.Itoa(p.HTTPPort))))
+	}
+	return nodes, nil
+}
+
 func buildURL(e string) (*url.URL, error) {
 	u := fmt.Sprintf(requestPattern, e)
 	addr, err := url.Parse(u)
@@ -227,6 +330,25 @@ func clientStats(c ClientStats, acc telegraf.Accumulator, host, version, topic,
 	acc.AddFields("nsq_client", fields, tags)
 }
 
+type LookupdNodes struct {
+	Code int64            `json:"status_code"`
+	Txt  string           `json:"status_txt"`
+	Data LookupdNodesData `json:"data"`
+}
+
+type LookupdNodesData struct {
+	Producers []LookupdProducer `json:"producers"`
+}
+
+type LookupdProducer struct {
+	RemoteAddress    string `json:"remote_address"`
+	Hostname         string `json:"hostname"`
+	BroadcastAddress string `json:"broadcast_address"`
+	TCPPort          int    `json:"tcp_port"`
+	HTTPPort         int    `json:"http_port"`
+	Version          string `json:"version"`
+}
+
 type NSQStats struct {
 	Code int64        `json:"status_code"`
 	Txt  string       `json:"status_txt"`
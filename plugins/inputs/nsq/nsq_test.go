@@ -2,13 +2,16 @@ package nsq
 
 import (
 	"fmt"
+	"net"
 	"net/http"
 	"net/http/httptest"
 	"net/url"
+	"sync/atomic"
 	"testing"
 
 	"github.com/influxdata/telegraf/testutil"
 
+	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
 
@@ -528,3 +531,320 @@ var responsePreV1 = `
   }
 }
 `
+
+func TestNSQChannelStats(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+		fmt.Fprint(w, responseChannels)
+	}))
+	defer ts.Close()
+
+	n := &NSQ{
+		Endpoints: []string{ts.URL},
+	}
+
+	var acc testutil.Accumulator
+	err := acc.GatherError(n.Gather)
+	require.NoError(t, err)
+
+	u, err := url.Parse(ts.URL)
+	require.NoError(t, err)
+	host := u.Host
+
+	tests := []struct {
+		f map[string]interface{}
+		g map[string]string
+	}{
+		{
+			map[string]interface{}{
+				"depth":          int64(1200),
+				"backend_depth":  int64(200),
+				"inflight_count": int64(0),
+				"deferred_count": int64(0),
+				"message_count":  int64(5000),
+				"requeue_count":  int64(0),
+				"timeout_count":  int64(0),
+				"client_count":   int64(0),
+			},
+			map[string]string{"server_host": host, "server_version": "1.0.0-compat",
+				"topic": "orders", "channel": "archive"},
+		},
+		{
+			map[string]interface{}{
+				"depth":          int64(3),
+				"backend_depth":  int64(0),
+				"inflight_count": int64(40),
+				"deferred_count": int64(2),
+				"message_count":  int64(5000),
+				"requeue_count":  int64(7),
+				"timeout_count":  int64(1),
+				"client_count":   int64(2),
+			},
+			map[string]string{"server_host": host, "server_version": "1.0.0-compat",
+				"topic": "orders", "channel": "billing"},
+		},
+		{
+			map[string]interface{}{
+				"depth":          int64(0),
+				"backend_depth":  int64(0),
+				"inflight_count": int64(1),
+				"deferred_count": int64(0),
+				"message_count":  int64(10),
+				"requeue_count":  int64(0),
+				"timeout_count":  int64(0),
+				"client_count":   int64(1),
+			},
+			map[string]string{"server_host": host, "server_version": "1.0.0-compat",
+				"topic": "events", "channel": "billing"},
+		},
+	}
+
+	for _, test := range tests {
+		acc.AssertContainsTaggedFields(t, "nsq_channel", test.f, test.g)
+	}
+	acc.AssertContainsTaggedFields(t, "nsq_topic",
+		map[string]interface{}{
+			"depth":         int64(0),
+			"backend_depth": int64(0),
+			"message_count": int64(5000),
+			"channel_count": int64(2),
+		},
+		map[string]string{"server_host": host, "server_version": "1.0.0-compat",
+			"topic": "orders"})
+}
+
+// stats of a nsqd with a topic consumed by two channels, one of them without
+// any connected consumer
+var responseChannels = `
+{
+  "version": "1.0.0-compat",
+  "health": "OK",
+  "start_time": 1452021674,
+  "topics": [
+    {
+      "topic_name": "orders",
+      "channels": [
+        {
+          "channel_name": "archive",
+          "depth": 1200,
+          "backend_depth": 200,
+          "in_flight_count": 0,
+          "deferred_count": 0,
+          "message_count": 5000,
+          "requeue_count": 0,
+          "timeout_count": 0,
+          "clients": [],
+          "paused": false
+        },
+        {
+          "channel_name": "billing",
+          "depth": 3,
+          "backend_depth": 0,
+          "in_flight_count": 40,
+          "deferred_count": 2,
+          "message_count": 5000,
+          "requeue_count": 7,
+          "timeout_count": 1,
+          "clients": [
+            {
+              "client_id": "billing-1",
+              "hostname": "billing-1",
+              "version": "V2",
+              "remote_address": "172.17.0.11:35560",
+              "ready_count": 20,
+              "in_flight_count": 20,
+              "message_count": 2500,
+              "finish_count": 2496,
+              "requeue_count": 4,
+              "user_agent": "go-nsq/1.0.7"
+            },
+            {
+              "client_id": "billing-2",
+              "hostname": "billing-2",
+              "version": "V2",
+              "remote_address": "172.17.0.12:35561",
+              "ready_count": 20,
+              "in_flight_count": 20,
+              "message_count": 2500,
+              "finish_count": 2497,
+              "requeue_count": 3,
+              "user_agent": "go-nsq/1.0.7"
+            }
+          ],
+          "paused": false
+        }
+      ],
+      "depth": 0,
+      "backend_depth": 0,
+      "message_count": 5000,
+      "paused": false
+    },
+    {
+      "topic_name": "events",
+      "channels": [
+        {
+          "channel_name": "billing",
+          "depth": 0,
+          "backend_depth": 0,
+          "in_flight_count": 1,
+          "deferred_count": 0,
+          "message_count": 10,
+          "requeue_count": 0,
+          "timeout_count": 0,
+          "clients": [
+            {
+              "client_id": "billing-1",
+              "hostname": "billing-1",
+              "version": "V2",
+              "remote_address": "172.17.0.11:35562",
+              "ready_count": 1,
+              "in_flight_count": 1,
+              "message_count": 10,
+              "finish_count": 9,
+              "requeue_count": 0,
+              "user_agent": "go-nsq/1.0.7"
+            }
+          ],
+          "paused": false
+        }
+      ],
+      "depth": 0,
+      "backend_depth": 0,
+      "message_count": 10,
+      "paused": false
+    }
+  ]
+}
+`
+
+func TestNSQLookupd(t *testing.T) {
+	nsqd := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+		fmt.Fprint(w, responseV1)
+	}))
+	defer nsqd.Close()
+	u, err := url.Parse(nsqd.URL)
+	require.NoError(t, err)
+	host, port, err := net.SplitHostPort(u.Host)
+	require.NoError(t, err)
+
+	var down int32
+	lookupd := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.LoadInt32(&down) == 1 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		if r.URL.Path != "/nodes" {
+			http.NotFound(w, r)
+			return
+		}
+		fmt.Fprintf(w, responseNodes, host, port)
+	}))
+	defer lookupd.Close()
+
+	n := &NSQ{
+		Endpoints: []string{nsqd.URL},
+		Lookupd:   []string{lookupd.URL},
+	}
+
+	var acc testutil.Accumulator
+	assert.Equal(t, []string{nsqd.URL, "http://127.0.0.2:4151"}, n.endpoints(&acc))
+	require.Empty(t, acc.Errors)
+
+	// the nsqd registered with the lookupd and listed in the endpoints is
+	// gathered only once
+	n.Endpoints = []string{nsqd.URL + "/"}
+	acc.ClearMetrics()
+	require.NoError(t, n.Gather(&acc))
+	acc.AssertContainsTaggedFields(t, "nsq_server",
+		map[string]interface{}{
+			"server_count": int64(1),
+			"topic_count":  int64(2),
+		},
+		map[string]string{
+			"server_host":    u.Host,
+			"server_version": "1.0.0-compat",
+		})
+	assert.Equal(t, 1, countMetrics(&acc, "nsq_server", u.Host))
+
+	// the last known nodes are used while the lookupd is down
+	atomic.StoreInt32(&down, 1)
+	acc = testutil.Accumulator{}
+	assert.Equal(t, []string{nsqd.URL, "http://127.0.0.2:4151"}, n.endpoints(&acc))
+	require.Len(t, acc.Errors, 1)
+}
+
+func TestNSQLookupdPreV1(t *testing.T) {
+	lookupd := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, responseNodesPreV1)
+	}))
+	defer lookupd.Close()
+
+	n := &NSQ{
+		Lookupd: []string{lookupd.URL + "/"},
+	}
+
+	var acc testutil.Accumulator
+	assert.Equal(t, []string{"http://nsqd-1:4151"}, n.endpoints(&acc))
+	require.Empty(t, acc.Errors)
+}
+
+func countMetrics(acc *testutil.Accumulator, measurement, host string) int {
+	count := 0
+	for _, m := range acc.Metrics {
+		if m.Measurement == measurement && m.Tags["server_host"] == host {
+			count++
+		}
+	}
+	return count
+}
+
+// v1 version of the nsqlookupd /nodes response body
+var responseNodes = `
+{
+  "producers": [
+    {
+      "remote_address": "%[1]s:41876",
+      "hostname": "nsqd-1",
+      "broadcast_address": "%[1]s",
+      "tcp_port": 4150,
+      "http_port": %[2]s,
+      "version": "1.0.0-compat",
+      "tombstones": [false],
+      "topics": ["t1"]
+    },
+    {
+      "remote_address": "127.0.0.2:41877",
+      "hostname": "nsqd-2",
+      "broadcast_address": "127.0.0.2",
+      "tcp_port": 4150,
+      "http_port": 4151,
+      "version": "1.0.0-compat",
+      "tombstones": [],
+      "topics": []
+    }
+  ]
+}
+`
+
+// pre v1 version of the nsqlookupd /nodes response body
+var responseNodesPreV1 = `
+{
+  "status_code": 200,
+  "status_txt": "OK",
+  "data": {
+    "producers": [
+      {
+        "remote_address": "172.17.0.4:41876",
+        "hostname": "nsqd-1",
+        "broadcast_address": "",
+        "tcp_port": 4150,
+        "http_port": 4151,
+        "version": "0.3.8",
+        "tombstones": [],
+        "topics": []
+      }
+    ]
+  }
+}
+`
@@ -3,16 +3,21 @@ package phpfpm
 import (
 	"bufio"
 	"bytes"
+	"encoding/json"
 	"fmt"
 	"io"
+	"io/ioutil"
+	"net"
 	"net/http"
 	"net/url"
 	"os"
 	"strconv"
 	"strings"
 	"sync"
+	"time"
 
 	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/internal"
 	"github.com/influxdata/telegraf/plugins/inputs"
 )
 
@@ -35,11 +40,22 @@ type metric map[string]int64
 type poolStat map[string]metric
 
 type phpfpm struct {
-	Urls []string
+	Urls            []string
+	GatherProcesses bool
+	SlowThreshold   internal.Duration
 
 	client *http.Client
 }
 
+// Worker of the full json status page
+type process struct {
+	Pid               int64   `json:"pid"`
+	State             string  `json:"state"`
+	RequestDuration   int64   `json:"request duration"`
+	LastRequestCPU    float64 `json:"last request cpu"`
+	LastRequestMemory int64   `json:"last request memory"`
+}
+
 var sampleConfig = `
   ## An array of addresses to gather stats about. Specify an ip or hostname
   ## with optional port and path
@@ -58,9 +74,19 @@ var sampleConfig = `
   ##       "fcgi://10.0.0.12:9000/status"
   ##       "cgi://10.0.10.12:9001/status"
   ##
+  ## The status page can be requested in the json format in every mode, ie:
+  ##   "/run/php/php-fpm.sock:status?json"
+  ##   "fcgi://10.0.0.12:9000/status?json"
+  ##
   ## Example of multiple gathering from local socket and remove host
   ## urls = ["http://192.168.1.20/status", "/tmp/fpm.sock"]
   urls = ["http://localhost/status"]
+
+  ## Gather the workers of the pools from the full json status page.
+  # gather_processes = false
+  ## Only gather the workers whose last or current request is slower than
+  ## the threshold.
+  # slow_threshold = "1s"
 `
 
 func (r *phpfpm) SampleConfig() string {
@@ -116,9 +142,14 @@ func (g *phpfpm) gatherServer(addr string, acc telegraf.Accumulator) error {
 		if err != nil {
 			return fmt.Errorf("Unable parse server address '%s': %s", addr, err)
 		}
-		socketAddr := strings.Split(u.Host, ":")
-		fcgiIp := socketAddr[0]
-		fcgiPort, _ := strconv.Atoi(socketAddr[1])
+		fcgiIp, port, err := net.SplitHostPort(u.Host)
+		if err != nil {
+			return fmt.Errorf("Unable parse server address '%s': %s", addr, err)
+		}
+		fcgiPort, err := strconv.Atoi(port)
+		if err != nil {
+			return fmt.Errorf("Unable parse server address '%s': %s", addr, err)
+		}
 		fcgi, err = newFcgiClient(fcgiIp, fcgiPort)
 		if err != nil {
 			return err
@@ -128,6 +159,9 @@ func (g *phpfpm) gatherServer(addr string, acc telegraf.Accumulator) error {
 		} else {
 			statusPath = "status"
 		}
+		if len(u.RawQuery) > 0 {
+			statusPath += "?" + u.RawQuery
+		}
 	} else {
 		socketAddr := strings.Split(addr, ":")
 		if len(socketAddr) >= 2 {
@@ -153,9 +187,15 @@ func (g *phpfpm) gatherServer(addr string, acc telegraf.Accumulator) error {
 
 // Gather stat using fcgi protocol
 func (g *phpfpm) gatherFcgi(fcgi *conn, statusPath string, acc telegraf.Accumulator) error {
+	var query string
+	if i := strings.Index(statusPath, "?"); i >= 0 {
+		statusPath, query = statusPath[:i], statusPath[i+1:]
+	}
+	query = g.statusQuery(query)
 	fpmOutput, fpmErr, err := fcgi.Request(map[string]string{
 		"SCRIPT_NAME":     "/" + statusPath,
 		"SCRIPT_FILENAME": statusPath,
+		"QUERY_STRING":    query,
 		"REQUEST_METHOD":  "GET",
 		"CONTENT_LENGTH":  "0",
 		"SERVER_PROTOCOL": "HTTP/1.0",
@@ -164,8 +204,12 @@ func (g *phpfpm) gatherFcgi(fcgi *conn, statusPath string, acc telegraf.Accumula
 	}, "/"+statusPath)
 
 	if len(fpmErr) == 0 && err == nil {
-		importMetric(bytes.NewReader(fpmOutput), acc)
-		return nil
+		// Skip the CGI headers preceding the status page
+		if i := bytes.Index(fpmOutput, []byte("\r\n\r\n")); i >= 0 {
+			fpmOutput = fpmOutput[i+4:]
+		}
+		_, err = g.importMetric(bytes.NewReader(fpmOutput), acc)
+		return err
 	} else {
 		return fmt.Errorf("Unable parse phpfpm status. Error: %v %v", string(fpmErr), err)
 	}
@@ -178,8 +222,12 @@ func (g *phpfpm) gatherHttp(addr string, acc telegraf.Accumulator) error {
 		return fmt.Errorf("Unable parse server address '%s': %s", addr, err)
 	}
 
+	path := u.Path
+	if query := g.statusQuery(u.RawQuery); len(query) > 0 {
+		path += "?" + query
+	}
 	req, err := http.NewRequest("GET", fmt.Sprintf("%s://%s%s", u.Scheme,
-		u.Host, u.Path), nil)
+		u.Host, path), nil)
 	res, err := g.client.Do(req)
 	if err != nil {
 		return fmt.Errorf("Unable to connect to phpfpm status page '%s': %v",
@@ -192,16 +240,71 @@ func (g *phpfpm) gatherHttp(addr string, acc telegraf.Accumulator) error {
 			addr, err)
 	}
 
-	importMetric(res.Body, acc)
-	return nil
+	_, err = g.importMetric(res.Body, acc)
+	return err
+}
+
+// Add the json and full parameters to the status page query when the
+// workers are gathered
+func (g *phpfpm) statusQuery(query string) string {
+	if !g.GatherProcesses {
+		return query
+	}
+	for _, param := range []string{"json", "full"} {
+		if strings.Contains(query, param) {
+			continue
+		}
+		if len(query) > 0 {
+			query += "&"
+		}
+		query += param
+	}
+	return query
 }
 
 // Import stat data into Telegraf system
-func importMetric(r io.Reader, acc telegraf.Accumulator) (poolStat, error) {
+func (g *phpfpm) importMetric(r io.Reader, acc telegraf.Accumulator) (poolStat, error) {
+	data, err := ioutil.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+
+	var stats poolStat
+	if trimmed := bytes.TrimSpace(data); len(trimmed) > 0 && trimmed[0] == '{' {
+		stats, err = parseJSONStats(trimmed)
+		if err != nil {
+			return nil, err
+		}
+		if g.GatherProcesses {
+			if err := g.importProcesses(trimmed, acc); err != nil {
+				return nil, err
+			}
+		}
+	} else {
+		stats = parseStats(data)
+	}
+
+	// Finally, we push the pool metric
+	for pool := range stats {
+		tags := map[string]string{
+			"pool": pool,
+		}
+		fields := make(map[string]interface{})
+		for k, v := range stats[pool] {
+			fields[strings.Replace(k, " ", "_", -1)] = v
+		}
+		acc.AddFields("phpfpm", fields, tags)
+	}
+
+	return stats, nil
+}
+
+// Parse the plain text status page
+func parseStats(data []byte) poolStat {
 	stats := make(poolStat)
 	var currentPool string
 
-	scanner := bufio.NewScanner(r)
+	scanner := bufio.NewScanner(bytes.NewReader(data))
 	for scanner.Scan() {
 		statLine := scanner.Text()
 		keyvalue := strings.Split(statLine, ":")
@@ -218,17 +321,7 @@ func importMetric(r io.Reader, acc telegraf.Accumulator) (poolStat, error) {
 		}
 
 		// Start to parse metric for current pool
-		switch fieldName {
-		case PF_ACCEPTED_CONN,
-			PF_LISTEN_QUEUE,
-			PF_MAX_LISTEN_QUEUE,
-			PF_LISTEN_QUEUE_LEN,
-			PF_IDLE_PROCESSES,
-			PF_ACTIVE_PROCESSES,
-			PF_TOTAL_PROCESSES,
-			PF_MAX_ACTIVE_PROCESSES,
-			PF_MAX_CHILDREN_REACHED,
-			PF_SLOW_REQUESTS:
+		if isPoolMetric(fieldName) {
 			fieldValue, err := strconv.ParseInt(strings.Trim(keyvalue[1], " "), 10, 64)
 			if err == nil {
 				stats[currentPool][fieldName] = fieldValue
@@ -236,21 +329,86 @@ func importMetric(r io.Reader, acc telegraf.Accumulator) (poolStat, error) {
 		}
 	}
 
-	// Finally, we push the pool metric
-	for pool := range stats {
-		tags := map[string]string{
-			"pool": pool,
+	return stats
+}
+
+// Parse the status page requested with ?json, it has the same keys as the
+// plain text page
+func parseJSONStats(data []byte) (poolStat, error) {
+	var status map[string]interface{}
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	if err := decoder.Decode(&status); err != nil {
+		return nil, fmt.Errorf("Unable parse phpfpm json status: %s", err)
+	}
+
+	pool, _ := status[PF_POOL].(string)
+	stats := poolStat{pool: make(metric)}
+	for fieldName, value := range status {
+		if !isPoolMetric(fieldName) {
+			continue
 		}
-		fields := make(map[string]interface{})
-		for k, v := range stats[pool] {
-			fields[strings.Replace(k, " ", "_", -1)] = v
+		number, ok := value.(json.Number)
+		if !ok {
+			continue
+		}
+		if fieldValue, err := number.Int64(); err == nil {
+			stats[pool][fieldName] = fieldValue
 		}
-		acc.AddFields("phpfpm", fields, tags)
 	}
 
 	return stats, nil
 }
 
+// Import the workers of the full json status page, skipping the ones
+// faster than the slow threshold
+func (g *phpfpm) importProcesses(data []byte, acc telegraf.Accumulator) error {
+	var status struct {
+		Pool      string    `json:"pool"`
+		Processes []process `json:"processes"`
+	}
+	if err := json.Unmarshal(data, &status); err != nil {
+		return fmt.Errorf("Unable parse phpfpm json status: %s", err)
+	}
+
+	for _, p := range status.Processes {
+		// php-fpm reports the request duration in microseconds
+		duration := time.Duration(p.RequestDuration) * time.Microsecond
+		if g.SlowThreshold.Duration > 0 && duration <= g.SlowThreshold.Duration {
+			continue
+		}
+		tags := map[string]string{
+			"pool": status.Pool,
+			"pid":  strconv.FormatInt(p.Pid, 10),
+		}
+		fields := map[string]interface{}{
+			"request_duration":    p.RequestDuration,
+			"last_request_cpu":    p.LastRequestCPU,
+			"last_request_memory": p.LastRequestMemory,
+			"state":               p.State,
+		}
+		acc.AddFields("phpfpm_process", fields, tags)
+	}
+	return nil
+}
+
+func isPoolMetric(fieldName string) bool {
+	switch fieldName {
+	case PF_ACCEPTED_CONN,
+		PF_LISTEN_QUEUE,
+		PF_MAX_LISTEN_QUEUE,
+		PF_LISTEN_QUEUE_LEN,
+		PF_IDLE_PROCESSES,
+		PF_ACTIVE_PROCESSES,
+		PF_TOTAL_PROCESSES,
+		PF_MAX_ACTIVE_PROCESSES,
+		PF_MAX_CHILDREN_REACHED,
+		PF_SLOW_REQUESTS:
+		return true
+	}
+	return false
+}
+
 func init() {
 	inputs.Add("phpfpm", func() telegraf.Input {
 		return &phpfpm{}
@@ -9,7 +9,9 @@ import (
 	"net/http/fcgi"
 	"net/http/httptest"
 	"testing"
+	"time"
 
+	"github.com/influxdata/telegraf/internal"
 	"github.com/influxdata/telegraf/testutil"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -98,6 +100,148 @@ func TestPhpFpmGeneratesMetrics_From_Fcgi(t *testing.T) {
 	acc.AssertContainsTaggedFields(t, "phpfpm", fields, tags)
 }
 
+type jsonStatServer struct{}
+
+// Only answer the status page in the json format
+func (s jsonStatServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.URL.Path != "/status" || r.URL.RawQuery != "json" {
+		http.NotFound(w, r)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	fmt.Fprint(w, outputSampleJSON)
+}
+
+func TestPhpFpmGeneratesMetrics_From_Fcgi_Json(t *testing.T) {
+	tcp, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal("Cannot initialize test server")
+	}
+	defer tcp.Close()
+
+	var randomNumber int64
+	binary.Read(rand.Reader, binary.LittleEndian, &randomNumber)
+	socket, err := net.Listen("unix", fmt.Sprintf("/tmp/test-fpm%d.sock", randomNumber))
+	if err != nil {
+		t.Fatal("Cannot initialize server on socket")
+	}
+	defer socket.Close()
+
+	s := jsonStatServer{}
+	go fcgi.Serve(tcp, s)
+	go fcgi.Serve(socket, s)
+
+	r := &phpfpm{
+		Urls: []string{
+			"fcgi://" + tcp.Addr().String() + "/status?json",
+			socket.Addr().String() + ":status?json",
+		},
+	}
+
+	var acc testutil.Accumulator
+	require.NoError(t, r.Gather(&acc))
+	require.Empty(t, acc.Errors)
+
+	fields := map[string]interface{}{
+		"accepted_conn":        int64(3),
+		"listen_queue":         int64(1),
+		"max_listen_queue":     int64(0),
+		"listen_queue_len":     int64(0),
+		"idle_processes":       int64(1),
+		"active_processes":     int64(1),
+		"total_processes":      int64(2),
+		"max_active_processes": int64(1),
+		"max_children_reached": int64(2),
+		"slow_requests":        int64(1),
+	}
+
+	acc.AssertContainsTaggedFields(t, "phpfpm", fields, map[string]string{"pool": "www"})
+	assert.Len(t, acc.Metrics, 2)
+}
+
+func TestPhpFpmGeneratesMetrics_From_Fcgi_Without_Port(t *testing.T) {
+	r := &phpfpm{
+		Urls: []string{"fcgi://127.0.0.1/status"},
+	}
+
+	var acc testutil.Accumulator
+
+	err := acc.GatherError(r.Gather)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "Unable parse server address 'fcgi://127.0.0.1/status'")
+}
+
+func TestPhpFpmGeneratesMetrics_Processes(t *testing.T) {
+	var query string
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		query = r.URL.RawQuery
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprint(w, outputSampleFullJSON)
+	}))
+	defer ts.Close()
+
+	r := &phpfpm{
+		Urls:            []string{ts.URL + "/status"},
+		GatherProcesses: true,
+		SlowThreshold:   internal.Duration{Duration: time.Second},
+	}
+
+	var acc testutil.Accumulator
+	require.NoError(t, acc.GatherError(r.Gather))
+	assert.Equal(t, "json&full", query)
+
+	acc.AssertContainsTaggedFields(t, "phpfpm",
+		map[string]interface{}{
+			"accepted_conn":        int64(3),
+			"listen_queue":         int64(1),
+			"max_listen_queue":     int64(0),
+			"listen_queue_len":     int64(0),
+			"idle_processes":       int64(1),
+			"active_processes":     int64(1),
+			"total_processes":      int64(2),
+			"max_active_processes": int64(1),
+			"max_children_reached": int64(2),
+			"slow_requests":        int64(1),
+		},
+		map[string]string{"pool": "www"})
+
+	// only the worker slower than the threshold is gathered
+	acc.AssertContainsTaggedFields(t, "phpfpm_process",
+		map[string]interface{}{
+			"request_duration":    int64(2500000),
+			"last_request_cpu":    12.5,
+			"last_request_memory": int64(2097152),
+			"state":               "Running",
+		},
+		map[string]string{"pool": "www", "pid": "1234"})
+	assert.Equal(t, 2, len(acc.Metrics))
+
+	// every worker is gathered without a threshold
+	r.SlowThreshold = internal.Duration{}
+	acc.ClearMetrics()
+	require.NoError(t, acc.GatherError(r.Gather))
+	acc.AssertContainsTaggedFields(t, "phpfpm_process",
+		map[string]interface{}{
+			"request_duration":    int64(1510),
+			"last_request_cpu":    0.0,
+			"last_request_memory": int64(0),
+			"state":               "Idle",
+		},
+		map[string]string{"pool": "www", "pid": "1235"})
+	assert.Equal(t, 3, len(acc.Metrics))
+}
+
+func TestPhpFpmStatusQuery(t *testing.T) {
+	r := &phpfpm{}
+	assert.Equal(t, "", r.statusQuery(""))
+	assert.Equal(t, "json", r.statusQuery("json"))
+
+	r.GatherProcesses = true
+	assert.Equal(t, "json&full", r.statusQuery(""))
+	assert.Equal(t, "full&json", r.statusQuery("full"))
+	assert.Equal(t, "json&full", r.statusQuery("json"))
+}
+
 func TestPhpFpmGeneratesMetrics_From_Socket(t *testing.T) {
 	// Create a socket in /tmp because we always have write permission and if the
 	// removing of socket fail when system restart /tmp is clear so
@@ -239,3 +383,25 @@ max active processes: 1
 max children reached: 2
 slow requests:        1
 `
+
+const outputSampleJSON = `{"pool":"www","process manager":"dynamic",` +
+	`"start time":1444606731,"start since":1991,"accepted conn":3,` +
+	`"listen queue":1,"max listen queue":0,"listen queue len":0,` +
+	`"idle processes":1,"active processes":1,"total processes":2,` +
+	`"max active processes":1,"max children reached":2,"slow requests":1}`
+
+const outputSampleFullJSON = `{"pool":"www","process manager":"dynamic",` +
+	`"start time":1444606731,"start since":1991,"accepted conn":3,` +
+	`"listen queue":1,"max listen queue":0,"listen queue len":0,` +
+	`"idle processes":1,"active processes":1,"total processes":2,` +
+	`"max active processes":1,"max children reached":2,"slow requests":1,` +
+	`"processes":[` +
+	`{"pid":1234,"state":"Running","start time":1444606731,"start since":1991,` +
+	`"requests":2,"request duration":2500000,"request method":"GET",` +
+	`"request uri":"/report.php","content length":0,"user":"-",` +
+	`"script":"/var/www/report.php","last request cpu":12.5,` +
+	`"last request memory":2097152},` +
+	`{"pid":1235,"state":"Idle","start time":1444606731,"start since":1991,` +
+	`"requests":1,"request duration":1510,"request method":"GET",` +
+	`"request uri":"/status?json&full","content length":0,"user":"-",` +
+	`"script":"-","last request cpu":0.00,"last request memory":0}]}`
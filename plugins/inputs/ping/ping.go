@@ -5,6 +5,7 @@ package ping
 import (
 	"errors"
 	"fmt"
+	"log"
 	"net"
 	"os/exec"
 	"runtime"
@@ -37,6 +38,10 @@ type Ping struct {
 	// Interface to send ping from (ping -I <INTERFACE>)
 	Interface string
 
+	// Method used to send pings, "exec" runs the ping command and "native"
+	// sends ICMP echo requests directly
+	Method string
+
 	// URLs to ping
 	Urls []string
 
@@ -60,8 +65,12 @@ const sampleConfig = `
   # ping_interval = 1.0
   ## per-ping timeout, in s. 0 == no timeout (ping -W <TIMEOUT>)
   # timeout = 1.0
-  ## interface to send ping from (ping -I <INTERFACE>)
+  ## interface or source address to send ping from (ping -I <INTERFACE>)
   # interface = ""
+  ## method used to send pings, "exec" runs the ping command while "native"
+  ## sends ICMP echo requests itself.  When an ICMP socket cannot be opened
+  ## the ping command is used instead.
+  # method = "exec"
 `
 
 func (_ *Ping) SampleConfig() string {
@@ -69,6 +78,18 @@ func (_ *Ping) SampleConfig() string {
 }
 
 func (p *Ping) Gather(acc telegraf.Accumulator) error {
+	switch p.Method {
+	case "", methodExec, methodNative:
+	default:
+		return fmt.Errorf("invalid method %q, must be %q or %q",
+			p.Method, methodExec, methodNative)
+	}
+	// the interface to send pings from must exist
+	if p.Interface != "" {
+		if _, err := interfaceAddrs(p.Interface); err != nil {
+			return err
+		}
+	}
 
 	var wg sync.WaitGroup
 
@@ -88,6 +109,22 @@ func (p *Ping) Gather(acc telegraf.Accumulator) error {
 				return
 			}
 
+			if p.Method == methodNative {
+				stats, err := p.nativePing(u)
+				if err == nil {
+					addStatsFields(fields, stats.transmitted, stats.received,
+						stats.ttl, stats.min, stats.avg, stats.max, stats.stddev)
+					acc.AddFields("ping", fields, tags)
+					return
+				}
+				if _, ok := err.(listenError); !ok {
+					acc.AddError(fmt.Errorf("%s: %s", err, u))
+					acc.AddFields("ping", fields, tags)
+					return
+				}
+				log.Printf("W! [inputs.ping] %s, falling back to the ping command", err)
+			}
+
 			args := p.args(u)
 			totalTimeout := float64(p.Count)*p.Timeout + float64(p.Count-1)*p.PingInterval
 
@@ -116,30 +153,14 @@ func (p *Ping) Gather(acc telegraf.Accumulator) error {
 				}
 			}
 
-			trans, rec, min, avg, max, stddev, err := processPingOutput(out)
+			trans, rec, ttl, min, avg, max, stddev, err := processPingOutput(out)
 			if err != nil {
 				// fatal error
 				acc.AddError(fmt.Errorf("%s: %s", err, u))
 				acc.AddFields("ping", fields, tags)
 				return
 			}
-			// Calculate packet loss percentage
-			loss := float64(trans-rec) / float64(trans) * 100.0
-			fields["packets_transmitted"] = trans
-			fields["packets_received"] = rec
-			fields["percent_packet_loss"] = loss
-			if min > 0 {
-				fields["minimum_response_ms"] = min
-			}
-			if avg > 0 {
-				fields["average_response_ms"] = avg
-			}
-			if max > 0 {
-				fields["maximum_response_ms"] = max
-			}
-			if stddev > 0 {
-				fields["standard_deviation_ms"] = stddev
-			}
+			addStatsFields(fields, trans, rec, ttl, min, avg, max, stddev)
 			acc.AddFields("ping", fields, tags)
 		}(url)
 	}
@@ -149,6 +170,31 @@ func (p *Ping) Gather(acc telegraf.Accumulator) error {
 	return nil
 }
 
+// addStatsFields adds the packet counts, reply ttl and response times to
+// fields, leaving out values which are not known.
+func addStatsFields(fields map[string]interface{}, trans, rec, ttl int, min, avg, max, stddev float64) {
+	// Calculate packet loss percentage
+	loss := float64(trans-rec) / float64(trans) * 100.0
+	fields["packets_transmitted"] = trans
+	fields["packets_received"] = rec
+	fields["percent_packet_loss"] = loss
+	if ttl >= 0 {
+		fields["ttl"] = ttl
+	}
+	if min > 0 {
+		fields["minimum_response_ms"] = min
+	}
+	if avg > 0 {
+		fields["average_response_ms"] = avg
+	}
+	if max > 0 {
+		fields["maximum_response_ms"] = max
+	}
+	if stddev > 0 {
+		fields["standard_deviation_ms"] = stddev
+	}
+}
+
 func hostPinger(timeout float64, args ...string) (string, error) {
 	bin, err := exec.LookPath("ping")
 	if err != nil {
@@ -195,48 +241,77 @@ func (p *Ping) args(url string) []string {
 //     2 packets transmitted, 2 packets received, 0.0% packet loss
 //     round-trip min/avg/max/stddev = 34.843/43.508/52.172/8.664 ms
 //
-// It returns (<transmitted packets>, <received packets>, <average response>)
-func processPingOutput(out string) (int, int, float64, float64, float64, float64, error) {
+// It returns (<transmitted packets>, <received packets>, <ttl of the first
+// reply>, <min>, <average>, <max> and <stddev response>). The ttl is -1 and
+// stddev is 0 when they are missing from the output.
+func processPingOutput(out string) (int, int, int, float64, float64, float64, float64, error) {
 	var trans, recv int
+	ttl := -1
 	var min, avg, max, stddev float64
 	// Set this error to nil if we find a 'transmitted' line
 	err := errors.New("Fatal error processing ping output")
 	lines := strings.Split(out, "\n")
 	for _, line := range lines {
-		if strings.Contains(line, "transmitted") &&
+		if ttl == -1 && strings.Contains(line, "ttl=") {
+			ttl = parseTTL(line)
+		} else if strings.Contains(line, "transmitted") &&
 			strings.Contains(line, "received") {
 			stats := strings.Split(line, ", ")
 			// Transmitted packets
 			trans, err = strconv.Atoi(strings.Split(stats[0], " ")[0])
 			if err != nil {
-				return trans, recv, min, avg, max, stddev, err
+				return trans, recv, ttl, min, avg, max, stddev, err
 			}
 			// Received packets
 			recv, err = strconv.Atoi(strings.Split(stats[1], " ")[0])
 			if err != nil {
-				return trans, recv, min, avg, max, stddev, err
+				return trans, recv, ttl, min, avg, max, stddev, err
 			}
 		} else if strings.Contains(line, "min/avg/max") {
-			stats := strings.Split(line, " ")[3]
-			min, err = strconv.ParseFloat(strings.Split(stats, "/")[0], 64)
+			fields := strings.Fields(line)
+			if len(fields) < 4 {
+				continue
+			}
+			// some implementations, like busybox, leave out the stddev
+			stats := strings.Split(fields[3], "/")
+			if len(stats) < 3 {
+				continue
+			}
+			min, err = strconv.ParseFloat(stats[0], 64)
 			if err != nil {
-				return trans, recv, min, avg, max, stddev, err
+				return trans, recv, ttl, min, avg, max, stddev, err
 			}
-			avg, err = strconv.ParseFloat(strings.Split(stats, "/")[1], 64)
+			avg, err = strconv.ParseFloat(stats[1], 64)
 			if err != nil {
-				return trans, recv, min, avg, max, stddev, err
+				return trans, recv, ttl, min, avg, max, stddev, err
 			}
-			max, err = strconv.ParseFloat(strings.Split(stats, "/")[2], 64)
+			max, err = strconv.ParseFloat(stats[2], 64)
 			if err != nil {
-				return trans, recv, min, avg, max, stddev, err
+				return trans, recv, ttl, min, avg, max, stddev, err
+			}
+			if len(stats) > 3 {
+				stddev, err = strconv.ParseFloat(stats[3], 64)
+				if err != nil {
+					return trans, recv, ttl, min, avg, max, stddev, err
+				}
 			}
-			stddev, err = strconv.ParseFloat(strings.Split(stats, "/")[3], 64)
+		}
+	}
+	return trans, recv, ttl, min, avg, max, stddev, err
+}
+
+// parseTTL returns the value of the ttl in an echo reply line, or -1.
+func parseTTL(line string) int {
+	for _, field := range strings.Fields(line) {
+		if strings.HasPrefix(field, "ttl=") {
+			ttl, err := strconv.Atoi(strings.TrimPrefix(field, "ttl="))
 			if err != nil {
-				return trans, recv, min, avg, max, stddev, err
+				return -1
 			}
+			return ttl
 		}
 	}
-	return trans, recv, min, avg, max, stddev, err
+	return -1
 }
 
 func init() {
@@ -246,6 +321,7 @@ func init() {
 			PingInterval: 1.0,
 			Count:        1,
 			Timeout:      1.0,
+			Method:       methodExec,
 		}
 	})
 }
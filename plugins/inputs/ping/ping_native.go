@@ -0,0 +1,367 @@
+// +build !windows
+
+package ping
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"net"
+	"os"
+	"sync/atomic"
+	"syscall"
+	"time"
+
+	"golang.org/x/net/icmp"
+	"golang.org/x/net/ipv4"
+	"golang.org/x/net/ipv6"
+)
+
+const (
+	methodExec   = "exec"
+	methodNative = "native"
+
+	protocolICMP     = 1
+	protocolIPv6ICMP = 58
+
+	// defaultNativeTimeout is used in native mode when no timeout is set,
+	// as a lost echo reply would otherwise block the collection forever
+	defaultNativeTimeout = 5 * time.Second
+)
+
+var errShortPacket = errors.New("received packet is too short")
+
+// echoID is incremented for every native ping, so that concurrent pings
+// sharing a raw socket can tell their replies apart.
+var echoID uint32
+
+// listenError is returned by nativePing when no ICMP socket can be opened,
+// usually because telegraf is running without the needed privileges.
+type listenError struct {
+	err error
+}
+
+func (e listenError) Error() string {
+	return fmt.Sprintf("could not open ICMP socket: %s", e.err)
+}
+
+// pingStats holds the summary of the echo requests sent to a host, with
+// response times in milliseconds. The ttl is the one of the first reply, or
+// -1 if there was no reply.
+type pingStats struct {
+	transmitted int
+	received    int
+	ttl         int
+	min         float64
+	avg         float64
+	max         float64
+	stddev      float64
+}
+
+// echoConn is the part of an ICMP socket used to send echo requests. Its
+// ReadFrom also returns the ttl, or hop limit, of the received packet.
+type echoConn interface {
+	ReadFrom(b []byte) (int, int, net.Addr, error)
+	WriteTo(b []byte, dst net.Addr) (int, error)
+	SetReadDeadline(t time.Time) error
+	Close() error
+}
+
+// echoSession sends echo requests to a single destination over conn.
+type echoSession struct {
+	conn     echoConn
+	dst      net.Addr
+	ipv6     bool
+	checkID  bool
+	id       int
+	timeout  time.Duration
+	interval time.Duration
+}
+
+// nativePing sends Count echo requests to host without using the ping
+// command. A listenError is returned when no ICMP socket can be opened.
+func (p *Ping) nativePing(host string) (*pingStats, error) {
+	addr, err := net.ResolveIPAddr("ip", host)
+	if err != nil {
+		return nil, err
+	}
+	isIPv6 := addr.IP.To4() == nil
+
+	var src net.IP
+	if p.Interface != "" {
+		src, err = sourceIP(p.Interface, isIPv6)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	conn, err := listenICMP(isIPv6, src)
+	if err != nil {
+		return nil, listenError{err: err}
+	}
+	defer conn.Close()
+
+	s := &echoSession{
+		conn:     conn,
+		dst:      addr,
+		ipv6:     isIPv6,
+		checkID:  conn.raw,
+		id:       int(atomic.AddUint32(&echoID, 1) & 0xffff),
+		timeout:  time.Duration(p.Timeout * float64(time.Second)),
+		interval: time.Duration(p.PingInterval * float64(time.Second)),
+	}
+	if !conn.raw {
+		// datagram ICMP sockets are addressed like UDP sockets
+		s.dst = &net.UDPAddr{IP: addr.IP, Zone: addr.Zone}
+	}
+	if s.timeout <= 0 {
+		s.timeout = defaultNativeTimeout
+	}
+	return s.run(p.Count)
+}
+
+// icmpConn is an ICMP socket which reports the ttl of the received packets.
+type icmpConn struct {
+	net.PacketConn
+	raw    bool
+	isIPv6 bool
+}
+
+// interfaceAddrs returns the addresses of the named network interface, or
+// the address itself when iface is the address of a local interface.
+func interfaceAddrs(iface string) ([]net.IP, error) {
+	if ip := net.ParseIP(iface); ip != nil {
+		addrs, err := net.InterfaceAddrs()
+		if err != nil {
+			return nil, err
+		}
+		for _, addr := range addrs {
+			if ipnet, ok := addr.(*net.IPNet); ok && ipnet.IP.Equal(ip) {
+				return []net.IP{ip}, nil
+			}
+		}
+		return nil, fmt.Errorf("interface address %s is not assigned to any interface", iface)
+	}
+
+	i, err := net.InterfaceByName(iface)
+	if err != nil {
+		return nil, fmt.Errorf("interface %s: %s", iface, err)
+	}
+	addrs, err := i.Addrs()
+	if err != nil {
+		return nil, err
+	}
+	var ips []net.IP
+	for _, addr := range addrs {
+		if ipnet, ok := addr.(*net.IPNet); ok {
+			ips = append(ips, ipnet.IP)
+		}
+	}
+	return ips, nil
+}
+
+// sourceIP returns the address of iface to send pings from, preferring
+// addresses which are not link-local.
+func sourceIP(iface string, isIPv6 bool) (net.IP, error) {
+	ips, err := interfaceAddrs(iface)
+	if err != nil {
+		return nil, err
+	}
+	var src net.IP
+	for _, ip := range ips {
+		if (ip.To4() == nil) != isIPv6 {
+			continue
+		}
+		if src == nil || src.IsLinkLocalUnicast() {
+			src = ip
+		}
+	}
+	if src == nil {
+		family := "IPv4"
+		if isIPv6 {
+			family = "IPv6"
+		}
+		return nil, fmt.Errorf("interface %s has no %s address", iface, family)
+	}
+	return src, nil
+}
+
+// listenICMP opens a raw ICMP socket, or an unprivileged datagram ICMP socket
+// where the system allows it, bound to the src address if it is set.
+func listenICMP(isIPv6 bool, src net.IP) (*icmpConn, error) {
+	conn, err := listenICMPSocket(isIPv6, syscall.SOCK_RAW, src)
+	if err == nil {
+		return &icmpConn{PacketConn: conn, raw: true, isIPv6: isIPv6}, nil
+	}
+	conn, dgramErr := listenICMPSocket(isIPv6, syscall.SOCK_DGRAM, src)
+	if dgramErr != nil {
+		return nil, err
+	}
+	return &icmpConn{PacketConn: conn, isIPv6: isIPv6}, nil
+}
+
+// listenICMPSocket creates an ICMP socket of the given type. The net package
+// cannot open datagram ICMP sockets, and neither can it ask for the ttl of
+// received packets, so the socket is set up with syscalls.
+func listenICMPSocket(isIPv6 bool, sotype int, src net.IP) (net.PacketConn, error) {
+	family, proto := syscall.AF_INET, protocolICMP
+	var sa syscall.Sockaddr
+	if isIPv6 {
+		family, proto = syscall.AF_INET6, protocolIPv6ICMP
+		sa6 := &syscall.SockaddrInet6{}
+		copy(sa6.Addr[:], src.To16())
+		sa = sa6
+	} else {
+		sa4 := &syscall.SockaddrInet4{}
+		copy(sa4.Addr[:], src.To4())
+		sa = sa4
+	}
+
+	fd, err := syscall.Socket(family, sotype, proto)
+	if err != nil {
+		return nil, os.NewSyscallError("socket", err)
+	}
+	syscall.CloseOnExec(fd)
+	if err := setTTLOption(fd, isIPv6); err != nil {
+		syscall.Close(fd)
+		return nil, os.NewSyscallError("setsockopt", err)
+	}
+	if err := syscall.Bind(fd, sa); err != nil {
+		syscall.Close(fd)
+		return nil, os.NewSyscallError("bind", err)
+	}
+
+	f := os.NewFile(uintptr(fd), "icmp")
+	defer f.Close()
+	return net.FilePacketConn(f)
+}
+
+// ReadFrom reads an ICMP message into b, returning its length, the ttl of
+// the packet, or -1 if unknown, and the sender address.
+func (c *icmpConn) ReadFrom(b []byte) (int, int, net.Addr, error) {
+	oob := make([]byte, 128)
+	var n, oobn int
+	var src net.Addr
+	var err error
+	switch conn := c.PacketConn.(type) {
+	case *net.IPConn:
+		n, oobn, _, src, err = conn.ReadMsgIP(b, oob)
+	case *net.UDPConn:
+		n, oobn, _, src, err = conn.ReadMsgUDP(b, oob)
+	default:
+		n, src, err = conn.ReadFrom(b)
+	}
+	if err != nil {
+		return 0, -1, nil, err
+	}
+	ttl := ttlFromControlMessage(oob[:oobn])
+
+	if c.raw && !c.isIPv6 {
+		// raw IPv4 sockets also receive the IP header
+		if n < ipv4.HeaderLen {
+			return 0, -1, src, errShortPacket
+		}
+		hdrlen := int(b[0]&0x0f) << 2
+		if hdrlen < ipv4.HeaderLen || n < hdrlen {
+			return 0, -1, src, errShortPacket
+		}
+		ttl = int(b[8])
+		n = copy(b, b[hdrlen:n])
+	}
+	return n, ttl, src, nil
+}
+
+// run sends count echo requests, waiting interval between them, and
+// summarizes the replies.
+func (s *echoSession) run(count int) (*pingStats, error) {
+	if count < 1 {
+		count = 1
+	}
+	stats := &pingStats{ttl: -1}
+	var rtts []float64
+	for seq := 0; seq < count; seq++ {
+		if seq > 0 && s.interval > 0 {
+			time.Sleep(s.interval)
+		}
+		stats.transmitted++
+		rtt, ttl, err := s.echo(seq)
+		if err != nil {
+			if nerr, ok := err.(net.Error); ok && nerr.Timeout() {
+				continue
+			}
+			return nil, err
+		}
+		if len(rtts) == 0 {
+			stats.ttl = ttl
+		}
+		rtts = append(rtts, float64(rtt)/float64(time.Millisecond))
+	}
+
+	stats.received = len(rtts)
+	if len(rtts) == 0 {
+		return stats, nil
+	}
+	var sum, sumSquares float64
+	stats.min = math.MaxFloat64
+	for _, rtt := range rtts {
+		sum += rtt
+		sumSquares += rtt * rtt
+		stats.min = math.Min(stats.min, rtt)
+		stats.max = math.Max(stats.max, rtt)
+	}
+	n := float64(len(rtts))
+	stats.avg = sum / n
+	// same as the mdev reported by iputils ping
+	stats.stddev = math.Sqrt(math.Max(sumSquares/n-stats.avg*stats.avg, 0))
+	return stats, nil
+}
+
+// echo sends a single echo request and waits for the matching reply,
+// returning the round trip time and the ttl of the reply.
+func (s *echoSession) echo(seq int) (time.Duration, int, error) {
+	var requestType, replyType icmp.Type = ipv4.ICMPTypeEcho, ipv4.ICMPTypeEchoReply
+	proto := protocolICMP
+	if s.ipv6 {
+		requestType, replyType = ipv6.ICMPTypeEchoRequest, ipv6.ICMPTypeEchoReply
+		proto = protocolIPv6ICMP
+	}
+
+	msg := icmp.Message{
+		Type: requestType,
+		Body: &icmp.Echo{
+			ID:   s.id,
+			Seq:  seq,
+			Data: []byte("telegraf-ping"),
+		},
+	}
+	// the kernel computes the checksum of ICMPv6 messages
+	b, err := msg.Marshal(nil)
+	if err != nil {
+		return 0, -1, err
+	}
+
+	start := time.Now()
+	if err := s.conn.SetReadDeadline(start.Add(s.timeout)); err != nil {
+		return 0, -1, err
+	}
+	if _, err := s.conn.WriteTo(b, s.dst); err != nil {
+		return 0, -1, err
+	}
+
+	reply := make([]byte, 1500)
+	for {
+		n, ttl, _, err := s.conn.ReadFrom(reply)
+		if err != nil {
+			return 0, -1, err
+		}
+		rm, err := icmp.ParseMessage(proto, reply[:n])
+		if err != nil || rm.Type != replyType {
+			continue
+		}
+		echo, ok := rm.Body.(*icmp.Echo)
+		if !ok || echo.Seq != seq || (s.checkID && echo.ID != s.id) {
+			continue
+		}
+		return time.Since(start), ttl, nil
+	}
+}
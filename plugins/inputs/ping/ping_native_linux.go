@@ -0,0 +1,34 @@
+package ping
+
+import (
+	"syscall"
+	"unsafe"
+)
+
+// setTTLOption asks for the ttl, or hop limit, of received packets to be
+// passed as a control message.
+func setTTLOption(fd int, isIPv6 bool) error {
+	if isIPv6 {
+		return syscall.SetsockoptInt(fd, syscall.IPPROTO_IPV6, syscall.IPV6_RECVHOPLIMIT, 1)
+	}
+	return syscall.SetsockoptInt(fd, syscall.IPPROTO_IP, syscall.IP_RECVTTL, 1)
+}
+
+// ttlFromControlMessage returns the ttl, or hop limit, from the control
+// messages of a received packet, or -1 when it is missing.
+func ttlFromControlMessage(oob []byte) int {
+	msgs, err := syscall.ParseSocketControlMessage(oob)
+	if err != nil {
+		return -1
+	}
+	for _, m := range msgs {
+		if len(m.Data) < 4 {
+			continue
+		}
+		if (m.Header.Level == syscall.IPPROTO_IP && m.Header.Type == syscall.IP_TTL) ||
+			(m.Header.Level == syscall.IPPROTO_IPV6 && m.Header.Type == syscall.IPV6_HOPLIMIT) {
+			return int(*(*int32)(unsafe.Pointer(&m.Data[0])))
+		}
+	}
+	return -1
+}
@@ -0,0 +1,13 @@
+// +build !linux,!windows
+
+package ping
+
+// setTTLOption is a no-op, the ttl is only known for IPv4 raw sockets,
+// which receive the IP header.
+func setTTLOption(fd int, isIPv6 bool) error {
+	return nil
+}
+
+func ttlFromControlMessage(oob []byte) int {
+	return -1
+}
@@ -0,0 +1,185 @@
+// +build !windows
+
+package ping
+
+import (
+	"net"
+	"testing"
+	"time"
+
+	"github.com/influxdata/telegraf/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/net/icmp"
+	"golang.org/x/net/ipv4"
+	"golang.org/x/net/ipv6"
+)
+
+// echoResponder answers the echo requests written to it, dropping the
+// sequence numbers in drop.
+type echoResponder struct {
+	ipv6     bool
+	drop     map[int]bool
+	replies  chan []byte
+	deadline time.Time
+}
+
+func newEchoResponder(ipv6 bool, drop ...int) *echoResponder {
+	r := &echoResponder{
+		ipv6:    ipv6,
+		drop:    make(map[int]bool),
+		replies: make(chan []byte, 16),
+	}
+	for _, seq := range drop {
+		r.drop[seq] = true
+	}
+	return r
+}
+
+func (r *echoResponder) WriteTo(b []byte, dst net.Addr) (int, error) {
+	proto, replyType := protocolICMP, icmp.Type(ipv4.ICMPTypeEchoReply)
+	if r.ipv6 {
+		proto, replyType = protocolIPv6ICMP, ipv6.ICMPTypeEchoReply
+	}
+	msg, err := icmp.ParseMessage(proto, b)
+	if err != nil {
+		return 0, err
+	}
+	echo := msg.Body.(*icmp.Echo)
+	if r.drop[echo.Seq] {
+		return len(b), nil
+	}
+
+	// an unrelated reply which must be skipped
+	other := icmp.Message{Type: replyType, Body: &icmp.Echo{ID: echo.ID + 1, Seq: echo.Seq}}
+	ob, _ := other.Marshal(nil)
+	r.replies <- ob
+
+	reply := icmp.Message{Type: replyType, Body: echo}
+	rb, err := reply.Marshal(nil)
+	if err != nil {
+		return 0, err
+	}
+	r.replies <- rb
+	return len(b), nil
+}
+
+func (r *echoResponder) ReadFrom(b []byte) (int, int, net.Addr, error) {
+	select {
+	case reply := <-r.replies:
+		return copy(b, reply), 64, &net.IPAddr{IP: net.IPv4(127, 0, 0, 1)}, nil
+	case <-time.After(r.deadline.Sub(time.Now())):
+		return 0, -1, nil, &net.OpError{Op: "read", Err: timeoutError{}}
+	}
+}
+
+func (r *echoResponder) SetReadDeadline(t time.Time) error {
+	r.deadline = t
+	return nil
+}
+
+func (r *echoResponder) Close() error {
+	return nil
+}
+
+type timeoutError struct{}
+
+func (timeoutError) Error() string   { return "i/o timeout" }
+func (timeoutError) Timeout() bool   { return true }
+func (timeoutError) Temporary() bool { return true }
+
+func TestEchoSession(t *testing.T) {
+	for _, isIPv6 := range []bool{false, true} {
+		s := &echoSession{
+			conn:    newEchoResponder(isIPv6, 1),
+			dst:     &net.IPAddr{IP: net.IPv4(127, 0, 0, 1)},
+			ipv6:    isIPv6,
+			checkID: true,
+			id:      42,
+			timeout: 50 * time.Millisecond,
+		}
+		stats, err := s.run(4)
+		require.NoError(t, err)
+
+		assert.Equal(t, 4, stats.transmitted)
+		assert.Equal(t, 3, stats.received)
+		assert.Equal(t, 64, stats.ttl)
+		assert.True(t, stats.min > 0)
+		assert.True(t, stats.min <= stats.avg)
+		assert.True(t, stats.avg <= stats.max)
+		assert.True(t, stats.stddev >= 0)
+		assert.True(t, stats.max < 50)
+	}
+}
+
+func TestEchoSessionNoReplies(t *testing.T) {
+	s := &echoSession{
+		conn:    newEchoResponder(false, 0, 1),
+		dst:     &net.IPAddr{IP: net.IPv4(127, 0, 0, 1)},
+		id:      42,
+		timeout: 10 * time.Millisecond,
+	}
+	stats, err := s.run(2)
+	require.NoError(t, err)
+
+	assert.Equal(t, &pingStats{transmitted: 2, ttl: -1}, stats)
+}
+
+func TestNativePingLoopback(t *testing.T) {
+	conn, err := listenICMP(false, nil)
+	if err != nil {
+		t.Skipf("cannot open ICMP socket: %s", err)
+	}
+	conn.Close()
+
+	var acc testutil.Accumulator
+	p := Ping{
+		Urls:      []string{"127.0.0.1"},
+		Count:     2,
+		Timeout:   1.0,
+		Method:    methodNative,
+		Interface: "127.0.0.1",
+	}
+	require.NoError(t, acc.GatherError(p.Gather))
+
+	m, ok := acc.Get("ping")
+	require.True(t, ok)
+	assert.Equal(t, "127.0.0.1", m.Tags["url"])
+	assert.Equal(t, 2, m.Fields["packets_transmitted"])
+	assert.Equal(t, 2, m.Fields["packets_received"])
+	assert.Equal(t, 0.0, m.Fields["percent_packet_loss"])
+	assert.Contains(t, m.Fields, "average_response_ms")
+	assert.Contains(t, m.Fields, "ttl")
+}
+
+func TestSourceIP(t *testing.T) {
+	ip, err := sourceIP("127.0.0.1", false)
+	require.NoError(t, err)
+	assert.Equal(t, "127.0.0.1", ip.String())
+
+	_, err = sourceIP("127.0.0.1", true)
+	assert.Error(t, err)
+}
+
+func TestGatherInterface(t *testing.T) {
+	var acc testutil.Accumulator
+	p := Ping{Interface: "127.0.0.1"}
+	assert.NoError(t, acc.GatherError(p.Gather))
+
+	p.Interface = "telegraf-missing0"
+	err := acc.GatherError(p.Gather)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "telegraf-missing0")
+
+	p.Interface = "192.0.2.1"
+	assert.Error(t, acc.GatherError(p.Gather))
+}
+
+func TestInvalidMethod(t *testing.T) {
+	var acc testutil.Accumulator
+	p := Ping{
+		Urls:   []string{"127.0.0.1"},
+		Method: "carrier-pigeon",
+	}
+	assert.Error(t, acc.GatherError(p.Gather))
+}
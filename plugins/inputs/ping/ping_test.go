@@ -48,29 +48,60 @@ ping: -i interval too short: Operation not permitted
 
 // Test that ping command output is processed properly
 func TestProcessPingOutput(t *testing.T) {
-	trans, rec, min, avg, max, stddev, err := processPingOutput(bsdPingOutput)
+	trans, rec, ttl, min, avg, max, stddev, err := processPingOutput(bsdPingOutput)
 	assert.NoError(t, err)
 	assert.Equal(t, 5, trans, "5 packets were transmitted")
 	assert.Equal(t, 5, rec, "5 packets were transmitted")
+	assert.Equal(t, 55, ttl, "ttl value is 55")
 	assert.InDelta(t, 15.087, min, 0.001)
 	assert.InDelta(t, 20.224, avg, 0.001)
 	assert.InDelta(t, 27.263, max, 0.001)
 	assert.InDelta(t, 4.076, stddev, 0.001)
 
-	trans, rec, min, avg, max, stddev, err = processPingOutput(linuxPingOutput)
+	trans, rec, ttl, min, avg, max, stddev, err = processPingOutput(linuxPingOutput)
 	assert.NoError(t, err)
 	assert.Equal(t, 5, trans, "5 packets were transmitted")
 	assert.Equal(t, 5, rec, "5 packets were transmitted")
+	assert.Equal(t, 63, ttl, "ttl value is 63")
 	assert.InDelta(t, 35.225, min, 0.001)
 	assert.InDelta(t, 43.628, avg, 0.001)
 	assert.InDelta(t, 51.806, max, 0.001)
 	assert.InDelta(t, 5.325, stddev, 0.001)
 }
 
+// BSD ping output without a stddev in the summary line
+var bsdNoStddevPingOutput = `
+PING www.google.com (216.58.217.36): 56 data bytes
+64 bytes from 216.58.217.36: icmp_seq=0 ttl=57 time=15.087 ms
+64 bytes from 216.58.217.36: icmp_seq=1 ttl=56 time=21.564 ms
+
+--- www.google.com ping statistics ---
+2 packets transmitted, 2 packets received, 0.0% packet loss
+round-trip min/avg/max = 15.087/18.325/21.564 ms
+`
+
+// Test that a missing stddev is left out and the first reply ttl is used
+func TestProcessPingOutputNoStddev(t *testing.T) {
+	trans, rec, ttl, min, avg, max, stddev, err := processPingOutput(bsdNoStddevPingOutput)
+	assert.NoError(t, err)
+	assert.Equal(t, 2, trans)
+	assert.Equal(t, 2, rec)
+	assert.Equal(t, 57, ttl)
+	assert.InDelta(t, 15.087, min, 0.001)
+	assert.InDelta(t, 18.325, avg, 0.001)
+	assert.InDelta(t, 21.564, max, 0.001)
+	assert.Equal(t, 0.0, stddev)
+
+	fields := map[string]interface{}{}
+	addStatsFields(fields, trans, rec, ttl, min, avg, max, stddev)
+	assert.NotContains(t, fields, "standard_deviation_ms")
+	assert.Equal(t, 57, fields["ttl"])
+}
+
 // Test that processPingOutput returns an error when 'ping' fails to run, such
 // as when an invalid argument is provided
 func TestErrorProcessPingOutput(t *testing.T) {
-	_, _, _, _, _, _, err := processPingOutput(fatalPingOutput)
+	_, _, _, _, _, _, _, err := processPingOutput(fatalPingOutput)
 	assert.Error(t, err, "Error was expected from processPingOutput")
 }
 
@@ -136,6 +167,24 @@ func TestArgs(t *testing.T) {
 		"Expected: %s Actual: %s", expected, actual)
 }
 
+// Test that the interface option is passed on to the ping command
+func TestArgsInterfaceAddress(t *testing.T) {
+	p := Ping{
+		Count:     1,
+		Interface: "192.0.2.10",
+	}
+
+	args := p.args("www.google.com")
+	for i, arg := range args {
+		if arg == "-I" {
+			assert.True(t, i+1 < len(args))
+			assert.Equal(t, "192.0.2.10", args[i+1])
+			return
+		}
+	}
+	t.Errorf("-I missing from ping arguments: %s", args)
+}
+
 func mockHostPinger(timeout float64, args ...string) (string, error) {
 	return linuxPingOutput, nil
 }
@@ -154,6 +203,7 @@ func TestPingGather(t *testing.T) {
 		"packets_transmitted":   5,
 		"packets_received":      5,
 		"percent_packet_loss":   0.0,
+		"ttl":                   63,
 		"minimum_response_ms":   35.225,
 		"average_response_ms":   43.628,
 		"maximum_response_ms":   51.806,
@@ -195,6 +245,7 @@ func TestLossyPingGather(t *testing.T) {
 		"packets_transmitted":   5,
 		"packets_received":      3,
 		"percent_packet_loss":   40.0,
+		"ttl":                   63,
 		"minimum_response_ms":   35.225,
 		"average_response_ms":   44.033,
 		"maximum_response_ms":   51.806,
@@ -4,6 +4,7 @@ import (
 	"bytes"
 	"database/sql"
 	"fmt"
+	"log"
 	"regexp"
 	"sort"
 	"strings"
@@ -16,11 +17,21 @@ import (
 )
 
 type Postgresql struct {
-	Address          string
-	Databases        []string
-	IgnoredDatabases []string
-	OrderedColumns   []string
-	AllColumns       []string
+	Address           string
+	Databases         []string
+	IgnoredDatabases  []string
+	GatherReplication bool
+	GatherStatements  bool
+	StatementsLimit   int
+	OrderedColumns    []string
+	AllColumns        []string
+	Query             []struct {
+		Sqlquery    string
+		Version     int
+		Withdbname  bool
+		Tagvalue    string
+		Measurement string
+	}
 	sanitizedAddress string
 }
 
@@ -49,6 +60,30 @@ var sampleConfig = `
   ## A list of databases to pull metrics about. If not specified, metrics for all
   ## databases are gathered.  Do NOT use with the 'ignored_databases' option.
   # databases = ["app_production", "testing"]
+
+  ## Gather the lag of the standbys on a primary and the replay delay on a
+  ## standby (postgres 9.2+).
+  # gather_replication = false
+
+  ## Gather the most expensive statements from the pg_stat_statements
+  ## extension (postgres 9.5+), ordered by their total time.
+  # gather_statements = false
+  # statements_limit = 100
+
+  ## Custom queries, the columns of the result are added as fields and the
+  ## columns listed in tagvalue (separated by commas) as tags. Columns which are
+  ## neither numeric nor tags are dropped. A query is only run if its version
+  ## is lower or equal to the server version, e.g. 906 for 9.6 and 1000 for 10.
+  ## If withdbname is true the query is completed with the list of databases
+  ## from above (or 'is not null'), e.g. "... where datname" becomes
+  ## "... where datname IN ('app_production', 'testing')".
+  ## The optional measurement overrides the default "postgresql" name.
+  # [[inputs.postgresql.query]]
+  #   sqlquery = "SELECT datname, count(*) AS locks FROM pg_locks JOIN pg_database ON pg_database.oid = database GROUP BY datname"
+  #   version = 901
+  #   withdbname = false
+  #   tagvalue = "datname"
+  #   measurement = "postgresql_locks"
 `
 
 func (p *Postgresql) SampleConfig() string {
@@ -140,7 +175,349 @@ func (p *Postgresql) Gather(acc telegraf.Accumulator) error {
 		}
 	}
 	sort.Strings(p.AllColumns)
-	return bg_writer_row.Err()
+	if err = bg_writer_row.Err(); err != nil {
+		return err
+	}
+
+	var version int
+	if err = db.QueryRow(`SHOW server_version_num`).Scan(&version); err != nil {
+		acc.AddError(err)
+		return nil
+	}
+
+	// the lsn functions used to compute the lag are available since 9.2
+	if p.GatherReplication && version >= 90200 {
+		if err = p.gatherReplication(db, version, acc); err != nil {
+			acc.AddError(err)
+		}
+	}
+
+	if p.GatherStatements {
+		if err = p.gatherStatements(db, version, acc); err != nil {
+			acc.AddError(err)
+		}
+	}
+
+	if len(p.Query) > 0 {
+		// 90605 is 9.6.5 and 100004 is 10.4, queries use 906 and 1000
+		p.gatherQueries(db, version/100, acc)
+	}
+	return nil
+}
+
+// replicationQuery returns the query of the standbys' state on a primary,
+// postgres 10 renamed the xlog functions and the location columns.
+func replicationQuery(version int) string {
+	if version >= 100000 {
+		return `
+			SELECT
+				COALESCE(client_addr::text, ''),
+				state,
+				pg_wal_lsn_diff(sent_lsn, '0/0')::bigint,
+				pg_wal_lsn_diff(pg_current_wal_lsn(), replay_lsn)::bigint,
+				EXTRACT(EPOCH FROM replay_lag)::float8
+			FROM pg_stat_replication`
+	}
+	return `
+		SELECT
+			COALESCE(client_addr::text, ''),
+			state,
+			pg_xlog_location_diff(sent_location, '0/0')::bigint,
+			pg_xlog_location_diff(pg_current_xlog_location(), replay_location)::bigint,
+			NULL::float8
+		FROM pg_stat_replication`
+}
+
+// gatherReplication reports the lag of every standby when the server is a
+// primary and the delay of the replay when it is a standby
+func (p *Postgresql) gatherReplication(db *sql.DB, version int, acc telegraf.Accumulator) error {
+	tagAddress, err := p.SanitizedAddress()
+	if err != nil {
+		return err
+	}
+
+	var inRecovery bool
+	if err = db.QueryRow(`SELECT pg_is_in_recovery()`).Scan(&inRecovery); err != nil {
+		return err
+	}
+
+	if inRecovery {
+		var delay sql.NullFloat64
+		err = db.QueryRow(`SELECT EXTRACT(EPOCH FROM now() - pg_last_xact_replay_timestamp())::float8`).Scan(&delay)
+		if err != nil {
+			return err
+		}
+		// nothing has been replayed yet
+		if !delay.Valid {
+			return nil
+		}
+		acc.AddFields("postgresql_replication",
+			map[string]interface{}{"replay_delay_seconds": delay.Float64},
+			map[string]string{"server": tagAddress})
+		return nil
+	}
+
+	rows, err := db.Query(replicationQuery(version))
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var (
+			clientAddr, state       string
+			sentLSN, replayLagBytes sql.NullInt64
+			replayLagSeconds        sql.NullFloat64
+		)
+		if err = rows.Scan(&clientAddr, &state, &sentLSN, &replayLagBytes, &replayLagSeconds); err != nil {
+			return err
+		}
+
+		tags := map[string]string{"server": tagAddress, "state": state}
+		if clientAddr != "" {
+			tags["client_addr"] = clientAddr
+		}
+		fields := make(map[string]interface{})
+		if sentLSN.Valid {
+			fields["sent_lsn"] = sentLSN.Int64
+		}
+		if replayLagBytes.Valid {
+			fields["replay_lag_bytes"] = replayLagBytes.Int64
+		}
+		// replay_lag is only tracked since postgres 10
+		if replayLagSeconds.Valid {
+			fields["replay_lag_seconds"] = replayLagSeconds.Float64
+		}
+		if len(fields) > 0 {
+			acc.AddFields("postgresql_replication", fields, tags)
+		}
+	}
+	return rows.Err()
+}
+
+const defaultStatementsLimit = 100
+
+// statementsQuery returns the query of the limit most expensive statements,
+// postgres 13 renamed total_time and mean_time to total_exec_time and
+// mean_exec_time.
+func statementsQuery(version int, limit int) string {
+	if limit <= 0 {
+		limit = defaultStatementsLimit
+	}
+	totalTime, meanTime := "total_time", "mean_time"
+	if version >= 130000 {
+		totalTime, meanTime = "total_exec_time", "mean_exec_time"
+	}
+	return fmt.Sprintf(`
+		SELECT
+			s.queryid::text,
+			d.datname,
+			s.calls,
+			s.%[1]s,
+			s.%[2]s,
+			s.rows,
+			s.shared_blks_hit,
+			s.shared_blks_read
+		FROM pg_stat_statements s
+		JOIN pg_database d ON d.oid = s.dbid
+		ORDER BY s.%[1]s DESC
+		LIMIT %[3]d`, totalTime, meanTime, limit)
+}
+
+// gatherStatements reports the statistics of the most expensive statements
+// if the pg_stat_statements extension is installed
+func (p *Postgresql) gatherStatements(db *sql.DB, version int, acc telegraf.Accumulator) error {
+	// queryid and mean_time were added in 9.4 and 9.5
+	if version < 90500 {
+		log.Printf("W! postgresql: pg_stat_statements requires postgres 9.5 or later, skipping statements")
+		return nil
+	}
+
+	var installed bool
+	err := db.QueryRow(`SELECT count(*) > 0 FROM pg_extension WHERE extname = 'pg_stat_statements'`).Scan(&installed)
+	if err != nil {
+		return err
+	}
+	if !installed {
+		log.Printf("W! postgresql: pg_stat_statements extension is not installed, skipping statements")
+		return nil
+	}
+
+	tagAddress, err := p.SanitizedAddress()
+	if err != nil {
+		return err
+	}
+
+	rows, err := db.Query(statementsQuery(version, p.StatementsLimit))
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var (
+			queryID, dbname              string
+			calls, resultRows, hit, read int64
+			totalTime, meanTime          float64
+		)
+		err = rows.Scan(&queryID, &dbname, &calls, &totalTime, &meanTime, &resultRows, &hit, &read)
+		if err != nil {
+			return err
+		}
+
+		tags := map[string]string{
+			"server":  tagAddress,
+			"queryid": queryID,
+			"datname": dbname,
+		}
+		fields := map[string]interface{}{
+			"calls":            calls,
+			"total_time":       totalTime,
+			"mean_time":        meanTime,
+			"rows":             resultRows,
+			"shared_blks_hit":  hit,
+			"shared_blks_read": read,
+		}
+		acc.AddFields("postgresql_statements", fields, tags)
+	}
+	return rows.Err()
+}
+
+// gatherQueries runs the custom queries supported by the server version
+func (p *Postgresql) gatherQueries(db *sql.DB, version int, acc telegraf.Accumulator) {
+	tagAddress, err := p.SanitizedAddress()
+	if err != nil {
+		acc.AddError(err)
+		return
+	}
+
+	for _, q := range p.Query {
+		if q.Version > version {
+			continue
+		}
+
+		query := q.Sqlquery
+		if q.Withdbname {
+			if len(p.Databases) != 0 {
+				query += fmt.Sprintf(` IN ('%s')`, strings.Join(p.Databases, "','"))
+			} else {
+				query += " is not null"
+			}
+		}
+
+		measurement := q.Measurement
+		if measurement == "" {
+			measurement = "postgresql"
+		}
+
+		var tagColumns []string
+		if q.Tagvalue != "" {
+			for _, tag := range strings.Split(q.Tagvalue, ",") {
+				tagColumns = append(tagColumns, strings.TrimSpace(tag))
+			}
+		}
+
+		if err := p.gatherQuery(db, query, measurement, tagAddress, tagColumns, acc); err != nil {
+			acc.AddError(err)
+		}
+	}
+}
+
+func (p *Postgresql) gatherQuery(
+	db *sql.DB,
+	query string,
+	measurement string,
+	tagAddress string,
+	tagColumns []string,
+	acc telegraf.Accumulator,
+) error {
+	rows, err := db.Query(query)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	columns, err := rows.Columns()
+	if err != nil {
+		return err
+	}
+
+	for rows.Next() {
+		values := make([]interface{}, len(columns))
+		columnVars := make([]interface{}, len(columns))
+		for i := range values {
+			columnVars[i] = &values[i]
+		}
+		if err = rows.Scan(columnVars...); err != nil {
+			return err
+		}
+
+		tags, fields := queryRowToMetric(columns, values, tagColumns)
+		tags["server"] = tagAddress
+		if len(fields) > 0 {
+			acc.AddFields(measurement, fields, tags)
+		}
+	}
+	return rows.Err()
+}
+
+// queryRowToMetric maps the columns of a custom query row to tags and fields.
+// The tag columns become tags, the numeric columns fields and all other
+// columns are dropped.
+func queryRowToMetric(
+	columns []string,
+	values []interface{},
+	tagColumns []string,
+) (map[string]string, map[string]interface{}) {
+	tags := map[string]string{"db": "postgres"}
+	fields := make(map[string]interface{})
+
+COLUMN:
+	for i, col := range columns {
+		val := values[i]
+		if val == nil {
+			continue
+		}
+		if col == "datname" {
+			if dbname, ok := toTagValue(val); ok {
+				tags["db"] = dbname
+			}
+		}
+
+		for _, tag := range tagColumns {
+			if col != tag {
+				continue
+			}
+			if v, ok := toTagValue(val); ok {
+				tags[col] = v
+			} else {
+				log.Printf("D! postgresql: unable to use column %s of type %T as tag", col, val)
+			}
+			continue COLUMN
+		}
+
+		switch val.(type) {
+		case int64, int32, int16, int, float64, float32:
+			fields[col] = val
+		default:
+			if col != "datname" {
+				log.Printf("D! postgresql: dropping non-numeric column %s of type %T", col, val)
+			}
+		}
+	}
+	return tags, fields
+}
+
+func toTagValue(val interface{}) (string, bool) {
+	switch v := val.(type) {
+	case string:
+		return v, true
+	case []byte:
+		return string(v), true
+	case int64, int32, int16, int:
+		return fmt.Sprintf("%d", v), true
+	}
+	return "", false
 }
 
 type scanner interface {
@@ -215,6 +592,8 @@ func (p *Postgresql) accRow(row scanner, acc telegraf.Accumulator) error {
 
 func init() {
 	inputs.Add("postgresql", func() telegraf.Input {
-		return &Postgresql{}
+		return &Postgresql{
+			StatementsLimit: defaultStatementsLimit,
+		}
 	})
 }
@@ -1,10 +1,12 @@
 package postgresql
 
 import (
+	"database/sql/driver"
 	"fmt"
 	"testing"
 
 	"github.com/influxdata/telegraf/testutil"
+	"github.com/influxdata/telegraf/testutil/fakesql"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
@@ -248,3 +250,276 @@ func TestPostgresqlDatabaseBlacklistTest(t *testing.T) {
 	assert.False(t, foundTemplate0)
 	assert.True(t, foundTemplate1)
 }
+
+func TestPostgresqlCustomQueries(t *testing.T) {
+	if testing.Short() {
+		t.Skip("Skipping integration test in short mode")
+	}
+
+	p := &Postgresql{
+		Address: fmt.Sprintf("host=%s user=postgres sslmode=disable",
+			testutil.GetLocalHost()),
+		Databases: []string{"postgres"},
+	}
+	p.Query = append(p.Query, struct {
+		Sqlquery    string
+		Version     int
+		Withdbname  bool
+		Tagvalue    string
+		Measurement string
+	}{
+		Sqlquery:    "SELECT datname, 'custom' AS kind, 42::bigint AS answer FROM pg_database WHERE datname",
+		Version:     901,
+		Withdbname:  true,
+		Tagvalue:    "kind",
+		Measurement: "postgresql_custom",
+	}, struct {
+		Sqlquery    string
+		Version     int
+		Withdbname  bool
+		Tagvalue    string
+		Measurement string
+	}{
+		Sqlquery: "SELECT 1::bigint AS future",
+		Version:  99999,
+	})
+
+	var acc testutil.Accumulator
+	require.NoError(t, acc.GatherError(p.Gather))
+
+	acc.AssertContainsTaggedFields(t, "postgresql_custom",
+		map[string]interface{}{"answer": int64(42)},
+		map[string]string{"db": "postgres", "kind": "custom", "server": p.Address})
+	assert.False(t, acc.HasInt64Field("postgresql", "future"))
+}
+
+func TestPostgresqlQueryRowToMetric(t *testing.T) {
+	tests := []struct {
+		name       string
+		columns    []string
+		values     []interface{}
+		tagColumns []string
+		tags       map[string]string
+		fields     map[string]interface{}
+	}{
+		{
+			name:       "queue depth",
+			columns:    []string{"datname", "queue", "depth", "oldest"},
+			values:     []interface{}{"app", []byte("emails"), int64(12), float64(3.5)},
+			tagColumns: []string{"queue"},
+			tags:       map[string]string{"db": "app", "queue": "emails"},
+			fields:     map[string]interface{}{"depth": int64(12), "oldest": float64(3.5)},
+		},
+		{
+			name:       "business counters",
+			columns:    []string{"region", "shard", "orders", "status", "note"},
+			values:     []interface{}{"eu", int32(3), int32(7), "open", nil},
+			tagColumns: []string{"region", "shard"},
+			tags:       map[string]string{"db": "postgres", "region": "eu", "shard": "3"},
+			fields:     map[string]interface{}{"orders": int32(7)},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tags, fields := queryRowToMetric(tt.columns, tt.values, tt.tagColumns)
+			require.Equal(t, tt.tags, tags)
+			require.Equal(t, tt.fields, fields)
+		})
+	}
+}
+
+func TestPostgresqlReplicationQuery(t *testing.T) {
+	q := replicationQuery(90605)
+	assert.Contains(t, q, "pg_xlog_location_diff(pg_current_xlog_location(), replay_location)")
+	assert.Contains(t, q, "sent_location")
+	assert.NotContains(t, q, "replay_lag")
+
+	q = replicationQuery(100004)
+	assert.Contains(t, q, "pg_wal_lsn_diff(pg_current_wal_lsn(), replay_lsn)")
+	assert.Contains(t, q, "sent_lsn")
+	assert.Contains(t, q, "replay_lag")
+}
+
+func TestPostgresqlReplicationOnPrimary(t *testing.T) {
+	if testing.Short() {
+		t.Skip("Skipping integration test in short mode")
+	}
+
+	p := &Postgresql{
+		Address: fmt.Sprintf("host=%s user=postgres sslmode=disable",
+			testutil.GetLocalHost()),
+		GatherReplication: true,
+	}
+
+	var acc testutil.Accumulator
+	require.NoError(t, acc.GatherError(p.Gather))
+
+	// the test server is a primary, standbys only report the replay delay
+	for _, m := range acc.Metrics {
+		if m.Measurement == "postgresql_replication" {
+			assert.NotContains(t, m.Fields, "replay_delay_seconds")
+		}
+	}
+}
+
+func TestPostgresqlGatherReplicationPrimary(t *testing.T) {
+	db, fake := fakesql.Open(t,
+		fakesql.Result{
+			Match:   "pg_is_in_recovery()",
+			Columns: []string{"pg_is_in_recovery"},
+			Rows:    [][]driver.Value{{false}},
+		},
+		fakesql.Result{
+			Match:   "FROM pg_stat_replication",
+			Columns: []string{"client_addr", "state", "sent_lsn", "replay_lag_bytes", "replay_lag"},
+			Rows: [][]driver.Value{
+				{"10.0.0.2", "streaming", int64(67108864), int64(1024), 0.25},
+				{"", "catchup", int64(33554432), nil, nil},
+			},
+		})
+	defer db.Close()
+
+	p := &Postgresql{Address: "host=localhost user=postgres sslmode=disable"}
+	server, err := p.SanitizedAddress()
+	require.NoError(t, err)
+
+	var acc testutil.Accumulator
+	require.NoError(t, p.gatherReplication(db, 100004, &acc))
+	assert.Empty(t, fake.Unmet())
+
+	assert.Len(t, acc.Metrics, 2)
+	acc.AssertContainsTaggedFields(t, "postgresql_replication",
+		map[string]interface{}{
+			"sent_lsn":           int64(67108864),
+			"replay_lag_bytes":   int64(1024),
+			"replay_lag_seconds": 0.25,
+		},
+		map[string]string{"server": server, "client_addr": "10.0.0.2", "state": "streaming"})
+	// standbys connected through a unix socket have no client_addr
+	acc.AssertContainsTaggedFields(t, "postgresql_replication",
+		map[string]interface{}{"sent_lsn": int64(33554432)},
+		map[string]string{"server": server, "state": "catchup"})
+}
+
+func TestPostgresqlGatherReplicationStandby(t *testing.T) {
+	db, fake := fakesql.Open(t,
+		fakesql.Result{
+			Match:   "pg_is_in_recovery()",
+			Columns: []string{"pg_is_in_recovery"},
+			Rows:    [][]driver.Value{{true}},
+		},
+		fakesql.Result{
+			Match:   "pg_last_xact_replay_timestamp()",
+			Columns: []string{"delay"},
+			Rows:    [][]driver.Value{{1.5}},
+		})
+	defer db.Close()
+
+	p := &Postgresql{Address: "host=localhost user=postgres sslmode=disable"}
+	server, err := p.SanitizedAddress()
+	require.NoError(t, err)
+
+	var acc testutil.Accumulator
+	require.NoError(t, p.gatherReplication(db, 100004, &acc))
+	assert.Empty(t, fake.Unmet())
+
+	assert.Len(t, acc.Metrics, 1)
+	acc.AssertContainsTaggedFields(t, "postgresql_replication",
+		map[string]interface{}{"replay_delay_seconds": 1.5},
+		map[string]string{"server": server})
+}
+
+func TestPostgresqlGatherReplicationStandbyNothingReplayed(t *testing.T) {
+	db, fake := fakesql.Open(t,
+		fakesql.Result{
+			Match:   "pg_is_in_recovery()",
+			Columns: []string{"pg_is_in_recovery"},
+			Rows:    [][]driver.Value{{true}},
+		},
+		fakesql.Result{
+			Match:   "pg_last_xact_replay_timestamp()",
+			Columns: []string{"delay"},
+			Rows:    [][]driver.Value{{nil}},
+		})
+	defer db.Close()
+
+	p := &Postgresql{Address: "host=localhost user=postgres sslmode=disable"}
+
+	var acc testutil.Accumulator
+	require.NoError(t, p.gatherReplication(db, 100004, &acc))
+	assert.Empty(t, fake.Unmet())
+	assert.Empty(t, acc.Metrics)
+}
+
+func TestPostgresqlStatementsQuery(t *testing.T) {
+	assert.Contains(t, statementsQuery(90605, 0), "ORDER BY s.total_time DESC\n\t\tLIMIT 100")
+	assert.Contains(t, statementsQuery(120004, 25), "ORDER BY s.total_time DESC\n\t\tLIMIT 25")
+
+	q := statementsQuery(130002, 25)
+	assert.Contains(t, q, "s.total_exec_time,\n\t\t\ts.mean_exec_time,")
+	assert.Contains(t, q, "ORDER BY s.total_exec_time DESC\n\t\tLIMIT 25")
+	assert.NotContains(t, q, "s.total_time")
+}
+
+func TestPostgresqlGatherStatements(t *testing.T) {
+	db, fake := fakesql.Open(t,
+		fakesql.Result{
+			Match:   "FROM pg_extension WHERE extname = 'pg_stat_statements'",
+			Columns: []string{"installed"},
+			Rows:    [][]driver.Value{{true}},
+		},
+		fakesql.Result{
+			Match: "ORDER BY s.total_exec_time DESC",
+			Columns: []string{"queryid", "datname", "calls", "total_exec_time", "mean_exec_time",
+				"rows", "shared_blks_hit", "shared_blks_read"},
+			Rows: [][]driver.Value{
+				{"-4123412", "app", int64(120), 5400.5, 45.0, int64(2400), int64(9000), int64(35)},
+			},
+		})
+	defer db.Close()
+
+	p := &Postgresql{
+		Address:          "host=localhost user=postgres sslmode=disable",
+		GatherStatements: true,
+		StatementsLimit:  1,
+	}
+	server, err := p.SanitizedAddress()
+	require.NoError(t, err)
+
+	var acc testutil.Accumulator
+	require.NoError(t, p.gatherStatements(db, 130002, &acc))
+	assert.Empty(t, fake.Unmet())
+
+	assert.Len(t, acc.Metrics, 1)
+	acc.AssertContainsTaggedFields(t, "postgresql_statements",
+		map[string]interface{}{
+			"calls":            int64(120),
+			"total_time":       5400.5,
+			"mean_time":        45.0,
+			"rows":             int64(2400),
+			"shared_blks_hit":  int64(9000),
+			"shared_blks_read": int64(35),
+		},
+		map[string]string{"server": server, "queryid": "-4123412", "datname": "app"})
+}
+
+func TestPostgresqlGatherStatementsNotInstalled(t *testing.T) {
+	// the statements aren't queried without the extension
+	db, fake := fakesql.Open(t, fakesql.Result{
+		Match:   "FROM pg_extension WHERE extname = 'pg_stat_statements'",
+		Columns: []string{"installed"},
+		Rows:    [][]driver.Value{{false}},
+	})
+	defer db.Close()
+
+	p := &Postgresql{
+		Address:          "host=localhost user=postgres sslmode=disable",
+		GatherStatements: true,
+	}
+
+	var acc testutil.Accumulator
+	require.NoError(t, p.gatherStatements(db, 100004, &acc))
+	assert.Empty(t, fake.Unmet())
+	assert.Empty(t, acc.Metrics)
+}
@@ -4,9 +4,12 @@ import (
 	"bytes"
 	"fmt"
 	"io/ioutil"
+	"log"
+	"os"
 	"os/exec"
 	"path/filepath"
 	"strconv"
+	"strings"
 	"time"
 
 	"github.com/influxdata/telegraf"
@@ -48,19 +51,22 @@ var sampleConfig = `
   # pattern = "nginx"
   ## user as argument for pgrep (ie, pgrep -u <user>)
   # user = "nginx"
-  ## Systemd unit name
+  ## Systemd unit name, all processes of the unit are monitored
   # systemd_unit = "nginx.service"
   ## CGroup name or path
   # cgroup = "systemd/system.slice/nginx.service"
 
   ## override for process_name
   ## This is optional; default is sourced from /proc/<pid>/status
+  ## When set and pid_tag is false, the fields of all matching processes are
+  ## summed up into a single metric with a num_processes field.
   # process_name = "bar"
   ## Field name prefix
   prefix = ""
   ## comment this out if you want raw cpu_time stats
   fielddrop = ["cpu_time_*"]
-  ## This is optional; moves pid into a tag instead of a field
+  ## This is optional; moves pid into a tag instead of a field, gathering
+  ## a metric per process even when process_name is set
   pid_tag = false
 `
 
@@ -87,6 +93,11 @@ func (p *Procstat) Gather(acc telegraf.Accumulator) error {
 	}
 	p.procs = procs
 
+	if p.ProcessName != "" && !p.PidTag {
+		p.addAggregateMetrics(acc)
+		return nil
+	}
+
 	for _, proc := range p.procs {
 		p.addMetrics(proc, acc)
 	}
@@ -96,6 +107,77 @@ func (p *Procstat) Gather(acc telegraf.Accumulator) error {
 
 // Add metrics a single Process
 func (p *Procstat) addMetrics(proc Process, acc telegraf.Accumulator) {
+	fields := p.processFields(proc)
+	if fields == nil {
+		return
+	}
+	acc.AddFields("procstat", fields, proc.Tags())
+}
+
+// addAggregateMetrics adds a single metric summing up the fields of all
+// processes, which share the same tags when process_name is set.
+func (p *Procstat) addAggregateMetrics(acc telegraf.Accumulator) {
+	var prefix string
+	if p.Prefix != "" {
+		prefix = p.Prefix + "_"
+	}
+
+	var tags map[string]string
+	var count int64
+	totals := map[string]interface{}{}
+	for _, proc := range p.procs {
+		fields := p.processFields(proc)
+		if fields == nil {
+			continue
+		}
+		for k, v := range fields {
+			if !isSummableField(strings.TrimPrefix(k, prefix)) {
+				continue
+			}
+			totals[k] = sumField(totals[k], v)
+		}
+		tags = proc.Tags()
+		count++
+	}
+	if tags == nil {
+		return
+	}
+	totals[prefix+"num_processes"] = count
+	acc.AddFields("procstat", totals, tags)
+}
+
+// isSummableField reports whether the field of a process can be summed up
+// with the one of other processes, which excludes the pid, priorities and
+// resource limits.
+func isSummableField(name string) bool {
+	switch name {
+	case "pid", "nice_priority", "realtime_priority":
+		return false
+	}
+	return !strings.HasPrefix(name, "rlimit_")
+}
+
+func sumField(total, value interface{}) interface{} {
+	switch v := value.(type) {
+	case int32:
+		t, _ := total.(int32)
+		return t + v
+	case int64:
+		t, _ := total.(int64)
+		return t + v
+	case uint64:
+		t, _ := total.(uint64)
+		return t + v
+	case float64:
+		t, _ := total.(float64)
+		return t + v
+	}
+	return total
+}
+
+// processFields returns the fields of a single Process, or nil if the
+// process no longer exists
+func (p *Procstat) processFields(proc Process) map[string]interface{} {
 	var prefix string
 	if p.Prefix != "" {
 		prefix = p.Prefix + "_"
@@ -119,11 +201,16 @@ func (p *Procstat) addMetrics(proc Process, acc telegraf.Accumulator) {
 	numThreads, err := proc.NumThreads()
 	if err == nil {
 		fields[prefix+"num_threads"] = numThreads
+	} else if os.IsNotExist(err) {
+		// The process exited after it was found
+		return nil
 	}
 
 	fds, err := proc.NumFDs()
 	if err == nil {
 		fields[prefix+"num_fds"] = fds
+	} else if os.IsPermission(err) {
+		log.Printf("D! [inputs.procstat] Not allowed to count open files of pid %d: %s", proc.PID(), err)
 	}
 
 	ctx, err := proc.NumCtxSwitches()
@@ -170,7 +257,14 @@ func (p *Procstat) addMetrics(proc Process, acc telegraf.Accumulator) {
 		fields[prefix+"memory_locked"] = mem.Locked
 	}
 
-	rlims, err := proc.RlimitUsage(true)
+	// Gathering the usage fails if any of it, such as the open files, cannot
+	// be read, in which case only the limits are reported.
+	gatherUsage := true
+	rlims, err := proc.RlimitUsage(gatherUsage)
+	if err != nil {
+		gatherUsage = false
+		rlims, err = proc.RlimitUsage(gatherUsage)
+	}
 	if err == nil {
 		for _, rlim := range rlims {
 			var name string
@@ -203,13 +297,13 @@ func (p *Procstat) addMetrics(proc Process, acc telegraf.Accumulator) {
 
 			fields[prefix+"rlimit_"+name+"_soft"] = rlim.Soft
 			fields[prefix+"rlimit_"+name+"_hard"] = rlim.Hard
-			if name != "file_locks" { // gopsutil doesn't currently track the used file locks count
+			if gatherUsage && name != "file_locks" { // gopsutil doesn't currently track the used file locks count
 				fields[prefix+name] = rlim.Used
 			}
 		}
 	}
 
-	acc.AddFields("procstat", fields, proc.Tags())
+	return fields
 }
 
 // Update monitored Processes
@@ -301,7 +395,32 @@ func (p *Procstat) findPids() ([]PID, map[string]string, error) {
 // execCommand is so tests can mock out exec.Command usage.
 var execCommand = exec.Command
 
+// systemdCGroupRoot is the parent cgroup of system units in the systemd
+// cgroup hierarchy, it can be changed by tests.
+var systemdCGroupRoot = "/sys/fs/cgroup/systemd/system.slice"
+
+// systemdUnitPIDs returns the processes in the cgroup of the systemd unit,
+// or none when the unit is stopped.
 func (p *Procstat) systemdUnitPIDs() ([]PID, error) {
+	if _, err := os.Stat(systemdCGroupRoot); err != nil {
+		// Without the systemd cgroup hierarchy only the main process of
+		// the unit can be found.
+		return p.systemdMainPID()
+	}
+
+	unit := p.SystemdUnit
+	if !strings.Contains(unit, ".") {
+		unit += ".service"
+	}
+	pids, err := readCGroupProcs(filepath.Join(systemdCGroupRoot, unit))
+	if os.IsNotExist(err) {
+		// systemd removes the cgroup of stopped units
+		return nil, nil
+	}
+	return pids, err
+}
+
+func (p *Procstat) systemdMainPID() ([]PID, error) {
 	var pids []PID
 	cmd := execCommand("systemctl", "show", p.SystemdUnit)
 	out, err := cmd.Output()
@@ -329,14 +448,19 @@ func (p *Procstat) systemdUnitPIDs() ([]PID, error) {
 }
 
 func (p *Procstat) cgroupPIDs() ([]PID, error) {
-	var pids []PID
-
 	procsPath := p.CGroup
 	if procsPath[0] != '/' {
 		procsPath = "/sys/fs/cgroup/" + procsPath
 	}
-	procsPath = filepath.Join(procsPath, "cgroup.procs")
-	out, err := ioutil.ReadFile(procsPath)
+	return readCGroupProcs(procsPath)
+}
+
+// readCGroupProcs returns the processes listed in the cgroup.procs file of
+// the cgroup directory.
+func readCGroupProcs(dir string) ([]PID, error) {
+	var pids []PID
+
+	out, err := ioutil.ReadFile(filepath.Join(dir, "cgroup.procs"))
 	if err != nil {
 		return nil, err
 	}
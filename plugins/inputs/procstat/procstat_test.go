@@ -6,6 +6,8 @@ import (
 	"os"
 	"os/exec"
 	"path/filepath"
+	"runtime"
+	"strconv"
 	"strings"
 	"testing"
 	"time"
@@ -143,6 +145,33 @@ func (p *testProc) RlimitUsage(gatherUsage bool) ([]process.RlimitStat, error) {
 	return []process.RlimitStat{}, nil
 }
 
+// valueProc reports resource usages derived from its pid
+type valueProc struct {
+	testProc
+}
+
+func newValueProc(pid PID) (Process, error) {
+	return &valueProc{testProc{pid: pid, tags: make(map[string]string)}}, nil
+}
+
+func (p *valueProc) MemoryInfo() (*process.MemoryInfoStat, error) {
+	return &process.MemoryInfoStat{RSS: uint64(p.pid) * 1024}, nil
+}
+
+func (p *valueProc) NumFDs() (int32, error) {
+	return int32(p.pid), nil
+}
+
+func (p *valueProc) Times() (*cpu.TimesStat, error) {
+	return &cpu.TimesStat{User: float64(p.pid) / 10}, nil
+}
+
+func (p *valueProc) RlimitUsage(gatherUsage bool) ([]process.RlimitStat, error) {
+	return []process.RlimitStat{
+		{Resource: process.RLIMIT_NOFILE, Soft: 1024, Hard: 4096, Used: uint64(p.pid)},
+	}, nil
+}
+
 var pid PID = PID(42)
 var exe string = "foo"
 
@@ -185,6 +214,44 @@ func TestGather_ProcessName(t *testing.T) {
 	assert.Equal(t, "custom_name", acc.TagValue("procstat", "process_name"))
 }
 
+func TestGather_ProcessNameAggregate(t *testing.T) {
+	var acc testutil.Accumulator
+
+	p := Procstat{
+		Pattern:         "worker",
+		ProcessName:     "worker",
+		createPIDFinder: pidFinder([]PID{1, 2, 3}, nil),
+		createProcess:   newValueProc,
+	}
+	require.NoError(t, acc.GatherError(p.Gather))
+
+	require.Len(t, acc.Metrics, 1)
+	m := acc.Metrics[0]
+	assert.Equal(t, map[string]string{"pattern": "worker", "process_name": "worker"}, m.Tags)
+	assert.Equal(t, int64(3), m.Fields["num_processes"])
+	assert.Equal(t, uint64(6), m.Fields["num_fds"])
+	assert.Equal(t, uint64(6*1024), m.Fields["memory_rss"])
+	assert.InDelta(t, 0.6, m.Fields["cpu_time_user"], 0.0001)
+	assert.NotContains(t, m.Fields, "pid")
+	assert.NotContains(t, m.Fields, "rlimit_num_fds_soft")
+}
+
+func TestGather_ProcessNamePidTag(t *testing.T) {
+	var acc testutil.Accumulator
+
+	p := Procstat{
+		Pattern:         "worker",
+		ProcessName:     "worker",
+		PidTag:          true,
+		createPIDFinder: pidFinder([]PID{1, 2, 3}, nil),
+		createProcess:   newValueProc,
+	}
+	require.NoError(t, acc.GatherError(p.Gather))
+
+	assert.Len(t, acc.Metrics, 3)
+	assert.False(t, acc.HasField("procstat", "num_processes"))
+}
+
 func TestGather_NoProcessNameUsesReal(t *testing.T) {
 	var acc testutil.Accumulator
 	pid := PID(os.Getpid())
@@ -338,6 +405,9 @@ func TestGather_PercentSecondPass(t *testing.T) {
 }
 
 func TestGather_systemdUnitPIDs(t *testing.T) {
+	defer func(root string) { systemdCGroupRoot = root }(systemdCGroupRoot)
+	systemdCGroupRoot = "/nonexistent/system.slice"
+
 	p := Procstat{
 		createPIDFinder: pidFinder([]PID{}, nil),
 		SystemdUnit:     "TestGather_systemdUnitPIDs",
@@ -348,6 +418,58 @@ func TestGather_systemdUnitPIDs(t *testing.T) {
 	assert.Equal(t, "TestGather_systemdUnitPIDs", tags["systemd_unit"])
 }
 
+func TestGather_systemdUnitCGroupPIDs(t *testing.T) {
+	td, err := ioutil.TempDir("", "")
+	require.NoError(t, err)
+	defer os.RemoveAll(td)
+	defer func(root string) { systemdCGroupRoot = root }(systemdCGroupRoot)
+	systemdCGroupRoot = td
+
+	unitDir := filepath.Join(td, "nginx.service")
+	require.NoError(t, os.Mkdir(unitDir, 0755))
+	err = ioutil.WriteFile(filepath.Join(unitDir, "cgroup.procs"), []byte("1234\n5678\n"), 0644)
+	require.NoError(t, err)
+
+	for _, unit := range []string{"nginx.service", "nginx"} {
+		p := Procstat{
+			createPIDFinder: pidFinder([]PID{}, nil),
+			SystemdUnit:     unit,
+		}
+		pids, tags, err := p.findPids()
+		require.NoError(t, err)
+		assert.Equal(t, []PID{1234, 5678}, pids)
+		assert.Equal(t, unit, tags["systemd_unit"])
+	}
+
+	var acc testutil.Accumulator
+	p := Procstat{
+		SystemdUnit:     "nginx.service",
+		PidTag:          true,
+		createPIDFinder: pidFinder([]PID{}, nil),
+		createProcess:   newTestProc,
+	}
+	require.NoError(t, acc.GatherError(p.Gather))
+	assert.Len(t, acc.Metrics, 2)
+	assert.Equal(t, "nginx.service", acc.TagValue("procstat", "systemd_unit"))
+}
+
+func TestGather_systemdUnitStopped(t *testing.T) {
+	td, err := ioutil.TempDir("", "")
+	require.NoError(t, err)
+	defer os.RemoveAll(td)
+	defer func(root string) { systemdCGroupRoot = root }(systemdCGroupRoot)
+	systemdCGroupRoot = td
+
+	var acc testutil.Accumulator
+	p := Procstat{
+		SystemdUnit:     "nginx.service",
+		createPIDFinder: pidFinder([]PID{}, nil),
+		createProcess:   newTestProc,
+	}
+	require.NoError(t, acc.GatherError(p.Gather))
+	assert.Len(t, acc.Metrics, 0)
+}
+
 func TestGather_cgroupPIDs(t *testing.T) {
 	td, err := ioutil.TempDir("", "")
 	require.NoError(t, err)
@@ -364,3 +486,103 @@ func TestGather_cgroupPIDs(t *testing.T) {
 	assert.Equal(t, []PID{1234, 5678}, pids)
 	assert.Equal(t, td, tags["cgroup"])
 }
+
+// Test the open files count and limits of a process in a fake procfs, which
+// lacks the files needed to gather the other resource usages.
+func TestGather_NumFDsFakeProcfs(t *testing.T) {
+	if runtime.GOOS != "linux" {
+		t.Skip("open files are only gathered on linux")
+	}
+	td := writeFakeProcStatus(t, pid, "Name:\tfoo\nThreads:\t1\n")
+	defer os.RemoveAll(td)
+
+	procDir := filepath.Join(td, strconv.Itoa(int(pid)))
+	require.NoError(t, os.MkdirAll(filepath.Join(procDir, "fd"), 0755))
+	for _, fd := range []string{"0", "1", "2"} {
+		require.NoError(t, os.Symlink("/dev/null", filepath.Join(procDir, "fd", fd)))
+	}
+	limits := `Limit                     Soft Limit           Hard Limit           Units
+Max open files            1024                 4096                 files
+`
+	require.NoError(t, ioutil.WriteFile(filepath.Join(procDir, "limits"), []byte(limits), 0644))
+
+	defer os.Setenv("HOST_PROC", os.Getenv("HOST_PROC"))
+	os.Setenv("HOST_PROC", td)
+
+	var acc testutil.Accumulator
+	p := Procstat{
+		Exe:             exe,
+		createPIDFinder: pidFinder([]PID{pid}, nil),
+		createProcess:   NewProc,
+	}
+	require.NoError(t, acc.GatherError(p.Gather))
+
+	m, ok := acc.Get("procstat")
+	require.True(t, ok)
+	assert.Equal(t, int32(3), m.Fields["num_fds"])
+	assert.Equal(t, int32(1024), m.Fields["rlimit_num_fds_soft"])
+	assert.Equal(t, int32(4096), m.Fields["rlimit_num_fds_hard"])
+	// the usage of the other limits could not be gathered
+	assert.NotContains(t, m.Fields, "nice_priority")
+}
+
+// writeFakeProcStatus creates a fake procfs with the status file of pid.
+func writeFakeProcStatus(t *testing.T, pid PID, status string) string {
+	td, err := ioutil.TempDir("", "")
+	require.NoError(t, err)
+
+	procDir := filepath.Join(td, strconv.Itoa(int(pid)))
+	require.NoError(t, os.MkdirAll(procDir, 0755))
+	require.NoError(t, ioutil.WriteFile(filepath.Join(procDir, "status"), []byte(status), 0644))
+	return td
+}
+
+func TestGather_NumThreadsFakeProcfs(t *testing.T) {
+	if runtime.GOOS != "linux" {
+		t.Skip("fake procfs is only used on linux")
+	}
+	td := writeFakeProcStatus(t, pid, "Name:\tfoo\nState:\tS (sleeping)\nThreads:\t7\n")
+	defer os.RemoveAll(td)
+
+	defer os.Setenv("HOST_PROC", os.Getenv("HOST_PROC"))
+	os.Setenv("HOST_PROC", td)
+
+	for _, p := range []*Procstat{
+		{PidFile: "/path/to/pidfile"},
+		{Pattern: "foo"},
+	} {
+		var acc testutil.Accumulator
+		p.createPIDFinder = pidFinder([]PID{pid}, nil)
+		p.createProcess = NewProc
+		require.NoError(t, acc.GatherError(p.Gather))
+
+		m, ok := acc.Get("procstat")
+		require.True(t, ok)
+		assert.Equal(t, int32(7), m.Fields["num_threads"])
+	}
+}
+
+func TestGather_ProcessExited(t *testing.T) {
+	if runtime.GOOS != "linux" {
+		t.Skip("fake procfs is only used on linux")
+	}
+	td := writeFakeProcStatus(t, pid, "Name:\tfoo\nThreads:\t7\n")
+	defer os.RemoveAll(td)
+
+	defer os.Setenv("HOST_PROC", os.Getenv("HOST_PROC"))
+	os.Setenv("HOST_PROC", td)
+
+	var acc testutil.Accumulator
+	p := Procstat{
+		Pattern:         "foo",
+		createPIDFinder: pidFinder([]PID{pid}, nil),
+		createProcess: func(pid PID) (Process, error) {
+			proc, err := NewProc(pid)
+			// the process exits right after it was found
+			os.RemoveAll(filepath.Join(td, strconv.Itoa(int(pid))))
+			return proc, err
+		},
+	}
+	require.NoError(t, acc.GatherError(p.Gather))
+	assert.Len(t, acc.Metrics, 0)
+}
@@ -0,0 +1,167 @@
+package prometheus
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"log"
+	"text/template"
+	"time"
+
+	"github.com/hashicorp/consul/api"
+	"github.com/influxdata/telegraf/internal"
+)
+
+const (
+	defaultConsulQueryInterval = 5 * time.Minute
+	defaultConsulServiceURL    = "http://{{if .ServiceAddress}}{{.ServiceAddress}}{{else}}{{.Address}}{{end}}:{{.ServicePort}}/metrics"
+)
+
+// ConsulConfig configures the discovery of scrape targets in the Consul
+// catalog.
+type ConsulConfig struct {
+	Enabled bool
+	// Address of the Consul agent, the Consul defaults are used when empty
+	Agent string
+	// How often the catalog is queried for new or removed instances
+	QueryInterval internal.Duration `toml:"query_interval"`
+	Queries       []*ConsulQuery    `toml:"query"`
+}
+
+// ConsulQuery selects the healthy instances of a service to scrape.
+type ConsulQuery struct {
+	ServiceName string `toml:"name"`
+	ServiceTag  string `toml:"tag"`
+	// Template of the scrape URL, executed with the api.CatalogService of
+	// each instance
+	ServiceURL string `toml:"url"`
+
+	serviceURLTemplate *template.Template
+}
+
+// startConsul resolves the configured queries once and then refreshes them
+// every query_interval until the context is cancelled.
+func (p *Prometheus) startConsul(ctx context.Context) error {
+	for _, q := range p.ConsulConfig.Queries {
+		if q.ServiceName == "" {
+			return fmt.Errorf("consul query is missing the service name")
+		}
+		serviceURL := q.ServiceURL
+		if serviceURL == "" {
+			serviceURL = defaultConsulServiceURL
+		}
+		tmpl, err := template.New("url").Parse(serviceURL)
+		if err != nil {
+			return fmt.Errorf("could not parse the consul query URL template %q: %s",
+				serviceURL, err)
+		}
+		q.serviceURLTemplate = tmpl
+	}
+
+	config := api.DefaultConfig()
+	if p.ConsulConfig.Agent != "" {
+		config.Address = p.ConsulConfig.Agent
+	}
+	client, err := api.NewClient(config)
+	if err != nil {
+		return fmt.Errorf("error creating Consul client: %s", err)
+	}
+
+	interval := p.ConsulConfig.QueryInterval.Duration
+	if interval == 0 {
+		interval = defaultConsulQueryInterval
+	}
+
+	p.refreshConsulServices(client)
+
+	p.wg.Add(1)
+	go func() {
+		defer p.wg.Done()
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				p.refreshConsulServices(client)
+			}
+		}
+	}()
+	return nil
+}
+
+// refreshConsulServices replaces the Consul scrape targets with the healthy
+// instances currently in the catalog. The previous targets of a query are
+// kept when the catalog can't be reached.
+func (p *Prometheus) refreshConsulServices(client *api.Client) {
+	targets := make(map[string]UrlAndAddress)
+	for _, q := range p.ConsulConfig.Queries {
+		entries, _, err := client.Health().Service(q.ServiceName, q.ServiceTag, true, nil)
+		if err != nil {
+			log.Printf("E! [inputs.prometheus] Error querying Consul for service %s: %s", q.ServiceName, err)
+			p.lock.Lock()
+			for key, target := range p.consulServices {
+				if target.Tags["consul_service"] == q.ServiceName {
+					targets[key] = target
+				}
+			}
+			p.lock.Unlock()
+			continue
+		}
+
+		for _, entry := range entries {
+			target, err := q.target(entry)
+			if err != nil {
+				log.Printf("E! [inputs.prometheus] Error building scrape URL for service %s: %s", q.ServiceName, err)
+				continue
+			}
+			targets[target.Url] = target
+		}
+	}
+
+	p.lock.Lock()
+	defer p.lock.Unlock()
+	for key := range targets {
+		if _, ok := p.consulServices[key]; !ok {
+			log.Printf("D! [inputs.prometheus] Adding scrape target %s from Consul", key)
+		}
+	}
+	p.consulServices = targets
+}
+
+func (q *ConsulQuery) target(entry *api.ServiceEntry) (UrlAndAddress, error) {
+	service := &api.CatalogService{
+		ID:              entry.Node.ID,
+		Node:            entry.Node.Node,
+		Address:         entry.Node.Address,
+		Datacenter:      entry.Node.Datacenter,
+		TaggedAddresses: entry.Node.TaggedAddresses,
+		NodeMeta:        entry.Node.Meta,
+		ServiceID:       entry.Service.ID,
+		ServiceName:     entry.Service.Service,
+		ServiceAddress:  entry.Service.Address,
+		ServiceTags:     entry.Service.Tags,
+		ServicePort:     entry.Service.Port,
+	}
+
+	var buf bytes.Buffer
+	if err := q.serviceURLTemplate.Execute(&buf, service); err != nil {
+		return UrlAndAddress{}, err
+	}
+
+	address := service.ServiceAddress
+	if address == "" {
+		address = service.Address
+	}
+
+	return UrlAndAddress{
+		OriginalUrl: buf.String(),
+		Url:         buf.String(),
+		Address:     address,
+		Tags: map[string]string{
+			"consul_service": service.ServiceName,
+			"consul_node":    service.Node,
+		},
+	}, nil
+}
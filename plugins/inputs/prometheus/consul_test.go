@@ -0,0 +1,177 @@
+package prometheus
+
+import (
+	"fmt"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/influxdata/telegraf/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// $ curl 'localhost:8500/v1/health/service/web?passing=1&tag=prometheus'
+const sampleConsulHealthResponse = `[
+  {
+    "Node": {
+      "ID": "40e4a748-2192-161a-0510-9bf59fe950b5",
+      "Node": "node-1",
+      "Address": "10.1.10.12",
+      "Datacenter": "dc1",
+      "TaggedAddresses": {"lan": "10.1.10.12", "wan": "10.1.10.12"},
+      "Meta": {}
+    },
+    "Service": {
+      "ID": "web-1",
+      "Service": "web",
+      "Tags": ["prometheus"],
+      "Address": "%s",
+      "Port": %s
+    },
+    "Checks": []
+  },
+  {
+    "Node": {
+      "ID": "4c1fa1f0-6c8b-4c84-8fb1-0d8e6e1b0d31",
+      "Node": "node-2",
+      "Address": "10.1.10.13",
+      "Datacenter": "dc1",
+      "TaggedAddresses": {"lan": "10.1.10.13", "wan": "10.1.10.13"},
+      "Meta": {}
+    },
+    "Service": {
+      "ID": "web-2",
+      "Service": "web",
+      "Tags": ["prometheus"],
+      "Address": "",
+      "Port": 9100
+    },
+    "Checks": []
+  }
+]`
+
+func TestConsulDiscovery(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, sampleTextFormat)
+	}))
+	defer ts.Close()
+	u, err := url.Parse(ts.URL)
+	require.NoError(t, err)
+	host, port, err := net.SplitHostPort(u.Host)
+	require.NoError(t, err)
+
+	var query url.Values
+	consul := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/v1/health/service/web" {
+			http.NotFound(w, r)
+			return
+		}
+		query = r.URL.Query()
+		fmt.Fprintf(w, sampleConsulHealthResponse, host, port)
+	}))
+	defer consul.Close()
+
+	p := &Prometheus{
+		ConsulConfig: ConsulConfig{
+			Enabled: true,
+			Agent:   consul.URL,
+			Queries: []*ConsulQuery{
+				{ServiceName: "web", ServiceTag: "prometheus"},
+			},
+		},
+	}
+
+	var acc testutil.Accumulator
+	require.NoError(t, p.Start(&acc))
+	defer p.Stop()
+
+	assert.Equal(t, "prometheus", query.Get("tag"))
+	_, passing := query["passing"]
+	assert.True(t, passing)
+
+	urls, err := p.GetAllURLs()
+	require.NoError(t, err)
+	require.Len(t, urls, 2)
+
+	targets := make(map[string]UrlAndAddress)
+	for _, u := range urls {
+		targets[u.Tags["consul_node"]] = u
+	}
+	assert.Equal(t, ts.URL+"/metrics", targets["node-1"].Url)
+	assert.Equal(t, host, targets["node-1"].Address)
+	assert.Equal(t, "web", targets["node-1"].Tags["consul_service"])
+	// the node address is used when the service has none
+	assert.Equal(t, "http://10.1.10.13:9100/metrics", targets["node-2"].Url)
+	assert.Equal(t, "10.1.10.13", targets["node-2"].Address)
+
+	// only scrape the reachable instance
+	p.lock.Lock()
+	p.consulServices = map[string]UrlAndAddress{"node-1": targets["node-1"]}
+	p.lock.Unlock()
+	require.NoError(t, acc.GatherError(p.Gather))
+	assert.Equal(t, "web", acc.TagValue("go_goroutines", "consul_service"))
+	assert.Equal(t, "node-1", acc.TagValue("go_goroutines", "consul_node"))
+}
+
+func TestConsulDiscoveryURLTemplate(t *testing.T) {
+	q := &ConsulQuery{
+		ServiceName: "web",
+		ServiceURL:  "https://{{.Node}}.example.org:{{.ServicePort}}/{{.ServiceName}}/metrics",
+	}
+	p := &Prometheus{
+		ConsulConfig: ConsulConfig{
+			Enabled: true,
+			Agent:   "127.0.0.1:1",
+			Queries: []*ConsulQuery{q},
+		},
+	}
+
+	var acc testutil.Accumulator
+	require.NoError(t, p.Start(&acc))
+	p.Stop()
+
+	// an unreachable agent leaves no targets
+	urls, err := p.GetAllURLs()
+	require.NoError(t, err)
+	assert.Len(t, urls, 0)
+
+	consul := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprintf(w, sampleConsulHealthResponse, "", "8443")
+	}))
+	defer consul.Close()
+
+	p.ConsulConfig.Agent = consul.URL
+	require.NoError(t, p.Start(&acc))
+	p.Stop()
+
+	urls, err = p.GetAllURLs()
+	require.NoError(t, err)
+	require.Len(t, urls, 2)
+	for _, u := range urls {
+		switch u.Tags["consul_node"] {
+		case "node-1":
+			assert.Equal(t, "https://node-1.example.org:8443/web/metrics", u.Url)
+		case "node-2":
+			assert.Equal(t, "https://node-2.example.org:9100/web/metrics", u.Url)
+		default:
+			t.Errorf("unexpected target %v", u)
+		}
+	}
+}
+
+func TestConsulDiscoveryInvalidTemplate(t *testing.T) {
+	p := &Prometheus{
+		ConsulConfig: ConsulConfig{
+			Enabled: true,
+			Queries: []*ConsulQuery{
+				{ServiceName: "web", ServiceURL: "http://{{.Address"},
+			},
+		},
+	}
+
+	var acc testutil.Accumulator
+	require.Error(t, p.Start(&acc))
+}
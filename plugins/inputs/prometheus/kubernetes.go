@@ -0,0 +1,343 @@
+package prometheus
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"log"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v2"
+)
+
+const (
+	serviceAccountDir     = "/var/run/secrets/kubernetes.io/serviceaccount"
+	podWatchRetryInterval = 5 * time.Second
+)
+
+// kubernetesClient is a minimal client of the Kubernetes API server.
+type kubernetesClient struct {
+	server    string
+	token     string
+	tokenFile string
+	client    *http.Client
+}
+
+// kubeConfig is the subset of a kubeconfig file needed to reach the API
+// server of the current context.
+type kubeConfig struct {
+	CurrentContext string `yaml:"current-context"`
+	Clusters       []struct {
+		Name    string `yaml:"name"`
+		Cluster struct {
+			Server                   string `yaml:"server"`
+			CertificateAuthority     string `yaml:"certificate-authority"`
+			CertificateAuthorityData string `yaml:"certificate-authority-data"`
+			InsecureSkipTLSVerify    bool   `yaml:"insecure-skip-tls-verify"`
+		} `yaml:"cluster"`
+	} `yaml:"clusters"`
+	Contexts []struct {
+		Name    string `yaml:"name"`
+		Context struct {
+			Cluster string `yaml:"cluster"`
+			User    string `yaml:"user"`
+		} `yaml:"context"`
+	} `yaml:"contexts"`
+	Users []struct {
+		Name string `yaml:"name"`
+		User struct {
+			Token                 string `yaml:"token"`
+			ClientCertificate     string `yaml:"client-certificate"`
+			ClientCertificateData string `yaml:"client-certificate-data"`
+			ClientKey             string `yaml:"client-key"`
+			ClientKeyData         string `yaml:"client-key-data"`
+		} `yaml:"user"`
+	} `yaml:"users"`
+}
+
+// newKubernetesClient creates a client from the given kubeconfig file, or
+// from the pod service account when running inside the cluster.
+func newKubernetesClient(kubeconfig string) (*kubernetesClient, error) {
+	if kubeconfig != "" {
+		return newKubernetesClientFromConfig(kubeconfig)
+	}
+	return newKubernetesClientInCluster()
+}
+
+func newKubernetesClientInCluster() (*kubernetesClient, error) {
+	host, port := os.Getenv("KUBERNETES_SERVICE_HOST"), os.Getenv("KUBERNETES_SERVICE_PORT")
+	if host == "" || port == "" {
+		return nil, fmt.Errorf("not running in a Kubernetes cluster, set kube_config")
+	}
+
+	caCert, err := ioutil.ReadFile(serviceAccountDir + "/ca.crt")
+	if err != nil {
+		return nil, fmt.Errorf("could not load service account CA: %s", err)
+	}
+	roots := x509.NewCertPool()
+	roots.AppendCertsFromPEM(caCert)
+
+	return &kubernetesClient{
+		server:    "https://" + net.JoinHostPort(host, port),
+		tokenFile: serviceAccountDir + "/token",
+		client:    newWatchClient(&tls.Config{RootCAs: roots}),
+	}, nil
+}
+
+func newKubernetesClientFromConfig(path string) (*kubernetesClient, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var config kubeConfig
+	if err := yaml.Unmarshal(data, &config); err != nil {
+		return nil, fmt.Errorf("could not parse kubeconfig %s: %s", path, err)
+	}
+
+	var clusterName, userName string
+	for _, c := range config.Contexts {
+		if c.Name == config.CurrentContext {
+			clusterName, userName = c.Context.Cluster, c.Context.User
+		}
+	}
+	if clusterName == "" {
+		return nil, fmt.Errorf("current context %q not found in kubeconfig %s",
+			config.CurrentContext, path)
+	}
+
+	client := &kubernetesClient{}
+	tlsConfig := &tls.Config{}
+	for _, c := range config.Clusters {
+		if c.Name != clusterName {
+			continue
+		}
+		client.server = strings.TrimSuffix(c.Cluster.Server, "/")
+		tlsConfig.InsecureSkipVerify = c.Cluster.InsecureSkipTLSVerify
+		caCert, err := readKubeConfigData(c.Cluster.CertificateAuthority, c.Cluster.CertificateAuthorityData)
+		if err != nil {
+			return nil, err
+		}
+		if caCert != nil {
+			roots := x509.NewCertPool()
+			roots.AppendCertsFromPEM(caCert)
+			tlsConfig.RootCAs = roots
+		}
+	}
+	if client.server == "" {
+		return nil, fmt.Errorf("cluster %q not found in kubeconfig %s", clusterName, path)
+	}
+
+	for _, u := range config.Users {
+		if u.Name != userName {
+			continue
+		}
+		client.token = u.User.Token
+		cert, err := readKubeConfigData(u.User.ClientCertificate, u.User.ClientCertificateData)
+		if err != nil {
+			return nil, err
+		}
+		key, err := readKubeConfigData(u.User.ClientKey, u.User.ClientKeyData)
+		if err != nil {
+			return nil, err
+		}
+		if cert != nil && key != nil {
+			keyPair, err := tls.X509KeyPair(cert, key)
+			if err != nil {
+				return nil, fmt.Errorf("could not load client certificate: %s", err)
+			}
+			tlsConfig.Certificates = []tls.Certificate{keyPair}
+		}
+	}
+
+	client.client = newWatchClient(tlsConfig)
+	return client, nil
+}
+
+// readKubeConfigData returns the contents of file, or the decoded base64
+// data when file is not set.
+func readKubeConfigData(file, data string) ([]byte, error) {
+	if file != "" {
+		return ioutil.ReadFile(file)
+	}
+	if data != "" {
+		return base64.StdEncoding.DecodeString(data)
+	}
+	return nil, nil
+}
+
+// newWatchClient returns an HTTP client without an overall timeout, as
+// watch requests stay open indefinitely.
+func newWatchClient(tlsConfig *tls.Config) *http.Client {
+	return &http.Client{
+		Transport: &http.Transport{
+			TLSClientConfig:       tlsConfig,
+			ResponseHeaderTimeout: 10 * time.Second,
+		},
+	}
+}
+
+func (c *kubernetesClient) get(ctx context.Context, path string) (*http.Response, error) {
+	req, err := http.NewRequest("GET", c.server+path, nil)
+	if err != nil {
+		return nil, err
+	}
+	req = req.WithContext(ctx)
+
+	token := c.token
+	if c.tokenFile != "" {
+		// service account tokens are rotated, so read it on every request
+		data, err := ioutil.ReadFile(c.tokenFile)
+		if err != nil {
+			return nil, err
+		}
+		token = strings.TrimSpace(string(data))
+	}
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		resp.Body.Close()
+		return nil, fmt.Errorf("%s returned HTTP status %s", c.server+path, resp.Status)
+	}
+	return resp, nil
+}
+
+type pod struct {
+	Metadata struct {
+		Name        string            `json:"name"`
+		Namespace   string            `json:"namespace"`
+		Annotations map[string]string `json:"annotations"`
+	} `json:"metadata"`
+	Status struct {
+		PodIP string `json:"podIP"`
+	} `json:"status"`
+}
+
+type podEvent struct {
+	Type   string `json:"type"`
+	Object pod    `json:"object"`
+}
+
+// watchPods keeps the scrape targets in sync with the annotated pods until
+// the context is cancelled, reconnecting when the watch is interrupted.
+func (p *Prometheus) watchPods(ctx context.Context, client *kubernetesClient) {
+	for {
+		err := p.watchPodsOnce(ctx, client)
+		if err != nil && ctx.Err() == nil {
+			log.Printf("E! [inputs.prometheus] Error watching Kubernetes pods: %s", err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(podWatchRetryInterval):
+		}
+	}
+}
+
+func (p *Prometheus) watchPodsOnce(ctx context.Context, client *kubernetesClient) error {
+	resp, err := client.get(ctx, "/api/v1/pods?watch=true")
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	// A new watch starts by adding all existing pods, so forget pods which
+	// may have been deleted while disconnected.
+	p.lock.Lock()
+	p.kubernetesPods = make(map[string]UrlAndAddress)
+	p.lock.Unlock()
+
+	decoder := json.NewDecoder(resp.Body)
+	for {
+		var event podEvent
+		if err := decoder.Decode(&event); err != nil {
+			if err == io.EOF {
+				return nil
+			}
+			return err
+		}
+		p.handlePodEvent(event)
+	}
+}
+
+func (p *Prometheus) handlePodEvent(event podEvent) {
+	key := event.Object.Metadata.Namespace + "/" + event.Object.Metadata.Name
+
+	p.lock.Lock()
+	defer p.lock.Unlock()
+	if p.kubernetesPods == nil {
+		p.kubernetesPods = make(map[string]UrlAndAddress)
+	}
+	switch event.Type {
+	case "ADDED", "MODIFIED":
+		scrapeURL := podScrapeURL(&event.Object)
+		if scrapeURL == nil {
+			delete(p.kubernetesPods, key)
+			return
+		}
+		if _, ok := p.kubernetesPods[key]; !ok {
+			log.Printf("D! [inputs.prometheus] Adding scrape target %s for pod %s", scrapeURL, key)
+		}
+		p.kubernetesPods[key] = UrlAndAddress{
+			OriginalUrl: scrapeURL.String(),
+			Url:         scrapeURL.String(),
+			Address:     event.Object.Status.PodIP,
+			Tags: map[string]string{
+				"pod_name":  event.Object.Metadata.Name,
+				"namespace": event.Object.Metadata.Namespace,
+			},
+		}
+	case "DELETED":
+		if _, ok := p.kubernetesPods[key]; ok {
+			log.Printf("D! [inputs.prometheus] Removing scrape target for pod %s", key)
+		}
+		delete(p.kubernetesPods, key)
+	}
+}
+
+// podScrapeURL returns the URL to scrape for a pod annotated with
+// prometheus.io/scrape=true, or nil if the pod should not be scraped.
+func podScrapeURL(pod *pod) *url.URL {
+	annotations := pod.Metadata.Annotations
+	if annotations["prometheus.io/scrape"] != "true" || pod.Status.PodIP == "" {
+		return nil
+	}
+
+	scheme := annotations["prometheus.io/scheme"]
+	if scheme == "" {
+		scheme = "http"
+	}
+	port := annotations["prometheus.io/port"]
+	if port == "" {
+		port = "9102"
+	}
+	path := annotations["prometheus.io/path"]
+	if path == "" {
+		path = "/metrics"
+	}
+	if !strings.HasPrefix(path, "/") {
+		path = "/" + path
+	}
+
+	return &url.URL{
+		Scheme: scheme,
+		Host:   net.JoinHostPort(pod.Status.PodIP, port),
+		Path:   path,
+	}
+}
@@ -0,0 +1,166 @@
+package prometheus
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/influxdata/telegraf/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func podEventJSON(eventType, name, ip, port string, scrape bool) string {
+	return fmt.Sprintf(`{"type":%q,"object":{"metadata":{"name":%q,"namespace":"default",`+
+		`"annotations":{"prometheus.io/scrape":"%t","prometheus.io/port":%q}},`+
+		`"status":{"phase":"Running","podIP":%q}}}`+"\n",
+		eventType, name, scrape, port, ip)
+}
+
+func TestPodScrapeURL(t *testing.T) {
+	p := &pod{}
+	p.Status.PodIP = "10.0.0.1"
+	assert.Nil(t, podScrapeURL(p))
+
+	p.Metadata.Annotations = map[string]string{"prometheus.io/scrape": "true"}
+	assert.Equal(t, "http://10.0.0.1:9102/metrics", podScrapeURL(p).String())
+
+	p.Metadata.Annotations["prometheus.io/port"] = "8080"
+	p.Metadata.Annotations["prometheus.io/path"] = "custom/metrics"
+	p.Metadata.Annotations["prometheus.io/scheme"] = "https"
+	assert.Equal(t, "https://10.0.0.1:8080/custom/metrics", podScrapeURL(p).String())
+
+	// pods without an IP address are not scheduled yet
+	p.Status.PodIP = ""
+	assert.Nil(t, podScrapeURL(p))
+}
+
+func TestWatchPods(t *testing.T) {
+	metrics := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, sampleTextFormat)
+	}))
+	defer metrics.Close()
+	metricsURL, err := url.Parse(metrics.URL)
+	require.NoError(t, err)
+	host, port, err := net.SplitHostPort(metricsURL.Host)
+	require.NoError(t, err)
+
+	deletePod := make(chan struct{})
+	api := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/v1/pods" || r.URL.Query().Get("watch") != "true" ||
+			r.Header.Get("Authorization") != "Bearer test-token" {
+			w.WriteHeader(http.StatusForbidden)
+			return
+		}
+		fmt.Fprint(w, podEventJSON("ADDED", "exporter", host, port, true))
+		fmt.Fprint(w, podEventJSON("ADDED", "database", "10.0.0.2", "9102", false))
+		w.(http.Flusher).Flush()
+
+		select {
+		case <-deletePod:
+		case <-r.Context().Done():
+			return
+		}
+		fmt.Fprint(w, podEventJSON("DELETED", "exporter", host, port, true))
+		w.(http.Flusher).Flush()
+		<-r.Context().Done()
+	}))
+	defer api.Close()
+
+	p := &Prometheus{}
+	client := &kubernetesClient{
+		server: api.URL,
+		token:  "test-token",
+		client: newWatchClient(nil),
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		p.watchPods(ctx, client)
+		close(done)
+	}()
+	defer func() {
+		cancel()
+		<-done
+	}()
+
+	waitForTargets := func(n int) []UrlAndAddress {
+		var urls []UrlAndAddress
+		for i := 0; i < 100; i++ {
+			urls, err = p.GetAllURLs()
+			require.NoError(t, err)
+			if len(urls) == n {
+				break
+			}
+			time.Sleep(10 * time.Millisecond)
+		}
+		return urls
+	}
+
+	urls := waitForTargets(1)
+	require.Len(t, urls, 1)
+	assert.Equal(t, metrics.URL+"/metrics", urls[0].Url)
+
+	var acc testutil.Accumulator
+	require.NoError(t, acc.GatherError(p.Gather))
+	assert.True(t, acc.HasFloatField("go_goroutines", "gauge"))
+	assert.Equal(t, "exporter", acc.TagValue("go_goroutines", "pod_name"))
+	assert.Equal(t, "default", acc.TagValue("go_goroutines", "namespace"))
+
+	close(deletePod)
+	assert.Len(t, waitForTargets(0), 0)
+}
+
+func TestNewKubernetesClientFromConfig(t *testing.T) {
+	kubeconfig := `
+apiVersion: v1
+kind: Config
+current-context: test
+clusters:
+- name: other
+  cluster:
+    server: https://other.example.org
+- name: test-cluster
+  cluster:
+    server: https://kubernetes.example.org:6443/
+    insecure-skip-tls-verify: true
+contexts:
+- name: test
+  context:
+    cluster: test-cluster
+    user: test-user
+users:
+- name: test-user
+  user:
+    token: test-token
+`
+	f, err := ioutil.TempFile("", "kubeconfig")
+	require.NoError(t, err)
+	defer os.Remove(f.Name())
+	_, err = f.WriteString(kubeconfig)
+	require.NoError(t, err)
+	require.NoError(t, f.Close())
+
+	client, err := newKubernetesClient(f.Name())
+	require.NoError(t, err)
+	assert.Equal(t, "https://kubernetes.example.org:6443", client.server)
+	assert.Equal(t, "test-token", client.token)
+	tr := client.client.Transport.(*http.Transport)
+	assert.True(t, tr.TLSClientConfig.InsecureSkipVerify)
+}
+
+func TestNewKubernetesClientNotInCluster(t *testing.T) {
+	if os.Getenv("KUBERNETES_SERVICE_HOST") != "" {
+		t.Skip("running in a Kubernetes cluster")
+	}
+	_, err := newKubernetesClient("")
+	assert.Error(t, err)
+}
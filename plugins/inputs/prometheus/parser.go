@@ -24,6 +24,16 @@ import (
 // Parse returns a slice of Metrics from a text representation of a
 // metrics
 func Parse(buf []byte, header http.Header) ([]telegraf.Metric, error) {
+	return parse(buf, header, false)
+}
+
+// ParseExpanded is like Parse, but returns one metric per histogram bucket
+// and summary quantile, tagged with le or quantile.
+func ParseExpanded(buf []byte, header http.Header) ([]telegraf.Metric, error) {
+	return parse(buf, header, true)
+}
+
+func parse(buf []byte, header http.Header, expand bool) ([]telegraf.Metric, error) {
 	var metrics []telegraf.Metric
 	var parser expfmt.TextParser
 	// parse even if the buffer begins with a newline
@@ -59,6 +69,11 @@ func Parse(buf []byte, header http.Header) ([]telegraf.Metric, error) {
 	// read metrics
 	for metricName, mf := range metricFamilies {
 		for _, m := range mf.Metric {
+			if expand && (mf.GetType() == dto.MetricType_SUMMARY || mf.GetType() == dto.MetricType_HISTOGRAM) {
+				metrics = append(metrics, makeExpandedMetrics(metricName, mf.GetType(), m)...)
+				continue
+			}
+
 			// reading tags
 			tags := makeLabels(m)
 			// reading fields
@@ -132,6 +147,53 @@ func makeBuckets(m *dto.Metric) map[string]interface{} {
 	return fields
 }
 
+// makeExpandedMetrics returns a metric for every bucket or quantile of a
+// histogram or summary, and one with its sum and count.  The field names
+// match the series names of the Prometheus exposition format when written
+// by the prometheus_client output.
+func makeExpandedMetrics(metricName string, mt dto.MetricType, m *dto.Metric) []telegraf.Metric {
+	var metrics []telegraf.Metric
+
+	var t time.Time
+	if m.TimestampMs != nil && *m.TimestampMs > 0 {
+		t = time.Unix(0, *m.TimestampMs*1000000)
+	} else {
+		t = time.Now()
+	}
+
+	add := func(tags map[string]string, fields map[string]interface{}) {
+		metric, err := metric.New(metricName, tags, fields, t, telegraf.Untyped)
+		if err == nil {
+			metrics = append(metrics, metric)
+		}
+	}
+
+	var count, sum float64
+	if mt == dto.MetricType_HISTOGRAM {
+		for _, b := range m.GetHistogram().Bucket {
+			tags := makeLabels(m)
+			tags["le"] = fmt.Sprint(b.GetUpperBound())
+			add(tags, map[string]interface{}{"bucket": float64(b.GetCumulativeCount())})
+		}
+		count = float64(m.GetHistogram().GetSampleCount())
+		sum = m.GetHistogram().GetSampleSum()
+	} else {
+		for _, q := range m.GetSummary().Quantile {
+			if math.IsNaN(q.GetValue()) {
+				continue
+			}
+			tags := makeLabels(m)
+			tags["quantile"] = fmt.Sprint(q.GetQuantile())
+			add(tags, map[string]interface{}{"value": q.GetValue()})
+		}
+		count = float64(m.GetSummary().GetSampleCount())
+		sum = m.GetSummary().GetSampleSum()
+	}
+	add(makeLabels(m), map[string]interface{}{"count": count, "sum": sum})
+
+	return metrics
+}
+
 // Get labels from metric
 func makeLabels(m *dto.Metric) map[string]string {
 	result := map[string]string{}
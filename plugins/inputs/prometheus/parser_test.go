@@ -163,3 +163,59 @@ func TestParseValidPrometheus(t *testing.T) {
 		metrics[0].Tags())
 
 }
+
+func TestParseExpandedHistogram(t *testing.T) {
+	metrics, err := ParseExpanded([]byte(validUniqueHistogram), http.Header{})
+	assert.NoError(t, err)
+	assert.Len(t, metrics, 9)
+
+	buckets := make(map[string]interface{})
+	for _, m := range metrics {
+		assert.Equal(t, "apiserver_request_latencies", m.Name())
+		assert.Equal(t, "bindings", m.Tags()["resource"])
+		assert.Equal(t, "POST", m.Tags()["verb"])
+		if le, ok := m.Tags()["le"]; ok {
+			buckets[le] = m.Fields()["bucket"]
+			continue
+		}
+		assert.Equal(t, map[string]interface{}{
+			"count": 2025.0,
+			"sum":   1.02726334e+08,
+		}, m.Fields())
+	}
+	assert.Equal(t, map[string]interface{}{
+		"125000": 1994.0,
+		"250000": 1997.0,
+		"500000": 2000.0,
+		"1e+06":  2005.0,
+		"2e+06":  2012.0,
+		"4e+06":  2017.0,
+		"8e+06":  2024.0,
+		"+Inf":   2025.0,
+	}, buckets)
+}
+
+func TestParseExpandedSummary(t *testing.T) {
+	metrics, err := ParseExpanded([]byte(validUniqueSummary), http.Header{})
+	assert.NoError(t, err)
+	assert.Len(t, metrics, 4)
+
+	quantiles := make(map[string]interface{})
+	for _, m := range metrics {
+		assert.Equal(t, "http_request_duration_microseconds", m.Name())
+		assert.Equal(t, "prometheus", m.Tags()["handler"])
+		if q, ok := m.Tags()["quantile"]; ok {
+			quantiles[q] = m.Fields()["value"]
+			continue
+		}
+		assert.Equal(t, map[string]interface{}{
+			"count": 9.0,
+			"sum":   1.8909097205e+07,
+		}, m.Fields())
+	}
+	assert.Equal(t, map[string]interface{}{
+		"0.5":  552048.506,
+		"0.9":  5.876804288e+06,
+		"0.99": 5.876804288e+06,
+	}, quantiles)
+}
@@ -1,6 +1,7 @@
 package prometheus
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"io/ioutil"
@@ -8,6 +9,7 @@ import (
 	"net"
 	"net/http"
 	"net/url"
+	"strings"
 	"sync"
 	"time"
 
@@ -25,6 +27,17 @@ type Prometheus struct {
 	// An array of Kubernetes services to scrape metrics from.
 	KubernetesServices []string
 
+	// Scrape Kubernetes pods with the prometheus.io/scrape annotation
+	MonitorPods bool `toml:"monitor_kubernetes_pods"`
+	// Path to a kubeconfig file, the service account is used when empty
+	KubeConfig string `toml:"kube_config"`
+
+	// Scrape the healthy instances of services registered in Consul
+	ConsulConfig ConsulConfig `toml:"consul"`
+
+	// Emit histogram buckets and summary quantiles as separate metrics
+	ExpandHistograms bool `toml:"expand_histograms"`
+
 	// Bearer Token authorization file path
 	BearerToken string `toml:"bearer_token"`
 
@@ -40,6 +53,12 @@ type Prometheus struct {
 	InsecureSkipVerify bool
 
 	client *http.Client
+
+	lock           sync.Mutex
+	kubernetesPods map[string]UrlAndAddress
+	consulServices map[string]UrlAndAddress
+	cancel         context.CancelFunc
+	wg             sync.WaitGroup
 }
 
 var sampleConfig = `
@@ -49,16 +68,43 @@ var sampleConfig = `
   ## An array of Kubernetes services to scrape metrics from.
   # kubernetes_services = ["http://my-service-dns.my-namespace:9100/metrics"]
 
+  ## Scrape Kubernetes pods annotated with prometheus.io/scrape=true, using
+  ## the prometheus.io/port and prometheus.io/path annotations.
+  # monitor_kubernetes_pods = false
+  ## Path to a kubeconfig file; if empty the in-cluster service account is
+  ## used.
+  # kube_config = "/path/to/.kube/config"
+
+  ## Scrape the healthy instances of services registered in Consul.
+  # [inputs.prometheus.consul]
+  #   enabled = true
+  #   ## Address of the Consul agent
+  #   agent = "http://localhost:8500"
+  #   ## How often the catalog is queried for new or removed instances
+  #   query_interval = "5m"
+  #
+  #   ## Each query selects the healthy instances of a service, optionally
+  #   ## filtered by a service tag. The url is a template executed with the
+  #   ## Consul catalog service of each instance.
+  #   [[inputs.prometheus.consul.query]]
+  #     name = "my-service"
+  #     tag = "prometheus"
+  #     url = 'http://{{if .ServiceAddress}}{{.ServiceAddress}}{{else}}{{.Address}}{{end}}:{{.ServicePort}}/metrics'
+
+  ## Emit a metric per histogram bucket and summary quantile, tagged with le
+  ## or quantile, instead of one metric with a field per bucket or quantile.
+  # expand_histograms = false
+
   ## Use bearer token for authorization
-  # bearer_token = /path/to/bearer/token
+  # bearer_token = "/path/to/bearer/token"
 
   ## Specify timeout duration for slower prometheus clients (default is 3s)
   # response_timeout = "3s"
 
   ## Optional SSL Config
-  # ssl_ca = /path/to/cafile
-  # ssl_cert = /path/to/certfile
-  # ssl_key = /path/to/keyfile
+  # ssl_ca = "/path/to/cafile"
+  # ssl_cert = "/path/to/certfile"
+  # ssl_key = "/path/to/keyfile"
   ## Use SSL but skip chain & host verification
   # insecure_skip_verify = false
 `
@@ -96,6 +142,7 @@ type UrlAndAddress struct {
 	OriginalUrl string
 	Url         string
 	Address     string
+	Tags        map[string]string
 }
 
 func (p *Prometheus) GetAllURLs() ([]UrlAndAddress, error) {
@@ -118,6 +165,15 @@ func (p *Prometheus) GetAllURLs() ([]UrlAndAddress, error) {
 			allUrls = append(allUrls, UrlAndAddress{Url: serviceUrl, Address: resolved, OriginalUrl: service})
 		}
 	}
+
+	p.lock.Lock()
+	defer p.lock.Unlock()
+	for _, pod := range p.kubernetesPods {
+		allUrls = append(allUrls, pod)
+	}
+	for _, service := range p.consulServices {
+		allUrls = append(allUrls, service)
+	}
 	return allUrls, nil
 }
 
@@ -180,6 +236,9 @@ func (p *Prometheus) createHttpClient() (*http.Client, error) {
 
 func (p *Prometheus) gatherURL(url UrlAndAddress, acc telegraf.Accumulator) error {
 	var req, err = http.NewRequest("GET", url.Url, nil)
+	if err != nil {
+		return err
+	}
 	req.Header.Add("Accept", acceptHeader)
 	var token []byte
 	var resp *http.Response
@@ -189,7 +248,7 @@ func (p *Prometheus) gatherURL(url UrlAndAddress, acc telegraf.Accumulator) erro
 		if err != nil {
 			return err
 		}
-		req.Header.Set("Authorization", "Bearer "+string(token))
+		req.Header.Set("Authorization", "Bearer "+strings.TrimSpace(string(token)))
 	}
 
 	resp, err = p.client.Do(req)
@@ -206,7 +265,12 @@ func (p *Prometheus) gatherURL(url UrlAndAddress, acc telegraf.Accumulator) erro
 		return fmt.Errorf("error reading body: %s", err)
 	}
 
-	metrics, err := Parse(body, resp.Header)
+	var metrics []telegraf.Metric
+	if p.ExpandHistograms {
+		metrics, err = ParseExpanded(body, resp.Header)
+	} else {
+		metrics, err = Parse(body, resp.Header)
+	}
 	if err != nil {
 		return fmt.Errorf("error reading metrics for %s: %s",
 			url.Url, err)
@@ -218,6 +282,9 @@ func (p *Prometheus) gatherURL(url UrlAndAddress, acc telegraf.Accumulator) erro
 		if url.Address != "" {
 			tags["address"] = url.Address
 		}
+		for k, v := range url.Tags {
+			tags[k] = v
+		}
 
 		switch metric.Type() {
 		case telegraf.Counter:
@@ -236,6 +303,44 @@ func (p *Prometheus) gatherURL(url UrlAndAddress, acc telegraf.Accumulator) erro
 	return nil
 }
 
+// Start watches the Kubernetes pods when monitor_kubernetes_pods is set and
+// queries Consul for services when consul discovery is enabled.
+func (p *Prometheus) Start(a telegraf.Accumulator) error {
+	var ctx context.Context
+	ctx, p.cancel = context.WithCancel(context.Background())
+
+	if p.MonitorPods {
+		client, err := newKubernetesClient(p.KubeConfig)
+		if err != nil {
+			p.cancel()
+			return fmt.Errorf("error creating Kubernetes client: %s", err)
+		}
+
+		p.wg.Add(1)
+		go func() {
+			defer p.wg.Done()
+			p.watchPods(ctx, client)
+		}()
+	}
+
+	if p.ConsulConfig.Enabled {
+		if err := p.startConsul(ctx); err != nil {
+			p.cancel()
+			p.wg.Wait()
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (p *Prometheus) Stop() {
+	if p.cancel != nil {
+		p.cancel()
+	}
+	p.wg.Wait()
+}
+
 func init() {
 	inputs.Add("prometheus", func() telegraf.Input {
 		return &Prometheus{ResponseTimeout: internal.Duration{Duration: time.Second * 3}}
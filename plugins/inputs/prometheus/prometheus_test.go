@@ -2,9 +2,11 @@ package prometheus
 
 import (
 	"fmt"
+	"io/ioutil"
 	"net/http"
 	"net/http/httptest"
 	"net/url"
+	"os"
 	"testing"
 	"time"
 
@@ -103,3 +105,40 @@ func TestPrometheusGeneratesMetricsAlthoughFirstDNSFails(t *testing.T) {
 	assert.True(t, acc.HasFloatField("test_metric", "value"))
 	assert.True(t, acc.HasTimestamp("test_metric", time.Unix(1490802350, 0)))
 }
+
+func TestPrometheusBearerTokenTLS(t *testing.T) {
+	ts := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer test-token" {
+			w.WriteHeader(http.StatusForbidden)
+			return
+		}
+		fmt.Fprint(w, sampleTextFormat)
+	}))
+	defer ts.Close()
+
+	tokenFile, err := ioutil.TempFile("", "token")
+	require.NoError(t, err)
+	defer os.Remove(tokenFile.Name())
+	// tokens are commonly written with a trailing newline
+	_, err = tokenFile.WriteString("test-token\n")
+	require.NoError(t, err)
+	require.NoError(t, tokenFile.Close())
+
+	p := &Prometheus{
+		Urls:               []string{ts.URL},
+		BearerToken:        tokenFile.Name(),
+		InsecureSkipVerify: true,
+	}
+
+	var acc testutil.Accumulator
+	require.NoError(t, acc.GatherError(p.Gather))
+	assert.True(t, acc.HasFloatField("go_goroutines", "gauge"))
+
+	// the token is read again on every gather
+	require.NoError(t, ioutil.WriteFile(tokenFile.Name(), []byte("rotated-token"), 0600))
+
+	var accRotated testutil.Accumulator
+	err = accRotated.GatherError(p.Gather)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "403")
+}
@@ -9,6 +9,7 @@ import (
 	"time"
 
 	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/filter"
 	"github.com/influxdata/telegraf/internal"
 	"github.com/influxdata/telegraf/plugins/inputs"
 )
@@ -51,7 +52,15 @@ type RabbitMQ struct {
 	Nodes  []string
 	Queues []string
 
+	QueueNameInclude []string
+	QueueNameExclude []string
+
+	GatherExchanges bool
+	Exchanges       []string
+
 	Client *http.Client
+
+	queueFilter filter.Filter
 }
 
 // OverviewResponse ...
@@ -78,6 +87,10 @@ type MessageStats struct {
 	PublishDetails    Details `json:"publish_details"`
 	Redeliver         int64
 	RedeliverDetails  Details `json:"redeliver_details"`
+	PublishIn         int64   `json:"publish_in"`
+	PublishInDetails  Details `json:"publish_in_details"`
+	PublishOut        int64   `json:"publish_out"`
+	PublishOutDetails Details `json:"publish_out_details"`
 }
 
 // ObjectTotals ...
@@ -116,6 +129,17 @@ type Queue struct {
 	IdleSince           string `json:"idle_since"`
 }
 
+// Exchange ...
+type Exchange struct {
+	Name         string
+	MessageStats `json:"message_stats"`
+	Type         string
+	Internal     bool
+	Vhost        string
+	Durable      bool
+	AutoDelete   bool `json:"auto_delete"`
+}
+
 // Node ...
 type Node struct {
 	Name string
@@ -136,7 +160,7 @@ type Node struct {
 // gatherFunc ...
 type gatherFunc func(r *RabbitMQ, acc telegraf.Accumulator)
 
-var gatherFunctions = []gatherFunc{gatherOverview, gatherNodes, gatherQueues}
+var gatherFunctions = []gatherFunc{gatherOverview, gatherNodes, gatherQueues, gatherExchanges}
 
 var sampleConfig = `
   ## Management Plugin url. (default: http://localhost:15672)
@@ -171,6 +195,20 @@ var sampleConfig = `
   ## A list of queues to gather as the rabbitmq_queue measurement. If not
   ## specified, metrics for all queues are gathered.
   # queues = ["telegraf"]
+
+  ## Queues to include and exclude. Globs accepted.
+  ## Note that an empty array for both will include all queues
+  # queue_name_include = []
+  # queue_name_exclude = []
+
+  ## Gather the rabbitmq_exchange measurement from /api/exchanges.
+  # gather_exchanges = false
+
+  ## A list of exchanges to gather as the rabbitmq_exchange measurement. If
+  ## not specified, metrics for all exchanges except the default exchange
+  ## are gathered. Add "(AMQP default)" or "" to the list to gather the
+  ## default exchange, it is tagged as "(AMQP default)".
+  # exchanges = ["telegraf"]
 `
 
 // SampleConfig ...
@@ -201,6 +239,15 @@ func (r *RabbitMQ) Gather(acc telegraf.Accumulator) error {
 		}
 	}
 
+	if r.queueFilter == nil {
+		queueFilter, err := filter.NewIncludeExcludeFilter(
+			r.QueueNameInclude, r.QueueNameExclude)
+		if err != nil {
+			return err
+		}
+		r.queueFilter = queueFilter
+	}
+
 	var wg sync.WaitGroup
 	wg.Add(len(gatherFunctions))
 	for _, f := range gatherFunctions {
@@ -373,6 +420,54 @@ func gatherQueues(r *RabbitMQ, acc telegraf.Accumulator) {
 	}
 }
 
+// defaultExchange is the exchange tag of the default exchange, which has no
+// name
+const defaultExchange = "(AMQP default)"
+
+func gatherExchanges(r *RabbitMQ, acc telegraf.Accumulator) {
+	if !r.GatherExchanges {
+		return
+	}
+
+	// Gather information about exchanges
+	exchanges := make([]Exchange, 0)
+	err := r.requestJSON("/api/exchanges", &exchanges)
+	if err != nil {
+		acc.AddError(err)
+		return
+	}
+
+	for _, exchange := range exchanges {
+		if !r.shouldGatherExchange(exchange) {
+			continue
+		}
+		name := exchange.Name
+		if name == "" {
+			name = defaultExchange
+		}
+		tags := map[string]string{
+			"url":         r.URL,
+			"exchange":    name,
+			"type":        exchange.Type,
+			"vhost":       exchange.Vhost,
+			"internal":    strconv.FormatBool(exchange.Internal),
+			"durable":     strconv.FormatBool(exchange.Durable),
+			"auto_delete": strconv.FormatBool(exchange.AutoDelete),
+		}
+
+		acc.AddFields(
+			"rabbitmq_exchange",
+			map[string]interface{}{
+				"messages_publish_in":       exchange.MessageStats.PublishIn,
+				"messages_publish_in_rate":  exchange.MessageStats.PublishInDetails.Rate,
+				"messages_publish_out":      exchange.MessageStats.PublishOut,
+				"messages_publish_out_rate": exchange.MessageStats.PublishOutDetails.Rate,
+			},
+			tags,
+		)
+	}
+}
+
 func (r *RabbitMQ) shouldGatherNode(node Node) bool {
 	if len(r.Nodes) == 0 {
 		return true
@@ -388,6 +483,10 @@ func (r *RabbitMQ) shouldGatherNode(node Node) bool {
 }
 
 func (r *RabbitMQ) shouldGatherQueue(queue Queue) bool {
+	if r.queueFilter != nil && !r.queueFilter.Match(queue.Name) {
+		return false
+	}
+
 	if len(r.Queues) == 0 {
 		return true
 	}
@@ -401,6 +500,21 @@ func (r *RabbitMQ) shouldGatherQueue(queue Queue) bool {
 	return false
 }
 
+func (r *RabbitMQ) shouldGatherExchange(exchange Exchange) bool {
+	if len(r.Exchanges) == 0 {
+		// The default exchange has no name and is only gathered on request
+		return exchange.Name != ""
+	}
+
+	for _, name := range r.Exchanges {
+		if name == exchange.Name || (name == defaultExchange && exchange.Name == "") {
+			return true
+		}
+	}
+
+	return false
+}
+
 func init() {
 	inputs.Add("rabbitmq", func() telegraf.Input {
 		return &RabbitMQ{
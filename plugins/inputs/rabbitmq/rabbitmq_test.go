@@ -374,8 +374,63 @@ const sampleQueuesResponse = `
 ]
 `
 
-func TestRabbitMQGeneratesMetrics(t *testing.T) {
-	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+const sampleExchangesResponse = `
+[
+  {
+    "arguments": {},
+    "internal": false,
+    "auto_delete": false,
+    "durable": true,
+    "type": "direct",
+    "vhost": "/",
+    "name": "",
+    "message_stats": {
+      "publish_in": 284725,
+      "publish_in_details": {
+        "rate": 0
+      },
+      "publish_out": 284572,
+      "publish_out_details": {
+        "rate": 0
+      }
+    },
+    "user_who_performed_action": "rmq-internal"
+  },
+  {
+    "arguments": {},
+    "internal": false,
+    "auto_delete": false,
+    "durable": true,
+    "type": "topic",
+    "vhost": "metrics",
+    "name": "telegraf",
+    "message_stats": {
+      "publish_in_details": {
+        "rate": 2.4
+      },
+      "publish_in": 22,
+      "publish_out_details": {
+        "rate": 1.2
+      },
+      "publish_out": 11
+    },
+    "user_who_performed_action": "telegraf"
+  },
+  {
+    "arguments": {},
+    "internal": true,
+    "auto_delete": false,
+    "durable": true,
+    "type": "topic",
+    "vhost": "/",
+    "name": "amq.rabbitmq.log",
+    "user_who_performed_action": "rmq-internal"
+  }
+]
+`
+
+func newTestServer() *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		var rsp string
 
 		switch r.URL.Path {
@@ -385,12 +440,18 @@ func TestRabbitMQGeneratesMetrics(t *testing.T) {
 			rsp = sampleNodesResponse
 		case "/api/queues":
 			rsp = sampleQueuesResponse
+		case "/api/exchanges":
+			rsp = sampleExchangesResponse
 		default:
 			panic("Cannot handle request")
 		}
 
 		fmt.Fprintln(w, rsp)
 	}))
+}
+
+func TestRabbitMQGeneratesMetrics(t *testing.T) {
+	ts := newTestServer()
 	defer ts.Close()
 
 	r := &RabbitMQ{
@@ -442,3 +503,144 @@ func TestRabbitMQGeneratesMetrics(t *testing.T) {
 
 	assert.True(t, acc.HasMeasurement("rabbitmq_queue"))
 }
+
+func TestRabbitMQQueueFilter(t *testing.T) {
+	ts := newTestServer()
+	defer ts.Close()
+
+	r := &RabbitMQ{
+		URL:              ts.URL,
+		QueueNameInclude: []string{"tele*", "collectd-*"},
+		QueueNameExclude: []string{"collectd-*"},
+	}
+
+	var acc testutil.Accumulator
+
+	err := acc.GatherError(r.Gather)
+	require.NoError(t, err)
+
+	vhosts := make(map[string]map[string]interface{})
+	for _, m := range acc.Metrics {
+		if m.Measurement != "rabbitmq_queue" {
+			continue
+		}
+		assert.Equal(t, "telegraf", m.Tags["queue"])
+		assert.Equal(t, "rabbit@testhost", m.Tags["node"])
+		vhosts[m.Tags["vhost"]] = m.Fields
+	}
+	require.Len(t, vhosts, 2)
+
+	fields := vhosts["collectd"]
+	require.NotNil(t, fields)
+	assert.Equal(t, int64(24), fields["messages"])
+	assert.Equal(t, int64(24), fields["messages_ready"])
+	assert.Equal(t, int64(0), fields["messages_unack"])
+	assert.Equal(t, int64(0), fields["consumers"])
+	assert.Equal(t, int64(149220), fields["message_bytes"])
+
+	fields = vhosts["metrics"]
+	require.NotNil(t, fields)
+	assert.Equal(t, int64(5), fields["messages"])
+	assert.Equal(t, int64(0), fields["messages_ready"])
+	assert.Equal(t, int64(5), fields["messages_unack"])
+	assert.Equal(t, int64(1), fields["consumers"])
+	assert.Equal(t, int64(150096), fields["message_bytes"])
+	assert.Equal(t, 0.4, fields["messages_publish_rate"])
+}
+
+func TestRabbitMQQueueFilterInvalid(t *testing.T) {
+	r := &RabbitMQ{
+		QueueNameInclude: []string{"["},
+	}
+
+	var acc testutil.Accumulator
+
+	err := acc.GatherError(r.Gather)
+	require.Error(t, err)
+}
+
+func TestRabbitMQExchanges(t *testing.T) {
+	ts := newTestServer()
+	defer ts.Close()
+
+	r := &RabbitMQ{
+		URL:             ts.URL,
+		GatherExchanges: true,
+	}
+
+	var acc testutil.Accumulator
+
+	err := acc.GatherError(r.Gather)
+	require.NoError(t, err)
+
+	acc.AssertContainsTaggedFields(t, "rabbitmq_exchange",
+		map[string]interface{}{
+			"messages_publish_in":       int64(22),
+			"messages_publish_in_rate":  2.4,
+			"messages_publish_out":      int64(11),
+			"messages_publish_out_rate": 1.2,
+		},
+		map[string]string{
+			"url":         ts.URL,
+			"exchange":    "telegraf",
+			"type":        "topic",
+			"vhost":       "metrics",
+			"internal":    "false",
+			"durable":     "true",
+			"auto_delete": "false",
+		})
+
+	acc.AssertContainsTaggedFields(t, "rabbitmq_exchange",
+		map[string]interface{}{
+			"messages_publish_in":       int64(0),
+			"messages_publish_in_rate":  0.0,
+			"messages_publish_out":      int64(0),
+			"messages_publish_out_rate": 0.0,
+		},
+		map[string]string{
+			"url":         ts.URL,
+			"exchange":    "amq.rabbitmq.log",
+			"type":        "topic",
+			"vhost":       "/",
+			"internal":    "true",
+			"durable":     "true",
+			"auto_delete": "false",
+		})
+
+	for _, m := range acc.Metrics {
+		if m.Measurement == "rabbitmq_exchange" {
+			assert.NotEqual(t, "", m.Tags["exchange"])
+		}
+	}
+}
+
+func TestRabbitMQDefaultExchange(t *testing.T) {
+	ts := newTestServer()
+	defer ts.Close()
+
+	for _, name := range []string{"", "(AMQP default)"} {
+		r := &RabbitMQ{
+			URL:             ts.URL,
+			GatherExchanges: true,
+			Exchanges:       []string{name},
+		}
+
+		var acc testutil.Accumulator
+
+		err := acc.GatherError(r.Gather)
+		require.NoError(t, err)
+
+		exchanges := 0
+		for _, m := range acc.Metrics {
+			if m.Measurement != "rabbitmq_exchange" {
+				continue
+			}
+			exchanges++
+			assert.Equal(t, "(AMQP default)", m.Tags["exchange"])
+			assert.Equal(t, "direct", m.Tags["type"])
+			assert.Equal(t, int64(284725), m.Fields["messages_publish_in"])
+			assert.Equal(t, int64(284572), m.Fields["messages_publish_out"])
+		}
+		assert.Equal(t, 1, exchanges)
+	}
+}
@@ -2,7 +2,13 @@ package redis
 
 import (
 	"bufio"
+	"crypto/tls"
 	"fmt"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strconv"
 	"strings"
 	"testing"
 	"time"
@@ -117,6 +123,196 @@ func TestRedis_ParseMetrics(t *testing.T) {
 	acc.AssertContainsTaggedFields(t, "redis_keyspace", keyspaceFields, keyspaceTags)
 }
 
+func TestRedis_ParseKeyspace(t *testing.T) {
+	var acc testutil.Accumulator
+	tags := map[string]string{"host": "redis.net"}
+	rdr := bufio.NewReader(strings.NewReader(testKeyspaceOutput))
+
+	err := gatherInfoOutput(rdr, &acc, tags)
+	require.NoError(t, err)
+
+	acc.AssertContainsTaggedFields(t, "redis_keyspace",
+		map[string]interface{}{"keys": int64(2), "expires": int64(0), "avg_ttl": int64(0)},
+		map[string]string{"host": "redis.net", "replication_role": "master", "database": "db0"})
+	acc.AssertContainsTaggedFields(t, "redis_keyspace",
+		map[string]interface{}{"keys": int64(1543), "expires": int64(12), "avg_ttl": int64(68915)},
+		map[string]string{"host": "redis.net", "replication_role": "master", "database": "db3"})
+	acc.AssertContainsTaggedFields(t, "redis_keyspace",
+		map[string]interface{}{"keys": int64(7), "avg_ttl": int64(0)},
+		map[string]string{"host": "redis.net", "replication_role": "master", "database": "db15"})
+
+	count := 0
+	for _, m := range acc.Metrics {
+		if m.Measurement == "redis_keyspace" {
+			count++
+		}
+	}
+	assert.Equal(t, 3, count)
+}
+
+func TestRedis_ParseCommandstats(t *testing.T) {
+	var acc testutil.Accumulator
+	tags := map[string]string{"host": "redis.net"}
+	rdr := bufio.NewReader(strings.NewReader(testCommandstatsOutput))
+
+	err := gatherInfoOutput(rdr, &acc, tags)
+	require.NoError(t, err)
+
+	tags = map[string]string{"host": "redis.net", "replication_role": "master"}
+	cmdstats := map[string]map[string]interface{}{
+		"get": {
+			"calls":         int64(5),
+			"usec":          int64(10),
+			"usec_per_call": float64(2.00),
+		},
+		"set": {
+			"calls":         int64(261265),
+			"usec":          int64(1634157),
+			"usec_per_call": float64(6.25),
+		},
+		"config|get": {
+			"calls":         int64(1),
+			"usec":          int64(24),
+			"usec_per_call": float64(24.00),
+		},
+	}
+	for command, fields := range cmdstats {
+		cmdTags := map[string]string{"command": command}
+		for k, v := range tags {
+			cmdTags[k] = v
+		}
+		acc.AssertContainsTaggedFields(t, "redis_cmdstat", fields, cmdTags)
+	}
+
+	// the commandstats lines are not part of the redis measurement
+	fields, ok := acc.Get("redis")
+	require.True(t, ok)
+	assert.NotContains(t, fields.Fields, "cmdstat_get")
+}
+
+const testCommandstatsOutput = `# Replication
+role:master
+connected_slaves:0
+
+# Commandstats
+cmdstat_get:calls=5,usec=10,usec_per_call=2.00
+cmdstat_set:calls=261265,usec=1634157,usec_per_call=6.25
+cmdstat_config|get:calls=1,usec=24,usec_per_call=24.00
+
+(error) ERR unknown command 'eof'
+`
+
+func TestRedis_ParseReplicationMaster(t *testing.T) {
+	var acc testutil.Accumulator
+	tags := map[string]string{"host": "redis.net"}
+	rdr := bufio.NewReader(strings.NewReader(testMasterReplicationOutput))
+
+	require.NoError(t, gatherInfoOutput(rdr, &acc, tags))
+
+	tags = map[string]string{"host": "redis.net", "replication_role": "master"}
+	acc.AssertContainsTaggedFields(t, "redis_replication",
+		map[string]interface{}{"master_repl_offset": int64(1751)}, tags)
+	acc.AssertContainsTaggedFields(t, "redis_replication",
+		map[string]interface{}{"offset": int64(1751), "lag": int64(0)},
+		map[string]string{"host": "redis.net", "replication_role": "master",
+			"slave_addr": "10.0.0.2:6379", "slave_state": "online"})
+	acc.AssertContainsTaggedFields(t, "redis_replication",
+		map[string]interface{}{"offset": int64(1580), "lag": int64(2)},
+		map[string]string{"host": "redis.net", "replication_role": "master",
+			"slave_addr": "10.0.0.3:6380", "slave_state": "wait_bgsave"})
+
+	for _, m := range acc.Metrics {
+		if m.Measurement == "redis_replication" {
+			assert.NotContains(t, m.Fields, "master_link_status")
+		}
+	}
+	assert.False(t, acc.HasMeasurement("redis_cluster"))
+}
+
+func TestRedis_ParseReplicationSlave(t *testing.T) {
+	var acc testutil.Accumulator
+	tags := map[string]string{"host": "redis.net"}
+	rdr := bufio.NewReader(strings.NewReader(testSlaveReplicationOutput))
+
+	require.NoError(t, gatherInfoOutput(rdr, &acc, tags))
+
+	acc.AssertContainsTaggedFields(t, "redis_replication",
+		map[string]interface{}{
+			"master_link_status": int64(0),
+			"master_repl_offset": int64(1751),
+			"slave_repl_offset":  int64(1720),
+		},
+		map[string]string{"host": "redis.net", "replication_role": "slave"})
+}
+
+func TestRedis_ParseClusterInfo(t *testing.T) {
+	var acc testutil.Accumulator
+	tags := map[string]string{"host": "redis.net"}
+	rdr := bufio.NewReader(strings.NewReader(testClusterInfoOutput))
+
+	require.NoError(t, gatherClusterInfo(rdr, &acc, tags))
+
+	acc.AssertContainsTaggedFields(t, "redis_cluster",
+		map[string]interface{}{
+			"cluster_enabled":     int64(1),
+			"cluster_known_nodes": int64(6),
+			"cluster_size":        int64(3),
+		},
+		tags)
+}
+
+const testMasterReplicationOutput = `# Replication
+role:master
+connected_slaves:2
+slave0:ip=10.0.0.2,port=6379,state=online,offset=1751,lag=0
+slave1:ip=10.0.0.3,port=6380,state=wait_bgsave,offset=1580,lag=2
+master_repl_offset:1751
+repl_backlog_active:1
+
+# Cluster
+cluster_enabled:0
+`
+
+const testSlaveReplicationOutput = `# Replication
+role:slave
+master_host:10.0.0.1
+master_port:6379
+master_link_status:down
+master_last_io_seconds_ago:-1
+master_sync_in_progress:0
+slave_repl_offset:1720
+slave_priority:100
+slave_read_only:1
+connected_slaves:0
+master_repl_offset:1751
+`
+
+const testClusterInfoOutput = `$256
+cluster_state:ok
+cluster_slots_assigned:16384
+cluster_slots_ok:16384
+cluster_slots_pfail:0
+cluster_slots_fail:0
+cluster_known_nodes:6
+cluster_size:3
+cluster_current_epoch:6
+cluster_my_epoch:2
+cluster_stats_messages_sent:1483972
+cluster_stats_messages_received:1483968
+
+-ERR unknown command 'EOF'
+`
+
+const testKeyspaceOutput = `# Replication
+role:master
+connected_slaves:0
+
+# Keyspace
+db0:keys=2,expires=0,avg_ttl=0
+db3:keys=1543,expires=12,avg_ttl=68915
+db15:keys=7,expires,avg_ttl=0
+`
+
 const testOutput = `# Server
 redis_version:2.8.9
 redis_git_sha1:00000000
@@ -206,3 +402,186 @@ db0:keys=2,expires=0,avg_ttl=0
 
 (error) ERR unknown command 'eof'
 `
+
+// readCommand reads an inline command or a RESP array of bulk strings
+func readCommand(rdr *bufio.Reader) ([]string, error) {
+	line, err := rdr.ReadString('\n')
+	if err != nil {
+		return nil, err
+	}
+	line = strings.TrimSpace(line)
+	if !strings.HasPrefix(line, "*") {
+		return strings.Fields(line), nil
+	}
+
+	n, err := strconv.Atoi(line[1:])
+	if err != nil {
+		return nil, err
+	}
+	args := make([]string, n)
+	for i := range args {
+		if _, err = rdr.ReadString('\n'); err != nil {
+			return nil, err
+		}
+		arg, err := rdr.ReadString('\n')
+		if err != nil {
+			return nil, err
+		}
+		args[i] = strings.TrimSuffix(arg, "\r\n")
+	}
+	return args, nil
+}
+
+// newTLSRedisServer starts a TLS listener answering like a redis server
+// which requires the given credentials before INFO.
+func newTLSRedisServer(t *testing.T, auth ...string) net.Listener {
+	// borrow the certificate of an httptest TLS server
+	srv := httptest.NewUnstartedServer(http.NotFoundHandler())
+	srv.StartTLS()
+	certs := srv.TLS.Certificates
+	srv.Close()
+
+	l, err := tls.Listen("tcp", "127.0.0.1:0", &tls.Config{Certificates: certs})
+	require.NoError(t, err)
+
+	go func() {
+		for {
+			c, err := l.Accept()
+			if err != nil {
+				return
+			}
+			go func(c net.Conn) {
+				defer c.Close()
+				authenticated := false
+				rdr := bufio.NewReader(c)
+				for {
+					args, err := readCommand(rdr)
+					if err != nil || len(args) == 0 {
+						return
+					}
+					line := strings.Join(args, " ")
+					switch {
+					case args[0] == "AUTH":
+						if reflect.DeepEqual(args[1:], auth) {
+							authenticated = true
+							fmt.Fprint(c, "+OK\r\n")
+						} else {
+							fmt.Fprint(c, "-WRONGPASS invalid username-password pair\r\n")
+						}
+					case !authenticated:
+						fmt.Fprint(c, "-NOAUTH Authentication required.\r\n")
+					case line == "INFO":
+						fmt.Fprintf(c, "$%d\r\n%s\r\n", len(testKeyspaceOutput), testKeyspaceOutput)
+					default:
+						fmt.Fprintf(c, "-ERR unknown command '%s'\r\n", line)
+					}
+				}
+			}(c)
+		}
+	}()
+	return l
+}
+
+func TestRedisTLSAuth(t *testing.T) {
+	l := newTLSRedisServer(t, "secret")
+	defer l.Close()
+
+	r := &Redis{
+		Servers:            []string{"rediss://" + l.Addr().String()},
+		Password:           "secret",
+		InsecureSkipVerify: true,
+	}
+	var acc testutil.Accumulator
+	require.NoError(t, acc.GatherError(r.Gather))
+	assert.True(t, acc.HasMeasurement("redis"))
+	assert.True(t, acc.HasMeasurement("redis_keyspace"))
+
+	// the password of the url takes precedence
+	r = &Redis{
+		Servers:            []string{"rediss://:secret@" + l.Addr().String()},
+		Password:           "wrong",
+		InsecureSkipVerify: true,
+	}
+	acc = testutil.Accumulator{}
+	require.NoError(t, acc.GatherError(r.Gather))
+	assert.True(t, acc.HasMeasurement("redis"))
+}
+
+func TestRedisTLSAuthUsername(t *testing.T) {
+	l := newTLSRedisServer(t, "telegraf", "secret")
+	defer l.Close()
+
+	r := &Redis{
+		Servers:            []string{"rediss://" + l.Addr().String()},
+		Username:           "telegraf",
+		Password:           "secret",
+		InsecureSkipVerify: true,
+	}
+	var acc testutil.Accumulator
+	require.NoError(t, acc.GatherError(r.Gather))
+	assert.True(t, acc.HasMeasurement("redis"))
+}
+
+func TestRedisTLSAuthPasswordWithSpaces(t *testing.T) {
+	l := newTLSRedisServer(t, "correct horse battery staple")
+	defer l.Close()
+
+	r := &Redis{
+		Servers:            []string{"rediss://" + l.Addr().String()},
+		Password:           "correct horse battery staple",
+		InsecureSkipVerify: true,
+	}
+	var acc testutil.Accumulator
+	require.NoError(t, acc.GatherError(r.Gather))
+	assert.True(t, acc.HasMeasurement("redis"))
+}
+
+func TestRedisAuthEmptyReply(t *testing.T) {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer l.Close()
+
+	go func() {
+		c, err := l.Accept()
+		if err != nil {
+			return
+		}
+		defer c.Close()
+		readCommand(bufio.NewReader(c))
+		fmt.Fprint(c, "\r\n")
+	}()
+
+	r := &Redis{
+		Servers:  []string{"tcp://" + l.Addr().String()},
+		Password: "secret",
+	}
+	var acc testutil.Accumulator
+	err = acc.GatherError(r.Gather)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "empty reply to AUTH")
+}
+
+func TestRedisCommand(t *testing.T) {
+	assert.Equal(t, "*2\r\n$4\r\nAUTH\r\n$9\r\nmy secret\r\n", string(command("AUTH", "my secret")))
+}
+
+func TestRedisTLSAuthRejected(t *testing.T) {
+	l := newTLSRedisServer(t, "secret")
+	defer l.Close()
+
+	r := &Redis{
+		Servers:            []string{"rediss://" + l.Addr().String()},
+		InsecureSkipVerify: true,
+	}
+	var acc testutil.Accumulator
+	err := acc.GatherError(r.Gather)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "NOAUTH")
+	assert.False(t, acc.HasMeasurement("redis"))
+
+	r.Password = "wrong"
+	acc = testutil.Accumulator{}
+	err = acc.GatherError(r.Gather)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "WRONGPASS")
+}
@@ -29,6 +29,11 @@ type stats struct {
 	Engine Engine `gorethink:"query_engine"`
 }
 
+type serverStats struct {
+	Server string `gorethink:"server"`
+	Engine Engine `gorethink:"query_engine"`
+}
+
 type Engine struct {
 	ClientConns   int64 `gorethink:"client_connections,omitempty"`
 	ClientActive  int64 `gorethink:"clients_active,omitempty"`
@@ -47,6 +52,9 @@ type tableStatus struct {
 }
 
 type tableStats struct {
+	DB      string  `gorethink:"db"`
+	Table   string  `gorethink:"table"`
+	Server  string  `gorethink:"server"`
 	Engine  Engine  `gorethink:"query_engine"`
 	Storage Storage `gorethink:"storage_engine"`
 }
@@ -91,12 +99,16 @@ func (e *Engine) AddEngineStats(
 	acc telegraf.Accumulator,
 	tags map[string]string,
 ) {
+	acc.AddFields("rethinkdb_engine", e.fields(keys), tags)
+}
+
+func (e *Engine) fields(keys []string) map[string]interface{} {
 	engine := reflect.ValueOf(e).Elem()
 	fields := make(map[string]interface{})
 	for _, key := range keys {
 		fields[key] = engine.FieldByName(engineStats[key]).Interface()
 	}
-	acc.AddFields("rethinkdb_engine", fields, tags)
+	return fields
 }
 
 func (s *Storage) AddStats(acc telegraf.Accumulator, tags map[string]string) {
@@ -1,10 +1,15 @@
 package rethinkdb
 
 import (
+	"encoding/json"
+	"net/url"
 	"testing"
 
 	"github.com/influxdata/telegraf/testutil"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"gopkg.in/gorethink/gorethink.v3/encoding"
 )
 
 var tags = make(map[string]string)
@@ -110,3 +115,100 @@ func TestAddStorageStats(t *testing.T) {
 		assert.True(t, acc.HasInt64Field("rethinkdb", metric))
 	}
 }
+
+// serverDocument and tableServerDocument are recorded from
+// r.db("rethinkdb").table("stats").get(["server", ...]) and
+// get(["table_server", ...]), the users table has two shards on server1
+const serverDocument = `{
+  "id": ["server", "8b2e7e1c-0b6c-4bd9-8a5e-d8d5a4b7d8f1"],
+  "server": "server1",
+  "query_engine": {
+    "client_connections": 3, "clients_active": 1,
+    "queries_per_sec": 8, "queries_total": 1200,
+    "read_docs_per_sec": 4, "read_docs_total": 900,
+    "written_docs_per_sec": 1, "written_docs_total": 250
+  }
+}`
+
+const tableServerDocument = `{
+  "id": ["table_server", "31c92680-f70c-4a4b-a49e-b238eb12c023", "8b2e7e1c-0b6c-4bd9-8a5e-d8d5a4b7d8f1"],
+  "db": "test",
+  "table": "users",
+  "server": "server1",
+  "query_engine": {
+    "read_docs_per_sec": 3, "read_docs_total": 850,
+    "written_docs_per_sec": 1, "written_docs_total": 240
+  },
+  "storage_engine": {
+    "cache": {"in_use_bytes": 1048576},
+    "disk": {
+      "read_bytes_per_sec": 0, "read_bytes_total": 4096,
+      "written_bytes_per_sec": 512, "written_bytes_total": 65536,
+      "space_usage": {"data_bytes": 2097152, "garbage_bytes": 0, "metadata_bytes": 1048576, "preallocated_bytes": 0}
+    }
+  }
+}`
+
+func decodeDocument(t *testing.T, document string, result interface{}) {
+	var doc interface{}
+	require.NoError(t, json.Unmarshal([]byte(document), &doc))
+	require.NoError(t, encoding.Decode(result, doc))
+}
+
+func testServer() *Server {
+	server := &Server{Url: &url.URL{Host: "127.0.0.1:28015"}}
+	server.serverStatus.Id = "8b2e7e1c-0b6c-4bd9-8a5e-d8d5a4b7d8f1"
+	server.serverStatus.Network.Hostname = "server1"
+	return server
+}
+
+func TestAddServerEngineStats(t *testing.T) {
+	var memberStats serverStats
+	decodeDocument(t, serverDocument, &memberStats)
+
+	var acc testutil.Accumulator
+	testServer().addServerEngineStats(memberStats, &acc)
+
+	assert.Equal(t, 1, len(acc.Metrics))
+	acc.AssertContainsTaggedFields(t, "rethinkdb_server_engine",
+		map[string]interface{}{
+			"active_clients":       int64(1),
+			"clients":              int64(3),
+			"queries_per_sec":      int64(8),
+			"total_queries":        int64(1200),
+			"read_docs_per_sec":    int64(4),
+			"total_reads":          int64(900),
+			"written_docs_per_sec": int64(1),
+			"total_writes":         int64(250),
+		},
+		map[string]string{
+			"rethinkdb_host":     "127.0.0.1:28015",
+			"rethinkdb_hostname": "server1",
+			"server":             "server1",
+		})
+}
+
+func TestAddTableServerStats(t *testing.T) {
+	var ts tableStats
+	decodeDocument(t, tableServerDocument, &ts)
+
+	var acc testutil.Accumulator
+	testServer().addTableServerStats(ts, &acc)
+
+	assert.Equal(t, 1, len(acc.Metrics))
+	acc.AssertContainsTaggedFields(t, "rethinkdb_table",
+		map[string]interface{}{
+			"read_docs_per_sec":    int64(3),
+			"total_reads":          int64(850),
+			"written_docs_per_sec": int64(1),
+			"total_writes":         int64(240),
+		},
+		map[string]string{
+			"rethinkdb_host":     "127.0.0.1:28015",
+			"rethinkdb_hostname": "server1",
+			"db":                 "test",
+			"table":              "users",
+			"server":             "server1",
+		})
+	assert.Equal(t, int64(1048576), ts.Storage.Cache.BytesInUse)
+}
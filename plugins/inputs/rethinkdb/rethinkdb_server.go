@@ -144,7 +144,7 @@ func (s *Server) addMemberStats(acc telegraf.Accumulator) error {
 		return fmt.Errorf("member stats query error, %s\n", err.Error())
 	}
 	defer cursor.Close()
-	var memberStats stats
+	var memberStats serverStats
 	if err := cursor.One(&memberStats); err != nil {
 		return fmt.Errorf("failure to parse member stats, %s\n", err.Error())
 	}
@@ -152,9 +152,17 @@ func (s *Server) addMemberStats(acc telegraf.Accumulator) error {
 	tags := s.getDefaultTags()
 	tags["type"] = "member"
 	memberStats.Engine.AddEngineStats(MemberTracking, acc, tags)
+	s.addServerEngineStats(memberStats, acc)
 	return nil
 }
 
+// addServerEngineStats adds the query engine counters of the server gathered
+func (s *Server) addServerEngineStats(memberStats serverStats, acc telegraf.Accumulator) {
+	tags := s.getDefaultTags()
+	tags["server"] = memberStats.Server
+	acc.AddFields("rethinkdb_server_engine", memberStats.Engine.fields(MemberTracking), tags)
+}
+
 var TableTracking = []string{
 	"read_docs_per_sec",
 	"total_reads",
@@ -188,6 +196,18 @@ func (s *Server) addTableStats(acc telegraf.Accumulator) error {
 		tags["ns"] = fmt.Sprintf("%s.%s", table.DB, table.Name)
 		ts.Engine.AddEngineStats(TableTracking, acc, tags)
 		ts.Storage.AddStats(acc, tags)
+		s.addTableServerStats(ts, acc)
 	}
 	return nil
 }
+
+// addTableServerStats adds the read and write stats of a table on the server
+// gathered. The table_server document already sums the shards of the table
+// hosted by the server.
+func (s *Server) addTableServerStats(ts tableStats, acc telegraf.Accumulator) {
+	tags := s.getDefaultTags()
+	tags["db"] = ts.DB
+	tags["table"] = ts.Table
+	tags["server"] = ts.Server
+	acc.AddFields("rethinkdb_table", ts.Engine.fields(TableTracking), tags)
+}
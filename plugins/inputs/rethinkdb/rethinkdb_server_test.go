@@ -51,6 +51,10 @@ func TestAddMemberStats(t *testing.T) {
 	for _, metric := range MemberTracking {
 		assert.True(t, acc.HasIntValue(metric))
 	}
+	for _, metric := range MemberTracking {
+		assert.True(t, acc.HasField("rethinkdb_server_engine", metric))
+	}
+	assert.True(t, acc.HasTag("rethinkdb_server_engine", "server"))
 }
 
 func TestAddTableStats(t *testing.T) {
@@ -62,6 +66,9 @@ func TestAddTableStats(t *testing.T) {
 	for _, metric := range TableTracking {
 		assert.True(t, acc.HasIntValue(metric))
 	}
+	for _, tag := range []string{"db", "table", "server"} {
+		assert.True(t, acc.HasTag("rethinkdb_table", tag))
+	}
 
 	keys := []string{
 		"cache_bytes_in_use",
@@ -5,6 +5,7 @@ package sensors
 import (
 	"errors"
 	"fmt"
+	"log"
 	"os/exec"
 	"regexp"
 	"strconv"
@@ -62,19 +63,28 @@ func (s *Sensors) parse(acc telegraf.Accumulator) error {
 	lines := strings.Split(strings.TrimSpace(string(out)), "\n")
 	for _, line := range lines {
 		if len(line) == 0 {
-			acc.AddFields("sensors", fields, tags)
+			if len(fields) > 0 {
+				acc.AddFields("sensors", fields, tags)
+			}
 			chip = ""
 			tags = map[string]string{}
 			fields = map[string]interface{}{}
 			continue
 		}
+		// sensors reports sub-features it fails to read on stderr, e.g.
+		//     ERROR: Can't get value of subfeature temp1_input: Can't read
+		// the other sub-features of the chip are still reported.
+		if strings.HasPrefix(line, "ERROR:") {
+			log.Printf("W! [inputs.sensors] %s", line)
+			continue
+		}
 		if len(chip) == 0 {
 			chip = line
 			tags["chip"] = chip
 			continue
 		}
 		if !strings.HasPrefix(line, "  ") {
-			if len(tags) > 1 {
+			if len(tags) > 1 && len(fields) > 0 {
 				acc.AddFields("sensors", fields, tags)
 			}
 			fields = map[string]interface{}{}
@@ -95,7 +105,9 @@ func (s *Sensors) parse(acc telegraf.Accumulator) error {
 			fields[fieldName] = fieldValue
 		}
 	}
-	acc.AddFields("sensors", fields, tags)
+	if len(fields) > 0 {
+		acc.AddFields("sensors", fields, tags)
+	}
 	return nil
 }
 
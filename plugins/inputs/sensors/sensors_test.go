@@ -290,6 +290,88 @@ func TestGatherNotRemoveNumbers(t *testing.T) {
 	}
 }
 
+func TestGatherThresholds(t *testing.T) {
+	s := Sensors{
+		RemoveNumbers: false,
+		path:          "sensors",
+	}
+	execCommand = fakeExecCommandThresholds
+	defer func() { execCommand = exec.Command }()
+	var acc testutil.Accumulator
+
+	err := s.Gather(&acc)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var tests = []struct {
+		tags   map[string]string
+		fields map[string]interface{}
+	}{
+		{
+			map[string]string{
+				"chip":    "nct6779-isa-0290",
+				"feature": "vcore",
+			},
+			map[string]interface{}{
+				"in0_input": 0.920,
+				"in0_min":   0.0,
+				"in0_max":   1.744,
+				"in0_alarm": 0.0,
+				"in0_beep":  0.0,
+			},
+		},
+		{
+			map[string]string{
+				"chip":    "nct6779-isa-0290",
+				"feature": "fan2",
+			},
+			map[string]interface{}{
+				"fan2_input": 1103.0,
+				"fan2_min":   1200.0,
+				"fan2_alarm": 1.0,
+				"fan2_beep":  0.0,
+			},
+		},
+		{
+			map[string]string{
+				"chip":    "nct6779-isa-0290",
+				"feature": "systin",
+			},
+			map[string]interface{}{
+				"temp1_input":    38.0,
+				"temp1_max":      80.0,
+				"temp1_max_hyst": 75.0,
+				"temp1_crit":     100.0,
+				"temp1_alarm":    0.0,
+				"temp1_type":     4.0,
+			},
+		},
+		{
+			map[string]string{
+				"chip":    "nouveau-pci-0100",
+				"feature": "temp1",
+			},
+			map[string]interface{}{
+				"temp1_input": 45.0,
+			},
+		},
+	}
+	for _, test := range tests {
+		acc.AssertContainsTaggedFields(t, "sensors", test.fields, test.tags)
+	}
+
+	// a feature without any readable sub-feature is dropped
+	for _, m := range acc.Metrics {
+		if m.Tags["feature"] == "auxtin3" {
+			t.Errorf("unexpected metric for unreadable feature: %v", m)
+		}
+	}
+	if len(acc.Metrics) != len(tests) {
+		t.Errorf("expected %d metrics, got %d", len(tests), len(acc.Metrics))
+	}
+}
+
 // fackeExecCommand is a helper function that mock
 // the exec.Command call (and call the test binary)
 func fakeExecCommand(command string, args ...string) *exec.Cmd {
@@ -300,6 +382,14 @@ func fakeExecCommand(command string, args ...string) *exec.Cmd {
 	return cmd
 }
 
+// fakeExecCommandThresholds mocks the exec.Command call with the output of
+// a chip reporting thresholds and alarms
+func fakeExecCommandThresholds(command string, args ...string) *exec.Cmd {
+	cmd := fakeExecCommand(command, args...)
+	cmd.Env = append(cmd.Env, "GO_HELPER_SENSORS_DATA=thresholds")
+	return cmd
+}
+
 // TestHelperProcess isn't a real test. It's used to mock exec.Command
 // For example, if you run:
 // GO_WANT_HELPER_PROCESS=1 go test -test.run=TestHelperProcess -- chrony tracking
@@ -364,6 +454,36 @@ Vcore Voltage:
   in1_max: 3.630
 `
 
+	// errors are printed on stderr before the buffered readings
+	if os.Getenv("GO_HELPER_SENSORS_DATA") == "thresholds" {
+		mockData = `ERROR: Can't get value of subfeature temp7_input: Can't read
+nct6779-isa-0290
+Vcore:
+  in0_input: 0.920
+  in0_min: 0.000
+  in0_max: 1.744
+  in0_alarm: 0.000
+  in0_beep: 0.000
+fan2:
+  fan2_input: 1103.000
+  fan2_min: 1200.000
+  fan2_alarm: 1.000
+  fan2_beep: 0.000
+SYSTIN:
+  temp1_input: 38.000
+  temp1_max: 80.000
+  temp1_max_hyst: 75.000
+  temp1_crit: 100.000
+  temp1_alarm: 0.000
+  temp1_type: 4.000
+AUXTIN3:
+
+nouveau-pci-0100
+temp1:
+  temp1_input: 45.000
+`
+	}
+
 	args := os.Args
 
 	// Previous arguments are tests stuff, that looks like :
@@ -34,6 +34,9 @@ const sampleConfig = `
 
   ## The GETBULK max-repetitions parameter
   max_repetitions = 10
+  ## Walk tables with GETBULK requests instead of GETNEXT. Ignored for
+  ## version 1, which has no GETBULK.
+  use_bulk = true
 
   ## SNMPv3 auth parameters
   #sec_name = "myuser"
@@ -112,6 +115,7 @@ type Snmp struct {
 
 	// Parameters for Version 2 & 3
 	MaxRepetitions uint8
+	UseBulk        bool
 
 	// Parameters for Version 3
 	ContextName string
@@ -145,6 +149,12 @@ func (s *Snmp) init() error {
 		return nil
 	}
 
+	if s.Version == 3 {
+		if err := s.validateV3(); err != nil {
+			return err
+		}
+	}
+
 	s.connectionCache = make([]snmpConnection, len(s.Agents))
 
 	for i := range s.Tables {
@@ -163,6 +173,42 @@ func (s *Snmp) init() error {
 	return nil
 }
 
+// validateV3 checks that the SNMPv3 protocols and passwords needed by the
+// security level are configured.
+func (s *Snmp) validateV3() error {
+	var auth, priv bool
+	switch strings.ToLower(s.SecLevel) {
+	case "noauthnopriv", "":
+	case "authnopriv":
+		auth = true
+	case "authpriv":
+		auth, priv = true, true
+	default:
+		return fmt.Errorf("invalid sec_level %q", s.SecLevel)
+	}
+
+	if s.SecName == "" {
+		return fmt.Errorf("sec_name is required for SNMPv3")
+	}
+	if auth {
+		if s.AuthProtocol == "" {
+			return fmt.Errorf("auth_protocol is required with sec_level %s", s.SecLevel)
+		}
+		if s.AuthPassword == "" {
+			return fmt.Errorf("auth_password is required with sec_level %s", s.SecLevel)
+		}
+	}
+	if priv {
+		if s.PrivProtocol == "" {
+			return fmt.Errorf("priv_protocol is required with sec_level %s", s.SecLevel)
+		}
+		if s.PrivPassword == "" {
+			return fmt.Errorf("priv_password is required with sec_level %s", s.SecLevel)
+		}
+	}
+	return nil
+}
+
 // Table holds the configuration for a SNMP table.
 type Table struct {
 	// Name will be the name of the measurement.
@@ -246,6 +292,14 @@ type Field struct {
 	//  "hwaddr" will convert a 6-byte string to a MAC address.
 	//  "ipaddr" will convert the value to an IPv4 or IPv6 address.
 	Conversion string
+	// SecondaryIndexTable marks a field from another table sharing the same
+	// index, such as ifXTable for ifTable. Its values are only added to the rows
+	// found by the other fields of the table.
+	SecondaryIndexTable bool
+	// SecondaryIndexUse replaces the index tag of the rows with the value of
+	// this field, when the table has IndexAsTag set. Rows without a value keep
+	// the numeric index.
+	SecondaryIndexUse bool
 
 	initialized bool
 }
@@ -258,7 +312,7 @@ func (f *Field) init() error {
 
 	_, oidNum, oidText, conversion, err := snmpTranslate(f.Oid)
 	if err != nil {
-		return Errorf(err, "translating")
+		return Errorf(err, "translating %s", f.Oid)
 	}
 	f.Oid = oidNum
 	if f.Name == "" {
@@ -320,6 +374,7 @@ func init() {
 			Name:           "snmp",
 			Retries:        3,
 			MaxRepetitions: 10,
+			UseBulk:        true,
 			Timeout:        internal.Duration{Duration: 5 * time.Second},
 			Version:        2,
 			Community:      "public",
@@ -412,8 +467,22 @@ func (s *Snmp) gatherTable(acc telegraf.Accumulator, gs snmpConnection, t Table,
 func (t Table) Build(gs snmpConnection, walk bool) (*RTable, error) {
 	rows := map[string]RTableRow{}
 
-	tagCount := 0
+	// walk the secondary table fields last, so that the rows they join are
+	// already known
+	fields := make([]Field, 0, len(t.Fields))
 	for _, f := range t.Fields {
+		if !f.SecondaryIndexTable {
+			fields = append(fields, f)
+		}
+	}
+	for _, f := range t.Fields {
+		if f.SecondaryIndexTable {
+			fields = append(fields, f)
+		}
+	}
+
+	tagCount := 0
+	for _, f := range fields {
 		if f.IsTag {
 			tagCount++
 		}
@@ -462,6 +531,11 @@ func (t Table) Build(gs snmpConnection, walk bool) (*RTable, error) {
 					}
 					idx = idx[:len(idx)-len(f.OidIndexSuffix)]
 				}
+				if _, ok := ifv[idx]; ok {
+					// some agents return overlapping entries across GETBULK
+					// responses, keep the first one
+					return nil
+				}
 
 				fv, err := fieldConvert(f.Conversion, ent.Value)
 				if err != nil {
@@ -479,6 +553,10 @@ func (t Table) Build(gs snmpConnection, walk bool) (*RTable, error) {
 
 		for idx, v := range ifv {
 			rtr, ok := rows[idx]
+			if !ok && f.SecondaryIndexTable {
+				// not in the primary table
+				continue
+			}
 			if !ok {
 				rtr = RTableRow{}
 				rtr.Tags = map[string]string{}
@@ -489,7 +567,12 @@ func (t Table) Build(gs snmpConnection, walk bool) (*RTable, error) {
 				if idx[0] == '.' {
 					idx = idx[1:]
 				}
-				rtr.Tags["index"] = idx
+				if _, ok := rtr.Tags["index"]; !ok {
+					rtr.Tags["index"] = idx
+				}
+				if vs := fmt.Sprintf("%v", v); f.SecondaryIndexUse && vs != "" {
+					rtr.Tags["index"] = vs
+				}
 			}
 			// don't add an empty string
 			if vs, ok := v.(string); !ok || vs != "" {
@@ -529,6 +612,8 @@ type snmpConnection interface {
 // gosnmpWrapper wraps a *gosnmp.GoSNMP object so we can use it as a snmpConnection.
 type gosnmpWrapper struct {
 	*gosnmp.GoSNMP
+	// useBulk walks with GETBULK requests, it must not be set for SNMPv1.
+	useBulk bool
 }
 
 // Host returns the value of GoSNMP.Target.
@@ -537,21 +622,25 @@ func (gsw gosnmpWrapper) Host() string {
 }
 
 // Walk wraps GoSNMP.Walk() or GoSNMP.BulkWalk(), depending on whether the
-// connection is using SNMPv1 or newer.
+// connection is configured to use GETBULK requests.
 // Also, if any error is encountered, it will just once reconnect and try again.
 func (gsw gosnmpWrapper) Walk(oid string, fn gosnmp.WalkFunc) error {
 	var err error
 	// On error, retry once.
-	// Unfortunately we can't distinguish between an error returned by gosnmp, and one returned by the walk function.
+	// Errors returned by the walk function are NestedErrors, which also break
+	// the walk at the end of the subtree, so they are not retried.
 	for i := 0; i < 2; i++ {
-		if gsw.Version == gosnmp.Version1 {
-			err = gsw.GoSNMP.Walk(oid, fn)
-		} else {
+		if gsw.useBulk {
 			err = gsw.GoSNMP.BulkWalk(oid, fn)
+		} else {
+			err = gsw.GoSNMP.Walk(oid, fn)
 		}
 		if err == nil {
 			return nil
 		}
+		if _, ok := err.(NestedError); ok {
+			return err
+		}
 		if err := gsw.GoSNMP.Connect(); err != nil {
 			return Errorf(err, "reconnecting")
 		}
@@ -587,7 +676,10 @@ func (s *Snmp) getConnection(idx int) (snmpConnection, error) {
 
 	agent := s.Agents[idx]
 
-	gs := gosnmpWrapper{&gosnmp.GoSNMP{}}
+	gs := gosnmpWrapper{
+		GoSNMP:  &gosnmp.GoSNMP{},
+		useBulk: s.UseBulk && s.Version != 1,
+	}
 	s.connectionCache[idx] = gs
 
 	host, portStr, err := net.SplitHostPort(agent)
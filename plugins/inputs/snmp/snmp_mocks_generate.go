@@ -31,6 +31,7 @@ var mockedCommands = [][]string{
 	{"snmptranslate", "-Td", "-Ob", "TEST::connections"},
 	{"snmptranslate", "-Td", "-Ob", "TEST::latency"},
 	{"snmptranslate", "-Td", "-Ob", "TEST::hostname"},
+	{"snmptranslate", "-Td", "-Ob", "TEST::unknown"},
 	{"snmptranslate", "-Td", "-Ob", "IF-MIB::ifPhysAddress.1"},
 	{"snmptranslate", "-Td", "-Ob", "BRIDGE-MIB::dot1dTpFdbAddress.1"},
 	{"snmptranslate", "-Td", "-Ob", "TCP-MIB::tcpConnectionLocalAddress.1"},
@@ -5,6 +5,9 @@ import (
 	"fmt"
 	"net"
 	"os/exec"
+	"sort"
+	"strconv"
+	"strings"
 	"sync"
 	"testing"
 	"time"
@@ -96,6 +99,7 @@ func TestSampleConfig(t *testing.T) {
 		Version:        2,
 		Community:      "public",
 		MaxRepetitions: 10,
+		UseBulk:        true,
 		Retries:        3,
 
 		Name: "system",
@@ -199,6 +203,54 @@ func TestSnmpInit(t *testing.T) {
 	}, s.Fields[0])
 }
 
+func TestSnmpInit_unknownOid(t *testing.T) {
+	s := &Snmp{
+		Fields: []Field{
+			{Oid: "TEST::unknown"},
+		},
+	}
+
+	err := s.init()
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "translating TEST::unknown")
+}
+
+func TestGather_translatedOid(t *testing.T) {
+	s := &Snmp{
+		Agents: []string{"TestGather"},
+		Name:   "mytable",
+		Tables: []Table{
+			{
+				Name: "myTable",
+				Fields: []Field{
+					{Oid: "TEST::server", IsTag: true},
+					{Name: "myField", Oid: "TEST::connections"},
+				},
+			},
+		},
+	}
+	require.NoError(t, s.init())
+	assert.Equal(t, ".1.0.0.0.1.1", s.Tables[0].Fields[0].Oid)
+	assert.Equal(t, ".1.0.0.0.1.2", s.Tables[0].Fields[1].Oid)
+	s.connectionCache[0] = tsc
+
+	acc := &testutil.Accumulator{}
+	require.NoError(t, s.Gather(acc))
+
+	require.Len(t, acc.Metrics, 3)
+	for _, server := range []string{"foo", "bar"} {
+		var found bool
+		for _, m := range acc.Metrics {
+			if m.Tags["server"] == server {
+				found = true
+				assert.Equal(t, "myTable", m.Measurement)
+				assert.Contains(t, m.Fields, "myField")
+			}
+		}
+		assert.True(t, found, "server %s", server)
+	}
+}
+
 func TestSnmpInit_noTranslate(t *testing.T) {
 	// override execCommand so it returns exec.ErrNotFound
 	defer func(ec func(string, ...string) *exec.Cmd) { execCommand = ec }(execCommand)
@@ -313,6 +365,89 @@ func TestGetSNMPConnection_v3(t *testing.T) {
 	assert.EqualValues(t, 2, sp.AuthoritativeEngineTime)
 }
 
+func TestSnmpInit_v3Validation(t *testing.T) {
+	tests := []struct {
+		secLevel     string
+		secName      string
+		authProtocol string
+		authPassword string
+		privProtocol string
+		privPassword string
+		valid        bool
+	}{
+		{"noAuthNoPriv", "myuser", "", "", "", "", true},
+		{"", "myuser", "", "", "", "", true},
+		{"noAuthNoPriv", "", "", "", "", "", false},
+		{"authNoPriv", "myuser", "SHA", "password123", "", "", true},
+		{"authNoPriv", "", "SHA", "password123", "", "", false},
+		{"authNoPriv", "myuser", "", "password123", "", "", false},
+		{"authNoPriv", "myuser", "SHA", "", "", "", false},
+		{"authPriv", "myuser", "SHA", "password123", "AES", "321drowssap", true},
+		{"authPriv", "myuser", "SHA", "password123", "AES", "", false},
+		{"authPriv", "myuser", "SHA", "password123", "", "321drowssap", false},
+		{"authPriv", "myuser", "", "password123", "AES", "321drowssap", false},
+		{"bogus", "myuser", "SHA", "password123", "AES", "321drowssap", false},
+	}
+
+	for _, tt := range tests {
+		s := &Snmp{
+			Agents:       []string{"1.2.3.4"},
+			Version:      3,
+			SecLevel:     tt.secLevel,
+			SecName:      tt.secName,
+			AuthProtocol: tt.authProtocol,
+			AuthPassword: tt.authPassword,
+			PrivProtocol: tt.privProtocol,
+			PrivPassword: tt.privPassword,
+		}
+		err := s.init()
+		if tt.valid {
+			assert.NoError(t, err, "%+v", tt)
+		} else {
+			assert.Error(t, err, "%+v", tt)
+		}
+	}
+}
+
+func TestGetSNMPConnection_v3AuthNoPriv(t *testing.T) {
+	s := &Snmp{
+		Agents:       []string{"1.2.3.4"},
+		Version:      3,
+		SecLevel:     "authNoPriv",
+		SecName:      "myuser",
+		AuthProtocol: "SHA",
+		AuthPassword: "password123",
+	}
+	require.NoError(t, s.init())
+
+	gsc, err := s.getConnection(0)
+	require.NoError(t, err)
+	gs := gsc.(gosnmpWrapper)
+	assert.Equal(t, gosnmp.Version3, gs.Version)
+	assert.Equal(t, gosnmp.UserSecurityModel, gs.SecurityModel)
+	assert.Equal(t, gosnmp.AuthNoPriv, gs.MsgFlags&gosnmp.AuthPriv)
+	sp := gs.SecurityParameters.(*gosnmp.UsmSecurityParameters)
+	assert.Equal(t, "myuser", sp.UserName)
+	assert.Equal(t, gosnmp.SHA, sp.AuthenticationProtocol)
+	assert.Equal(t, "password123", sp.AuthenticationPassphrase)
+	assert.Equal(t, gosnmp.NoPriv, sp.PrivacyProtocol)
+}
+
+func TestGetSNMPConnection_useBulk(t *testing.T) {
+	for _, version := range []uint8{1, 2, 3} {
+		s := &Snmp{
+			Agents:  []string{"1.2.3.4"},
+			Version: version,
+			SecName: "myuser",
+			UseBulk: true,
+		}
+		require.NoError(t, s.init())
+		gsc, err := s.getConnection(0)
+		require.NoError(t, err)
+		assert.Equal(t, version != 1, gsc.(gosnmpWrapper).useBulk, "version %d", version)
+	}
+}
+
 func TestGetSNMPConnection_caching(t *testing.T) {
 	s := &Snmp{
 		Agents: []string{"1.2.3.4", "1.2.3.5", "1.2.3.5"},
@@ -369,7 +504,7 @@ func TestGosnmpWrapper_walk_retry(t *testing.T) {
 	require.NoError(t, err)
 	conn := gs.Conn
 
-	gsw := gosnmpWrapper{gs}
+	gsw := gosnmpWrapper{GoSNMP: gs, useBulk: true}
 	err = gsw.Walk(".1.0.0", func(_ gosnmp.SnmpPDU) error { return nil })
 	srvr.Close()
 	wg.Wait()
@@ -415,7 +550,7 @@ func TestGosnmpWrapper_get_retry(t *testing.T) {
 	require.NoError(t, err)
 	conn := gs.Conn
 
-	gsw := gosnmpWrapper{gs}
+	gsw := gosnmpWrapper{GoSNMP: gs, useBulk: true}
 	_, err = gsw.Get([]string{".1.0.0"})
 	srvr.Close()
 	wg.Wait()
@@ -424,6 +559,277 @@ func TestGosnmpWrapper_get_retry(t *testing.T) {
 	assert.Equal(t, (gs.Retries+1)*2, reqCount)
 }
 
+// testSNMPAgent is a minimal SNMPv2c agent answering GETNEXT and GETBULK
+// requests for a sorted list of integer OIDs.
+type testSNMPAgent struct {
+	conn   net.PacketConn
+	oids   [][]int
+	values map[string]int
+	// dup makes GETBULK responses repeat their first OID with another value.
+	dup bool
+
+	wg       sync.WaitGroup
+	requests map[byte]int
+}
+
+func newTestSNMPAgent(t *testing.T, values map[string]int, dup bool) *testSNMPAgent {
+	conn, err := net.ListenPacket("udp4", "127.0.0.1:0")
+	require.NoError(t, err)
+	a := &testSNMPAgent{
+		conn:     conn,
+		values:   values,
+		dup:      dup,
+		requests: map[byte]int{},
+	}
+	for oid := range values {
+		a.oids = append(a.oids, parseTestOid(oid))
+	}
+	sort.Slice(a.oids, func(i, j int) bool { return compareTestOids(a.oids[i], a.oids[j]) < 0 })
+
+	a.wg.Add(1)
+	go a.serve()
+	return a
+}
+
+// connect returns a SNMPv2c connection to the agent.
+func (a *testSNMPAgent) connect(t *testing.T, useBulk bool) gosnmpWrapper {
+	addr := a.conn.LocalAddr().(*net.UDPAddr)
+	gs := &gosnmp.GoSNMP{
+		Target:         addr.IP.String(),
+		Port:           uint16(addr.Port),
+		Version:        gosnmp.Version2c,
+		Community:      "public",
+		Timeout:        time.Second,
+		MaxRepetitions: 4,
+	}
+	require.NoError(t, gs.Connect())
+	return gosnmpWrapper{GoSNMP: gs, useBulk: useBulk}
+}
+
+// stop shuts the agent down and returns the number of requests it got for
+// each PDU type.
+func (a *testSNMPAgent) stop() map[byte]int {
+	a.conn.Close()
+	a.wg.Wait()
+	return a.requests
+}
+
+func (a *testSNMPAgent) serve() {
+	defer a.wg.Done()
+	buf := make([]byte, 1500)
+	for {
+		n, addr, err := a.conn.ReadFrom(buf)
+		if err != nil {
+			return
+		}
+		if resp := a.respond(buf[:n]); resp != nil {
+			a.conn.WriteTo(resp, addr)
+		}
+	}
+}
+
+func (a *testSNMPAgent) respond(req []byte) []byte {
+	_, msg, _ := berTLV(req)
+	_, _, rest := berTLV(msg)          // version
+	_, community, rest := berTLV(rest) // community
+	pduType, pdu, _ := berTLV(rest)
+	a.requests[pduType]++
+
+	_, reqID, rest := berTLV(pdu)
+	_, _, rest = berTLV(rest) // non-repeaters
+	_, maxReps, rest := berTLV(rest)
+	_, vbl, _ := berTLV(rest)
+	_, vb, _ := berTLV(vbl)
+	_, oid, _ := berTLV(vb)
+	last := decodeTestOid(oid)
+
+	count := 1
+	if pduType == 0xa5 {
+		count = int(maxReps[0])
+	}
+	var varbinds []byte
+	for i := 0; i < count; i++ {
+		next := a.next(last)
+		if next == nil {
+			// endOfMibView
+			varbinds = append(varbinds, berEncode(0x30,
+				append(berEncode(0x06, encodeTestOid(last)), 0x82, 0x00))...)
+			break
+		}
+		value := a.values[formatTestOid(next)]
+		varbinds = append(varbinds, berEncode(0x30,
+			append(berEncode(0x06, encodeTestOid(next)), berEncode(0x02, []byte{byte(value)})...))...)
+		if a.dup && i == 0 && count > 1 {
+			varbinds = append(varbinds, berEncode(0x30,
+				append(berEncode(0x06, encodeTestOid(next)), berEncode(0x02, []byte{0x7f})...))...)
+		}
+		last = next
+	}
+
+	resp := berEncode(0x02, reqID)
+	resp = append(resp, berEncode(0x02, []byte{0})...)
+	resp = append(resp, berEncode(0x02, []byte{0})...)
+	resp = append(resp, berEncode(0x30, varbinds)...)
+	msg = berEncode(0x02, []byte{1})
+	msg = append(msg, berEncode(0x04, community)...)
+	msg = append(msg, berEncode(0xa2, resp)...)
+	return berEncode(0x30, msg)
+}
+
+// next returns the first OID of the agent following oid.
+func (a *testSNMPAgent) next(oid []int) []int {
+	for _, o := range a.oids {
+		if compareTestOids(o, oid) > 0 {
+			return o
+		}
+	}
+	return nil
+}
+
+func berTLV(b []byte) (byte, []byte, []byte) {
+	tag, l := b[0], int(b[1])
+	b = b[2:]
+	if l&0x80 != 0 {
+		n := l & 0x7f
+		l = 0
+		for _, c := range b[:n] {
+			l = l<<8 | int(c)
+		}
+		b = b[n:]
+	}
+	return tag, b[:l], b[l:]
+}
+
+func berEncode(tag byte, content []byte) []byte {
+	b := []byte{tag}
+	if len(content) < 0x80 {
+		b = append(b, byte(len(content)))
+	} else {
+		b = append(b, 0x82, byte(len(content)>>8), byte(len(content)))
+	}
+	return append(b, content...)
+}
+
+func parseTestOid(s string) []int {
+	var oid []int
+	for _, part := range strings.Split(strings.TrimPrefix(s, "."), ".") {
+		n, _ := strconv.Atoi(part)
+		oid = append(oid, n)
+	}
+	return oid
+}
+
+func formatTestOid(oid []int) string {
+	var s string
+	for _, n := range oid {
+		s += "." + strconv.Itoa(n)
+	}
+	return s
+}
+
+func compareTestOids(a, b []int) int {
+	for i := 0; i < len(a) && i < len(b); i++ {
+		if a[i] != b[i] {
+			return a[i] - b[i]
+		}
+	}
+	return len(a) - len(b)
+}
+
+func decodeTestOid(b []byte) []int {
+	oid := []int{int(b[0]) / 40, int(b[0]) % 40}
+	n := 0
+	for _, c := range b[1:] {
+		n = n<<7 | int(c&0x7f)
+		if c&0x80 == 0 {
+			oid = append(oid, n)
+			n = 0
+		}
+	}
+	return oid
+}
+
+func encodeTestOid(oid []int) []byte {
+	b := []byte{byte(oid[0]*40 + oid[1])}
+	for _, n := range oid[2:] {
+		var enc []byte
+		for enc = []byte{byte(n & 0x7f)}; n > 0x7f; enc = append([]byte{byte(n&0x7f) | 0x80}, enc...) {
+			n >>= 7
+		}
+		b = append(b, enc...)
+	}
+	return b
+}
+
+// testAgentTable returns an interface table with the ifIndex and ifInOctets
+// columns, followed by a column sharing the ifInOctets OID as string prefix.
+func testAgentTable() map[string]int {
+	values := map[string]int{}
+	for i := 1; i <= 10; i++ {
+		values[fmt.Sprintf(".1.3.6.1.2.1.2.2.1.1.%d", i)] = i
+		values[fmt.Sprintf(".1.3.6.1.2.1.2.2.1.10.%d", i)] = i * 10
+		values[fmt.Sprintf(".1.3.6.1.2.1.2.2.1.100.%d", i)] = 1
+	}
+	values[".1.3.6.1.2.1.31.1.1.1.1.1"] = 1
+	return values
+}
+
+var testAgentTableConfig = Table{
+	Name:       "interface",
+	IndexAsTag: true,
+	Fields: []Field{
+		{Name: "ifIndex", Oid: ".1.3.6.1.2.1.2.2.1.1"},
+		{Name: "ifInOctets", Oid: ".1.3.6.1.2.1.2.2.1.10"},
+	},
+}
+
+func TestTableBuild_bulkWalk(t *testing.T) {
+	tables := map[bool]*RTable{}
+	for _, useBulk := range []bool{false, true} {
+		agent := newTestSNMPAgent(t, testAgentTable(), false)
+		gs := agent.connect(t, useBulk)
+		tb, err := testAgentTableConfig.Build(gs, true)
+		requests := agent.stop()
+		require.NoError(t, err)
+
+		if useBulk {
+			assert.Equal(t, 0, requests[0xa1], "GETNEXT requests")
+			assert.True(t, requests[0xa5] > 0, "GETBULK requests")
+			// 10 entries per column fetched 4 at a time
+			assert.True(t, requests[0xa5] <= 6, "GETBULK requests: %d", requests[0xa5])
+		} else {
+			assert.Equal(t, 0, requests[0xa5], "GETBULK requests")
+			// one request per entry, plus the one leaving each column
+			assert.Equal(t, 22, requests[0xa1], "GETNEXT requests: %d", requests[0xa1])
+		}
+		tables[useBulk] = tb
+	}
+
+	require.Len(t, tables[true].Rows, 10)
+	require.Len(t, tables[false].Rows, 10)
+	for _, row := range tables[false].Rows {
+		assert.Contains(t, tables[true].Rows, row)
+	}
+	assert.Contains(t, tables[true].Rows, RTableRow{
+		Tags:   map[string]string{"index": "3"},
+		Fields: map[string]interface{}{"ifIndex": 3, "ifInOctets": 30},
+	})
+}
+
+func TestTableBuild_bulkWalkDuplicates(t *testing.T) {
+	agent := newTestSNMPAgent(t, testAgentTable(), true)
+	gs := agent.connect(t, true)
+	tb, err := testAgentTableConfig.Build(gs, true)
+	agent.stop()
+	require.NoError(t, err)
+
+	require.Len(t, tb.Rows, 10)
+	for _, row := range tb.Rows {
+		assert.NotEqual(t, 0x7f, row.Fields["ifIndex"], "row %s", row.Tags["index"])
+		assert.NotEqual(t, 0x7f, row.Fields["ifInOctets"], "row %s", row.Tags["index"])
+	}
+}
+
 func TestTableBuild_walk(t *testing.T) {
 	tbl := Table{
 		Name:       "mytable",
@@ -501,6 +907,62 @@ func TestTableBuild_walk(t *testing.T) {
 	assert.Contains(t, tb.Rows, rtr4)
 }
 
+func TestTableBuild_secondaryIndexTable(t *testing.T) {
+	tsc := &testSNMPConnection{
+		host: "tsc",
+		values: map[string]interface{}{
+			// ifTable
+			".1.3.6.1.2.1.2.2.1.10.1": 100,
+			".1.3.6.1.2.1.2.2.1.10.2": 200,
+			".1.3.6.1.2.1.2.2.1.10.3": 300,
+			// ifXTable, without interface 3 but with an interface missing from ifTable
+			".1.3.6.1.2.1.31.1.1.1.1.1":  []byte("lo"),
+			".1.3.6.1.2.1.31.1.1.1.1.2":  []byte("eth0"),
+			".1.3.6.1.2.1.31.1.1.1.1.4":  []byte("eth2"),
+			".1.3.6.1.2.1.31.1.1.1.15.2": 1000,
+		},
+	}
+	tbl := Table{
+		Name:       "interface",
+		IndexAsTag: true,
+		Fields: []Field{
+			{
+				Name:                "ifName",
+				Oid:                 ".1.3.6.1.2.1.31.1.1.1.1",
+				IsTag:               true,
+				SecondaryIndexTable: true,
+				SecondaryIndexUse:   true,
+			},
+			{
+				Name:                "ifHighSpeed",
+				Oid:                 ".1.3.6.1.2.1.31.1.1.1.15",
+				SecondaryIndexTable: true,
+			},
+			{
+				Name: "ifInOctets",
+				Oid:  ".1.3.6.1.2.1.2.2.1.10",
+			},
+		},
+	}
+
+	tb, err := tbl.Build(tsc, true)
+	require.NoError(t, err)
+
+	assert.Len(t, tb.Rows, 3)
+	assert.Contains(t, tb.Rows, RTableRow{
+		Tags:   map[string]string{"index": "lo", "ifName": "lo"},
+		Fields: map[string]interface{}{"ifInOctets": 100},
+	})
+	assert.Contains(t, tb.Rows, RTableRow{
+		Tags:   map[string]string{"index": "eth0", "ifName": "eth0"},
+		Fields: map[string]interface{}{"ifInOctets": 200, "ifHighSpeed": 1000},
+	})
+	assert.Contains(t, tb.Rows, RTableRow{
+		Tags:   map[string]string{"index": "3"},
+		Fields: map[string]interface{}{"ifInOctets": 300},
+	})
+}
+
 func TestTableBuild_noWalk(t *testing.T) {
 	tbl := Table{
 		Name: "mytable",
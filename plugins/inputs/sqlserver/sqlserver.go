@@ -2,6 +2,9 @@ package sqlserver
 
 import (
 	"database/sql"
+	"fmt"
+	"log"
+	"strconv"
 	"sync"
 	"time"
 
@@ -14,7 +17,17 @@ import (
 
 // SQLServer struct
 type SQLServer struct {
-	Servers []string
+	Servers                  []string
+	GatherAvailabilityGroups bool          `toml:"gather_availability_groups"`
+	Queries                  []CustomQuery `toml:"query"`
+}
+
+// CustomQuery is a user defined query, its numeric columns are added as
+// fields and the TagColumns as tags
+type CustomQuery struct {
+	Script      string
+	Measurement string
+	TagColumns  []string `toml:"tag_columns"`
 }
 
 // Query struct
@@ -41,6 +54,18 @@ var sampleConfig = `
   # servers = [
   #  "Server=192.168.1.10;Port=1433;User Id=<user>;Password=<pw>;app name=telegraf;log=1;",
   # ]
+
+  ## Gather the synchronization health of the Always On availability
+  ## replicas, skipped on instances without availability groups.
+  # gather_availability_groups = false
+
+  ## Custom queries, executed on every server. The numeric columns of the
+  ## result are added as fields, the tag_columns as tags. Other columns
+  ## are skipped.
+  # [[inputs.sqlserver.query]]
+  #   script = "SELECT @@SERVERNAME AS servername, name, COUNT(*) AS jobs FROM msdb.dbo.sysjobs_view GROUP BY name"
+  #   measurement = "sqlserver_jobs"
+  #   tag_columns = ["servername", "name"]
 `
 
 // SampleConfig return the sample configuration
@@ -82,13 +107,11 @@ func (s *SQLServer) Gather(acc telegraf.Accumulator) error {
 	var wg sync.WaitGroup
 
 	for _, serv := range s.Servers {
-		for _, query := range queries {
-			wg.Add(1)
-			go func(serv string, query Query) {
-				defer wg.Done()
-				acc.AddError(s.gatherServer(serv, query, acc))
-			}(serv, query)
-		}
+		wg.Add(1)
+		go func(serv string) {
+			defer wg.Done()
+			acc.AddError(s.gatherInstance(serv, acc))
+		}(serv)
 	}
 
 	wg.Wait()
@@ -182,13 +205,273 @@ func (s *SQLServer) accRow(query Query, acc telegraf.Accumulator, row scanner) e
 	return nil
 }
 
+// gatherInstance detects the edition of the server and runs the queries
+// supported by the edition. The built-in queries run concurrently, the
+// optional and custom queries share a single connection.
+func (s *SQLServer) gatherInstance(server string, acc telegraf.Accumulator) error {
+	conn, err := sql.Open("mssql", server)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+	err = conn.Ping()
+	if err != nil {
+		return err
+	}
+
+	azure, err := isAzureSQLDatabase(conn)
+	if err != nil {
+		return err
+	}
+
+	var wg sync.WaitGroup
+	if azure {
+		// the on-premise DMVs aren't available in Azure SQL Database
+		acc.AddError(gatherAzureResourceStats(conn, acc))
+	} else {
+		for _, query := range queries {
+			wg.Add(1)
+			go func(query Query) {
+				defer wg.Done()
+				acc.AddError(s.gatherServer(server, query, acc))
+			}(query)
+		}
+		if s.GatherAvailabilityGroups {
+			acc.AddError(gatherAvailabilityReplicas(conn, acc))
+		}
+	}
+	for _, query := range s.Queries {
+		acc.AddError(gatherCustomQuery(conn, query, acc))
+	}
+
+	wg.Wait()
+	return nil
+}
+
+func isAzureSQLDatabase(conn *sql.DB) (bool, error) {
+	var engineEdition int
+	err := conn.QueryRow(sqlEngineEdition).Scan(&engineEdition)
+	if err != nil {
+		return false, err
+	}
+	return engineEdition == engineEditionAzure, nil
+}
+
+func gatherAzureResourceStats(conn *sql.DB, acc telegraf.Accumulator) error {
+	rows, err := conn.Query(sqlAzureDBResourceStats)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var (
+			databaseName                                        string
+			avgCPUPercent, avgDataIOPercent, avgLogWritePercent float64
+			dtuLimit                                            sql.NullInt64
+		)
+		err := rows.Scan(&databaseName, &avgCPUPercent, &avgDataIOPercent,
+			&avgLogWritePercent, &dtuLimit)
+		if err != nil {
+			return err
+		}
+		tags := map[string]string{
+			"database_name": databaseName,
+		}
+		fields := map[string]interface{}{
+			"avg_cpu_percent":       avgCPUPercent,
+			"avg_data_io_percent":   avgDataIOPercent,
+			"avg_log_write_percent": avgLogWritePercent,
+		}
+		// vCore databases don't have a DTU limit
+		if dtuLimit.Valid {
+			fields["dtu_limit"] = dtuLimit.Int64
+		}
+		acc.AddFields("sqlserver_azure_db_resource_stats", fields, tags, time.Now())
+	}
+	return rows.Err()
+}
+
+func gatherAvailabilityReplicas(conn *sql.DB, acc telegraf.Accumulator) error {
+	// standalone instances and versions older than 2012 don't have the
+	// availability groups DMVs or leave them empty
+	var hadrEnabled int
+	err := conn.QueryRow(sqlHadrEnabled).Scan(&hadrEnabled)
+	if err != nil {
+		return err
+	}
+	if hadrEnabled == 0 {
+		return nil
+	}
+
+	rows, err := conn.Query(sqlAvailabilityReplicas)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var (
+			availabilityGroup, replicaServerName, synchronizationState string
+			logSendQueueSize, redoQueueSize, secondaryLagSeconds       int64
+		)
+		err := rows.Scan(&availabilityGroup, &replicaServerName, &synchronizationState,
+			&logSendQueueSize, &redoQueueSize, &secondaryLagSeconds)
+		if err != nil {
+			return err
+		}
+		tags := map[string]string{
+			"availability_group":    availabilityGroup,
+			"replica_server_name":   replicaServerName,
+			"synchronization_state": synchronizationState,
+		}
+		fields := map[string]interface{}{
+			"log_send_queue_size":   logSendQueueSize,
+			"redo_queue_size":       redoQueueSize,
+			"secondary_lag_seconds": secondaryLagSeconds,
+		}
+		acc.AddFields("sqlserver_availability_replica", fields, tags, time.Now())
+	}
+	return rows.Err()
+}
+
+func gatherCustomQuery(conn *sql.DB, query CustomQuery, acc telegraf.Accumulator) error {
+	if len(query.Script) == 0 {
+		return fmt.Errorf("custom query of measurement %q has no script", query.Measurement)
+	}
+	measurement := query.Measurement
+	if len(measurement) == 0 {
+		measurement = "sqlserver_query"
+	}
+
+	rows, err := conn.Query(query.Script)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	columns, err := rows.Columns()
+	if err != nil {
+		return err
+	}
+	isTag := make(map[string]bool)
+	for _, column := range query.TagColumns {
+		isTag[column] = true
+	}
+
+	// scan as many values as the result has columns, whatever the number
+	// of columns the script was expected to return
+	values := make([]interface{}, len(columns))
+	valuePtrs := make([]interface{}, len(columns))
+	for i := range values {
+		valuePtrs[i] = &values[i]
+	}
+
+	skipped := make(map[string]bool)
+	for rows.Next() {
+		if err := rows.Scan(valuePtrs...); err != nil {
+			return err
+		}
+		tags := make(map[string]string)
+		fields := make(map[string]interface{})
+		for i, column := range columns {
+			if values[i] == nil {
+				continue
+			}
+			if isTag[column] {
+				tags[column] = tagValue(values[i])
+				continue
+			}
+			if value, ok := fieldValue(values[i]); ok {
+				fields[column] = value
+			} else if !skipped[column] {
+				skipped[column] = true
+				log.Printf("W! [inputs.sqlserver] Skipping non-numeric column %q of measurement %q, add it to tag_columns to keep it",
+					column, measurement)
+			}
+		}
+		if len(fields) > 0 {
+			acc.AddFields(measurement, fields, tags, time.Now())
+		}
+	}
+	return rows.Err()
+}
+
+func tagValue(value interface{}) string {
+	if b, ok := value.([]byte); ok {
+		return string(b)
+	}
+	return fmt.Sprint(value)
+}
+
+// fieldValue converts numeric column values, decimals are returned by the
+// driver as []byte
+func fieldValue(value interface{}) (interface{}, bool) {
+	switch v := value.(type) {
+	case int64, float64:
+		return v, true
+	case int32:
+		return int64(v), true
+	case int16:
+		return int64(v), true
+	case uint8:
+		return int64(v), true
+	case float32:
+		return float64(v), true
+	case bool:
+		return v, true
+	case []byte:
+		if f, err := strconv.ParseFloat(string(v), 64); err == nil {
+			return f, true
+		}
+	}
+	return nil, false
+}
+
 func init() {
 	inputs.Add("sqlserver", func() telegraf.Input {
 		return &SQLServer{}
 	})
 }
 
+// EngineEdition of Azure SQL Database
+const engineEditionAzure = 5
+
 // queries
+const sqlEngineEdition string = `SELECT CAST(SERVERPROPERTY('EngineEdition') AS int);`
+
+// Resource usage of the last 15 seconds
+const sqlAzureDBResourceStats string = `SET NOCOUNT ON;
+SELECT TOP 1
+	DB_NAME() AS database_name,
+	avg_cpu_percent,
+	avg_data_io_percent,
+	avg_log_write_percent,
+	dtu_limit
+FROM sys.dm_db_resource_stats
+ORDER BY end_time DESC;
+`
+
+const sqlHadrEnabled string = `SELECT ISNULL(CAST(SERVERPROPERTY('IsHadrEnabled') AS int), 0);`
+
+// The queue sizes (KB) of the databases of each replica are summed, the lag
+// is the largest delay behind the last commit on the primary replica
+const sqlAvailabilityReplicas string = `SET NOCOUNT ON;
+SELECT
+	ag.name AS availability_group,
+	ar.replica_server_name,
+	drs.synchronization_state_desc AS synchronization_state,
+	SUM(ISNULL(drs.log_send_queue_size, 0)) AS log_send_queue_size,
+	SUM(ISNULL(drs.redo_queue_size, 0)) AS redo_queue_size,
+	MAX(ISNULL(DATEDIFF(SECOND, drs.last_commit_time, pdrs.last_commit_time), 0)) AS secondary_lag_seconds
+FROM sys.dm_hadr_database_replica_states AS drs
+INNER JOIN sys.availability_replicas AS ar ON ar.replica_id = drs.replica_id
+INNER JOIN sys.availability_groups AS ag ON ag.group_id = drs.group_id
+LEFT JOIN sys.dm_hadr_database_replica_states AS pdrs
+	ON pdrs.group_database_id = drs.group_database_id AND pdrs.is_primary_replica = 1
+GROUP BY ag.name, ar.replica_server_name, drs.synchronization_state_desc;
+`
+
 const sqlPerformanceMetrics string = `SET NOCOUNT ON;
 SET ARITHABORT ON;
 SET QUOTED_IDENTIFIER ON;
@@ -1,12 +1,16 @@
 package sqlserver
 
 import (
+	"database/sql/driver"
+	"fmt"
 	"strconv"
 	"strings"
 	"testing"
 	"time"
 
 	"github.com/influxdata/telegraf/testutil"
+	"github.com/influxdata/telegraf/testutil/fakesql"
+	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
 
@@ -81,6 +85,195 @@ func TestSqlServer_ParseMetrics(t *testing.T) {
 	}
 }
 
+func TestSqlServer_CustomQuery(t *testing.T) {
+	db, fake := fakesql.Open(t, fakesql.Result{
+		Match:   "FROM msdb.dbo.sysjobs",
+		Columns: []string{"servername", "job_name", "last_run", "duration_ms", "queued", "ratio"},
+		Rows: [][]driver.Value{
+			{"SQL01", "backup", "2017-10-10 03:00:00", int64(3200), int64(2), []byte("0.75")},
+			{"SQL01", "reindex", nil, int64(54000), int64(0), []byte("1.50")},
+		},
+	})
+	defer db.Close()
+
+	query := CustomQuery{
+		Script:      "SELECT servername, job_name, last_run, duration_ms, queued, ratio FROM msdb.dbo.sysjobs",
+		Measurement: "sqlserver_jobs",
+		TagColumns:  []string{"servername", "job_name"},
+	}
+
+	var acc testutil.Accumulator
+	require.NoError(t, gatherCustomQuery(db, query, &acc))
+	assert.Empty(t, fake.Unmet())
+
+	// last_run is neither numeric nor a tag column and is skipped
+	acc.AssertContainsTaggedFields(t, "sqlserver_jobs",
+		map[string]interface{}{
+			"duration_ms": int64(3200),
+			"queued":      int64(2),
+			"ratio":       0.75,
+		},
+		map[string]string{"servername": "SQL01", "job_name": "backup"})
+	acc.AssertContainsTaggedFields(t, "sqlserver_jobs",
+		map[string]interface{}{
+			"duration_ms": int64(54000),
+			"queued":      int64(0),
+			"ratio":       1.5,
+		},
+		map[string]string{"servername": "SQL01", "job_name": "reindex"})
+	assert.Len(t, acc.Metrics, 2)
+}
+
+func TestSqlServer_CustomQueryErrors(t *testing.T) {
+	db, _ := fakesql.Open(t, fakesql.Result{
+		Match: "FROM queue",
+		Err:   fmt.Errorf("Invalid object name 'queue'"),
+	})
+	defer db.Close()
+
+	var acc testutil.Accumulator
+	require.Error(t, gatherCustomQuery(db, CustomQuery{Measurement: "empty"}, &acc))
+
+	err := gatherCustomQuery(db, CustomQuery{Script: "SELECT depth FROM queue"}, &acc)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "Invalid object name")
+	assert.Empty(t, acc.Metrics)
+}
+
+func TestSqlServer_AvailabilityReplicas(t *testing.T) {
+	db, fake := fakesql.Open(t,
+		fakesql.Result{
+			Match:   "SERVERPROPERTY('IsHadrEnabled')",
+			Columns: []string{""},
+			Rows:    [][]driver.Value{{int64(1)}},
+		},
+		fakesql.Result{
+			Match: "FROM sys.dm_hadr_database_replica_states",
+			Columns: []string{"availability_group", "replica_server_name", "synchronization_state",
+				"log_send_queue_size", "redo_queue_size", "secondary_lag_seconds"},
+			Rows: [][]driver.Value{
+				{"AG1", "SQL01", "SYNCHRONIZED", int64(0), int64(0), int64(0)},
+				{"AG1", "SQL02", "SYNCHRONIZING", int64(1024), int64(512), int64(12)},
+			},
+		})
+	defer db.Close()
+
+	var acc testutil.Accumulator
+	require.NoError(t, gatherAvailabilityReplicas(db, &acc))
+	assert.Empty(t, fake.Unmet())
+
+	acc.AssertContainsTaggedFields(t, "sqlserver_availability_replica",
+		map[string]interface{}{
+			"log_send_queue_size":   int64(0),
+			"redo_queue_size":       int64(0),
+			"secondary_lag_seconds": int64(0),
+		},
+		map[string]string{
+			"availability_group":    "AG1",
+			"replica_server_name":   "SQL01",
+			"synchronization_state": "SYNCHRONIZED",
+		})
+	acc.AssertContainsTaggedFields(t, "sqlserver_availability_replica",
+		map[string]interface{}{
+			"log_send_queue_size":   int64(1024),
+			"redo_queue_size":       int64(512),
+			"secondary_lag_seconds": int64(12),
+		},
+		map[string]string{
+			"availability_group":    "AG1",
+			"replica_server_name":   "SQL02",
+			"synchronization_state": "SYNCHRONIZING",
+		})
+	assert.Len(t, acc.Metrics, 2)
+}
+
+func TestSqlServer_AvailabilityReplicasStandalone(t *testing.T) {
+	// the replica states aren't queried when HADR isn't enabled
+	db, fake := fakesql.Open(t, fakesql.Result{
+		Match:   "SERVERPROPERTY('IsHadrEnabled')",
+		Columns: []string{""},
+		Rows:    [][]driver.Value{{int64(0)}},
+	})
+	defer db.Close()
+
+	var acc testutil.Accumulator
+	require.NoError(t, gatherAvailabilityReplicas(db, &acc))
+	assert.Empty(t, fake.Unmet())
+	assert.Empty(t, acc.Metrics)
+}
+
+var resourceStatsColumns = []string{"database_name", "avg_cpu_percent", "avg_data_io_percent",
+	"avg_log_write_percent", "dtu_limit"}
+
+func TestSqlServer_AzureSQLDatabase(t *testing.T) {
+	db, fake := fakesql.Open(t,
+		fakesql.Result{
+			Match:   "SERVERPROPERTY('EngineEdition')",
+			Columns: []string{""},
+			Rows:    [][]driver.Value{{int64(5)}},
+		},
+		fakesql.Result{
+			Match:   "FROM sys.dm_db_resource_stats",
+			Columns: resourceStatsColumns,
+			Rows:    [][]driver.Value{{"telegraf", 12.5, 3.25, 0.5, int64(100)}},
+		})
+	defer db.Close()
+
+	azure, err := isAzureSQLDatabase(db)
+	require.NoError(t, err)
+	assert.True(t, azure)
+
+	var acc testutil.Accumulator
+	require.NoError(t, gatherAzureResourceStats(db, &acc))
+	assert.Empty(t, fake.Unmet())
+
+	acc.AssertContainsTaggedFields(t, "sqlserver_azure_db_resource_stats",
+		map[string]interface{}{
+			"avg_cpu_percent":       12.5,
+			"avg_data_io_percent":   3.25,
+			"avg_log_write_percent": 0.5,
+			"dtu_limit":             int64(100),
+		},
+		map[string]string{"database_name": "telegraf"})
+}
+
+func TestSqlServer_AzureSQLDatabaseVCore(t *testing.T) {
+	db, fake := fakesql.Open(t, fakesql.Result{
+		Match:   "FROM sys.dm_db_resource_stats",
+		Columns: resourceStatsColumns,
+		Rows:    [][]driver.Value{{"telegraf", 40.0, 10.0, 2.0, nil}},
+	})
+	defer db.Close()
+
+	var acc testutil.Accumulator
+	require.NoError(t, gatherAzureResourceStats(db, &acc))
+	assert.Empty(t, fake.Unmet())
+
+	// without dtu_limit
+	acc.AssertContainsTaggedFields(t, "sqlserver_azure_db_resource_stats",
+		map[string]interface{}{
+			"avg_cpu_percent":       40.0,
+			"avg_data_io_percent":   10.0,
+			"avg_log_write_percent": 2.0,
+		},
+		map[string]string{"database_name": "telegraf"})
+}
+
+func TestSqlServer_OnPremiseEdition(t *testing.T) {
+	// Enterprise
+	db, fake := fakesql.Open(t, fakesql.Result{
+		Match:   "SERVERPROPERTY('EngineEdition')",
+		Columns: []string{""},
+		Rows:    [][]driver.Value{{int64(3)}},
+	})
+	defer db.Close()
+
+	azure, err := isAzureSQLDatabase(db)
+	require.NoError(t, err)
+	assert.False(t, azure)
+	assert.Empty(t, fake.Unmet())
+}
+
 const mockPerformanceMetrics = `measurement;servername;type;Point In Time Recovery;Available physical memory (bytes);Average pending disk IO;Average runnable tasks;Average tasks;Buffer pool rate (bytes/sec);Connection memory per connection (bytes);Memory grant pending;Page File Usage (%);Page lookup per batch request;Page split per batch request;Readahead per page read;Signal wait (%);Sql compilation per batch request;Sql recompilation per batch request;Total target memory ratio
 Performance metrics;WIN8-DEV;Performance metrics;0;6353158144;0;0;7;2773;415061;0;25;229371;130;10;18;188;52;14`
 
@@ -30,6 +30,9 @@ const (
 
 	defaultProtocol = "udp"
 
+	// protocolUDPAndTCP listens on both UDP and TCP
+	protocolUDPAndTCP = "udp+tcp"
+
 	defaultSeparator           = "_"
 	defaultAllowPendingMessage = 10000
 	MaxTCPConnections          = 250
@@ -171,10 +174,12 @@ func (_ *Statsd) Description() string {
 }
 
 const sampleConfig = `
-  ## Protocol, must be "tcp", "udp", "udp4" or "udp6" (default=udp)
+  ## Protocol, must be "tcp", "udp", "udp4", "udp6" or "udp+tcp" to listen
+  ## on both UDP and TCP (default=udp)
   protocol = "udp"
 
-  ## MaxTCPConnection - applicable when protocol is set to tcp (default=250)
+  ## MaxTCPConnection - applicable when protocol is set to tcp or udp+tcp
+  ## (default=250)
   max_tcp_connections = 250
 
   ## Address and port to host UDP listener on
@@ -325,14 +330,17 @@ func (s *Statsd) Start(_ telegraf.Accumulator) error {
 		s.MetricSeparator = defaultSeparator
 	}
 
-	s.wg.Add(2)
-	// Start the UDP listener
+	// Start the UDP and/or TCP listener
 	if s.isUDP() {
+		s.wg.Add(1)
 		go s.udpListen()
-	} else {
+	}
+	if s.isTCP() {
+		s.wg.Add(1)
 		go s.tcpListen()
 	}
 	// Start the line parser
+	s.wg.Add(1)
 	go s.parser()
 	log.Printf("I! Started the statsd service on %s\n", s.ServiceAddress)
 	return nil
@@ -381,8 +389,12 @@ func (s *Statsd) tcpListen() error {
 func (s *Statsd) udpListen() error {
 	defer s.wg.Done()
 	var err error
-	address, _ := net.ResolveUDPAddr(s.Protocol, s.ServiceAddress)
-	s.UDPlistener, err = net.ListenUDP(s.Protocol, address)
+	network := s.Protocol
+	if network == protocolUDPAndTCP {
+		network = "udp"
+	}
+	address, _ := net.ResolveUDPAddr(network, s.ServiceAddress)
+	s.UDPlistener, err = net.ListenUDP(network, address)
 	if err != nil {
 		log.Fatalf("ERROR: ListenUDP - %s", err)
 	}
@@ -455,24 +467,7 @@ func (s *Statsd) parseStatsdLine(line string) error {
 		for _, segment := range pipesplit {
 			if len(segment) > 0 && segment[0] == '#' {
 				// we have ourselves a tag; they are comma separated
-				tagstr := segment[1:]
-				tags := strings.Split(tagstr, ",")
-				for _, tag := range tags {
-					ts := strings.SplitN(tag, ":", 2)
-					var k, v string
-					switch len(ts) {
-					case 1:
-						// just a tag
-						k = ts[0]
-						v = ""
-					case 2:
-						k = ts[0]
-						v = ts[1]
-					}
-					if k != "" {
-						lineTags[k] = v
-					}
-				}
+				parseDataDogTags(segment[1:], lineTags)
 			} else {
 				recombinedSegments = append(recombinedSegments, segment)
 			}
@@ -600,6 +595,24 @@ func (s *Statsd) parseStatsdLine(line string) error {
 	return nil
 }
 
+// parseDataDogTags adds the comma separated DataDog tags in tagstr to tags.
+// Tags without a value, such as "#live", are given the value "true" as empty
+// tag values cannot be written; tags with an empty key are ignored.
+func parseDataDogTags(tagstr string, tags map[string]string) {
+	for _, tag := range strings.Split(tagstr, ",") {
+		ts := strings.SplitN(tag, ":", 2)
+		k := strings.TrimSpace(ts[0])
+		if k == "" {
+			continue
+		}
+		v := "true"
+		if len(ts) == 2 && ts[1] != "" {
+			v = ts[1]
+		}
+		tags[k] = v
+	}
+}
+
 // parseName parses the given bucket name with the list of bucket maps in the
 // config file. If there is a match, it will parse the name of the metric and
 // map of tags.
@@ -826,7 +839,8 @@ func (s *Statsd) Stop() {
 	close(s.done)
 	if s.isUDP() {
 		s.UDPlistener.Close()
-	} else {
+	}
+	if s.isTCP() {
 		s.TCPlistener.Close()
 		// Close all open TCP connections
 		//  - get all conns from the s.conns map and put into slice
@@ -857,6 +871,11 @@ func (s *Statsd) isUDP() bool {
 	return strings.HasPrefix(s.Protocol, "udp")
 }
 
+// isTCP returns true if the protocol is TCP, false otherwise.
+func (s *Statsd) isTCP() bool {
+	return s.Protocol == "tcp" || s.Protocol == protocolUDPAndTCP
+}
+
 func init() {
 	inputs.Add("statsd", func() telegraf.Input {
 		return &Statsd{
@@ -4,6 +4,7 @@ import (
 	"bytes"
 	"errors"
 	"fmt"
+	"math/rand"
 	"net"
 	"testing"
 	"time"
@@ -125,6 +126,50 @@ func TestCloseConcurrentConns(t *testing.T) {
 	listener.Stop()
 }
 
+// Test that metrics sent over both UDP and TCP are aggregated
+func TestUDPAndTCP(t *testing.T) {
+	listener := NewTestStatsd()
+	listener.Protocol = "udp+tcp"
+	listener.ServiceAddress = "127.0.0.1:8125"
+	listener.AllowedPendingMessages = 10000
+	listener.MaxTCPConnections = 2
+
+	acc := &testutil.Accumulator{}
+	require.NoError(t, listener.Start(acc))
+	defer listener.Stop()
+
+	time.Sleep(time.Millisecond * 25)
+	tcpConn, err := net.Dial("tcp", "127.0.0.1:8125")
+	require.NoError(t, err)
+	defer tcpConn.Close()
+	_, err = tcpConn.Write([]byte("test.counter:1|c\ntest.counter:2|c\ntest.gauge:5|g\n"))
+	require.NoError(t, err)
+
+	udpConn, err := net.Dial("udp", "127.0.0.1:8125")
+	require.NoError(t, err)
+	defer udpConn.Close()
+	_, err = udpConn.Write([]byte("test.counter:3|c"))
+	require.NoError(t, err)
+
+	for i := 0; i < 100; i++ {
+		listener.Lock()
+		n := len(listener.counters) + len(listener.gauges)
+		var value int64
+		for _, c := range listener.counters {
+			value = c.fields[defaultFieldName].(int64)
+		}
+		listener.Unlock()
+		if n == 2 && value == 6 {
+			break
+		}
+		time.Sleep(time.Millisecond * 10)
+	}
+
+	require.NoError(t, listener.Gather(acc))
+	acc.AssertContainsFields(t, "test_counter", map[string]interface{}{"value": int64(6)})
+	acc.AssertContainsFields(t, "test_gauge", map[string]interface{}{"value": float64(5)})
+}
+
 // benchmark how long it takes to accept & process 100,000 metrics:
 func BenchmarkUDP(b *testing.B) {
 	listener := Statsd{
@@ -445,6 +490,59 @@ func TestParse_Timings(t *testing.T) {
 	acc.AssertContainsFields(t, "test_timing", valid)
 }
 
+// Tests percentiles of a known distribution
+func TestParse_TimingsPercentiles(t *testing.T) {
+	s := NewTestStatsd()
+	s.Percentiles = []int{50, 90, 95, 99}
+	acc := &testutil.Accumulator{}
+
+	// send 1 to 100 in a shuffled order
+	for _, i := range rand.Perm(100) {
+		line := fmt.Sprintf("test.timing:%d|ms", i+1)
+		if err := s.parseStatsdLine(line); err != nil {
+			t.Errorf("Parsing line %s should not have resulted in an error\n", line)
+		}
+	}
+
+	s.Gather(acc)
+
+	valid := map[string]interface{}{
+		"50_percentile": float64(51),
+		"90_percentile": float64(91),
+		"95_percentile": float64(96),
+		"99_percentile": float64(100),
+		"count":         int64(100),
+		"lower":         float64(1),
+		"upper":         float64(100),
+	}
+	m, ok := acc.Get("test_timing")
+	require.True(t, ok)
+	for name, value := range valid {
+		assert.Equal(t, value, m.Fields[name], name)
+	}
+}
+
+// Tests that high percentiles of few values are the largest value
+func TestParse_TimingsPercentilesFewValues(t *testing.T) {
+	s := NewTestStatsd()
+	s.Percentiles = []int{50, 99}
+	acc := &testutil.Accumulator{}
+
+	for _, line := range []string{"test.timing:30|ms", "test.timing:10|ms", "test.timing:20|ms"} {
+		if err := s.parseStatsdLine(line); err != nil {
+			t.Errorf("Parsing line %s should not have resulted in an error\n", line)
+		}
+	}
+
+	s.Gather(acc)
+
+	m, ok := acc.Get("test_timing")
+	require.True(t, ok)
+	assert.Equal(t, float64(20), m.Fields["50_percentile"])
+	assert.Equal(t, float64(30), m.Fields["99_percentile"])
+	assert.Equal(t, float64(30), m.Fields["upper"])
+}
+
 func TestParseScientificNotation(t *testing.T) {
 	s := NewTestStatsd()
 	sciNotationLines := []string{
@@ -881,7 +979,7 @@ func TestParse_DataDogTags(t *testing.T) {
 		},
 
 		"my_gauge": map[string]string{
-			"live": "",
+			"live": "true",
 		},
 
 		"my_set": map[string]string{
@@ -889,7 +987,7 @@ func TestParse_DataDogTags(t *testing.T) {
 		},
 
 		"my_timer": map[string]string{
-			"live": "",
+			"live": "true",
 			"host": "localhost",
 		},
 	}
@@ -918,6 +1016,53 @@ func TestParse_DataDogTags(t *testing.T) {
 	}
 }
 
+// Test that DataDog tags are added to the tags from templates and that
+// malformed tags don't drop the line
+func TestParse_DataDogTagsTemplate(t *testing.T) {
+	s := NewTestStatsd()
+	s.ParseDataDogTags = true
+	s.Templates = []string{"measurement.host.field"}
+	acc := &testutil.Accumulator{}
+
+	lines := []string{
+		"cpu.localhost.idle:10|g|#env:prod,shard:3",
+		"requests.localhost.total:1|c|#,:nokey,env:prod,flag:",
+		"latency.localhost.p:3|ms|@0.5|#env:prod",
+	}
+	for _, line := range lines {
+		err := s.parseStatsdLine(line)
+		if err != nil {
+			t.Errorf("Parsing line %s should not have resulted in an error\n", line)
+		}
+	}
+
+	s.Gather(acc)
+
+	acc.AssertContainsTaggedFields(t, "cpu",
+		map[string]interface{}{"idle": float64(10)},
+		map[string]string{
+			"host":        "localhost",
+			"env":         "prod",
+			"shard":       "3",
+			"metric_type": "gauge",
+		})
+	acc.AssertContainsTaggedFields(t, "requests",
+		map[string]interface{}{"total": int64(1)},
+		map[string]string{
+			"host":        "localhost",
+			"env":         "prod",
+			"flag":        "true",
+			"metric_type": "counter",
+		})
+	m, ok := acc.Get("latency")
+	require.True(t, ok)
+	assert.Equal(t, map[string]string{
+		"host":        "localhost",
+		"env":         "prod",
+		"metric_type": "timing",
+	}, m.Tags)
+}
+
 func tagsForItem(m interface{}) map[string]string {
 	switch m.(type) {
 	case map[string]cachedcounter:
@@ -1433,6 +1578,47 @@ func TestParse_Gauges_Delete(t *testing.T) {
 	}
 }
 
+// Tests that gauge deltas adjust the value kept between flushes
+func TestParse_GaugeDeltasAcrossFlushes(t *testing.T) {
+	s := NewTestStatsd()
+	s.DeleteGauges = false
+	acc := &testutil.Accumulator{}
+
+	steps := []struct {
+		line  string
+		value float64
+	}{
+		{"current.users:100|g", 100},
+		{"current.users:+5|g", 105},
+		{"current.users:-3|g", 102},
+		{"current.users:50|g", 50},
+	}
+
+	for _, step := range steps {
+		require.NoError(t, s.parseStatsdLine(step.line))
+		require.NoError(t, s.Gather(acc))
+		acc.AssertContainsFields(t, "current_users",
+			map[string]interface{}{"value": step.value})
+		acc.ClearMetrics()
+	}
+}
+
+// Tests that gauge deltas start from zero when gauges are deleted
+func TestParse_GaugeDeltasDelete(t *testing.T) {
+	s := NewTestStatsd()
+	s.DeleteGauges = true
+	acc := &testutil.Accumulator{}
+
+	require.NoError(t, s.parseStatsdLine("current.users:100|g"))
+	require.NoError(t, s.Gather(acc))
+	acc.ClearMetrics()
+
+	require.NoError(t, s.parseStatsdLine("current.users:+5|g"))
+	require.NoError(t, s.Gather(acc))
+	acc.AssertContainsFields(t, "current_users",
+		map[string]interface{}{"value": float64(5)})
+}
+
 // Tests the delete_sets option
 func TestParse_Sets_Delete(t *testing.T) {
 	s := NewTestStatsd()
@@ -87,7 +87,7 @@ func TestNetStats(t *testing.T) {
 
 	acc.Metrics = nil
 
-	err = (&NetStats{&mps}).Gather(&acc)
+	err = (&NetStats{ps: &mps}).Gather(&acc)
 	require.NoError(t, err)
 
 	fields3 := map[string]interface{}{
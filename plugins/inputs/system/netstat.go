@@ -2,6 +2,7 @@ package system
 
 import (
 	"fmt"
+	"path"
 	"syscall"
 
 	"github.com/influxdata/telegraf"
@@ -10,13 +11,22 @@ import (
 
 type NetStats struct {
 	ps PS
+
+	PerListener bool
+
+	// directory holding the tcp and tcp6 tables, /proc/net by default
+	procNet string
 }
 
 func (_ *NetStats) Description() string {
 	return "Read TCP metrics such as established, time wait and sockets counts."
 }
 
-var tcpstatSampleConfig = ""
+var tcpstatSampleConfig = `
+  ## Count the established, time wait and close wait connections of each
+  ## listening TCP port as the netstat_listener measurement. Linux only.
+  # per_listener = false
+`
 
 func (_ *NetStats) SampleConfig() string {
 	return tcpstatSampleConfig
@@ -61,6 +71,14 @@ func (s *NetStats) Gather(acc telegraf.Accumulator) error {
 	}
 	acc.AddFields("netstat", fields, tags)
 
+	if s.PerListener {
+		procNet := s.procNet
+		if procNet == "" {
+			procNet = path.Join(GetHostProc(), "net")
+		}
+		return gatherListeners(procNet, acc)
+	}
+
 	return nil
 }
 
@@ -0,0 +1,158 @@
+package system
+
+import (
+	"bufio"
+	"encoding/hex"
+	"fmt"
+	"net"
+	"os"
+	"path"
+	"strconv"
+	"strings"
+
+	"github.com/influxdata/telegraf"
+)
+
+// TCP states of the st column of /proc/net/tcp, see include/net/tcp_states.h
+const (
+	tcpEstablished = "01"
+	tcpTimeWait    = "06"
+	tcpCloseWait   = "08"
+	tcpListen      = "0A"
+)
+
+type procNetTCPEntry struct {
+	ip    net.IP
+	port  uint16
+	state string
+}
+
+type listenerCounts struct {
+	established int
+	timeWait    int
+	closeWait   int
+}
+
+// gatherListeners adds a netstat_listener metric per listening TCP port,
+// counting the connections accepted on that port by state.
+func gatherListeners(procNet string, acc telegraf.Accumulator) error {
+	var entries []procNetTCPEntry
+	for _, name := range []string{"tcp", "tcp6"} {
+		e, err := readProcNetTCP(path.Join(procNet, name))
+		if err != nil {
+			if os.IsNotExist(err) && name == "tcp6" {
+				// IPv6 is disabled
+				continue
+			}
+			return err
+		}
+		entries = append(entries, e...)
+	}
+
+	var listeners []procNetTCPEntry
+	counts := make(map[uint16]*listenerCounts)
+	for _, e := range entries {
+		if e.state == tcpListen {
+			listeners = append(listeners, e)
+			counts[e.port] = &listenerCounts{}
+		}
+	}
+
+	for _, e := range entries {
+		if e.state == tcpListen {
+			continue
+		}
+		c := matchListener(listeners, e, counts)
+		if c == nil {
+			continue
+		}
+		switch e.state {
+		case tcpEstablished:
+			c.established++
+		case tcpTimeWait:
+			c.timeWait++
+		case tcpCloseWait:
+			c.closeWait++
+		}
+	}
+
+	for port, c := range counts {
+		acc.AddFields("netstat_listener",
+			map[string]interface{}{
+				"tcp_established": c.established,
+				"tcp_time_wait":   c.timeWait,
+				"tcp_close_wait":  c.closeWait,
+			},
+			map[string]string{"port": strconv.Itoa(int(port))})
+	}
+	return nil
+}
+
+// matchListener returns the counts of the listener which accepted the
+// connection: one on the same local port bound to either the local address
+// of the connection or a wildcard address.
+func matchListener(listeners []procNetTCPEntry, conn procNetTCPEntry, counts map[uint16]*listenerCounts) *listenerCounts {
+	for _, l := range listeners {
+		if l.port != conn.port {
+			continue
+		}
+		if l.ip.IsUnspecified() || l.ip.Equal(conn.ip) {
+			return counts[l.port]
+		}
+	}
+	return nil
+}
+
+// readProcNetTCP reads the local address and state of each socket listed in
+// a /proc/net/tcp or /proc/net/tcp6 file.
+func readProcNetTCP(file string) ([]procNetTCPEntry, error) {
+	f, err := os.Open(file)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var entries []procNetTCPEntry
+	scanner := bufio.NewScanner(f)
+	// skip the header
+	scanner.Scan()
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) < 4 {
+			continue
+		}
+		ip, port, err := parseProcNetAddr(fields[1])
+		if err != nil {
+			return nil, fmt.Errorf("error parsing %s: %s", file, err)
+		}
+		entries = append(entries, procNetTCPEntry{ip: ip, port: port, state: fields[3]})
+	}
+	return entries, scanner.Err()
+}
+
+// parseProcNetAddr parses an address of /proc/net/tcp or /proc/net/tcp6 such
+// as 0100007F:0050. The address is made of 32 bit words in host byte order,
+// little endian on the architectures we care about, while the port is in
+// network byte order.
+func parseProcNetAddr(s string) (net.IP, uint16, error) {
+	parts := strings.Split(s, ":")
+	if len(parts) != 2 {
+		return nil, 0, fmt.Errorf("invalid address %q", s)
+	}
+
+	b, err := hex.DecodeString(parts[0])
+	if err != nil || (len(b) != net.IPv4len && len(b) != net.IPv6len) {
+		return nil, 0, fmt.Errorf("invalid address %q", s)
+	}
+	ip := make(net.IP, len(b))
+	for i := 0; i < len(b); i += 4 {
+		ip[i], ip[i+1], ip[i+2], ip[i+3] = b[i+3], b[i+2], b[i+1], b[i]
+	}
+
+	port, err := strconv.ParseUint(parts[1], 16, 16)
+	if err != nil {
+		return nil, 0, fmt.Errorf("invalid port in address %q", s)
+	}
+
+	return ip, uint16(port), nil
+}
@@ -0,0 +1,104 @@
+package system
+
+import (
+	"io/ioutil"
+	"net"
+	"os"
+	"path"
+	"testing"
+
+	"github.com/influxdata/telegraf/testutil"
+	psnet "github.com/shirou/gopsutil/net"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// Listeners on 0.0.0.0:22, 127.0.0.1:8086 and [::]:80, with connections to
+// each of them and an outgoing connection from port 35000.
+const procNetTCP = `  sl  local_address rem_address   st tx_queue rx_queue tr tm->when retrnsmt   uid  timeout inode
+   0: 00000000:0016 00000000:0000 0A 00000000:00000000 00:00000000 00000000     0        0 16512 1 0000000000000000 100 0 0 10 0
+   1: 0100007F:1F96 00000000:0000 0A 00000000:00000000 00:00000000 00000000   999        0 17123 1 0000000000000000 100 0 0 10 0
+   2: 0A00020F:0016 0100000A:D4F2 01 00000000:00000000 02:0009A5B7 00000000     0        0 20481 4 0000000000000000 20 4 29 10 -1
+   3: 0A00020F:0016 0200000A:D4F4 01 00000000:00000000 02:0009A5B7 00000000     0        0 20482 4 0000000000000000 20 4 29 10 -1
+   4: 0A00020F:0016 0300000A:D4F6 06 00000000:00000000 03:00000F2A 00000000     0        0 0 3 0000000000000000
+   5: 0100007F:1F96 0100007F:B3A2 08 00000000:00000000 00:00000000 00000000   999        0 21901 1 0000000000000000 20 4 30 10 -1
+   6: 0A00020F:1F96 0100000A:B3A4 01 00000000:00000000 00:00000000 00000000   999        0 21902 1 0000000000000000 20 4 30 10 -1
+   7: 0A00020F:88B8 0100007F:1F96 01 00000000:00000000 00:00000000 00000000  1000        0 21903 1 0000000000000000 20 4 30 10 -1
+`
+
+const procNetTCP6 = `  sl  local_address                         remote_address                        st tx_queue rx_queue tr tm->when retrnsmt   uid  timeout inode
+   0: 00000000000000000000000000000000:0050 00000000000000000000000000000000:0000 0A 00000000:00000000 00:00000000 00000000    33        0 18123 1 0000000000000000 100 0 0 10 0
+   1: 0000000000000000FFFF00000F02000A:0050 0000000000000000FFFF00000100000A:C350 01 00000000:00000000 00:00000000 00000000    33        0 22001 1 0000000000000000 20 4 30 10 -1
+   2: B80D0120000000000000000001000000:0050 B80D0120000000000000000002000000:C352 06 00000000:00000000 00:00000000 00000000     0        0 0 3 0000000000000000
+`
+
+func TestParseProcNetAddr(t *testing.T) {
+	ip, port, err := parseProcNetAddr("0100007F:1F96")
+	require.NoError(t, err)
+	assert.Equal(t, "127.0.0.1", ip.String())
+	assert.Equal(t, uint16(8086), port)
+
+	ip, port, err = parseProcNetAddr("B80D0120000000000000000001000000:0050")
+	require.NoError(t, err)
+	assert.Equal(t, "2001:db8::1", ip.String())
+	assert.Equal(t, uint16(80), port)
+
+	ip, _, err = parseProcNetAddr("0000000000000000FFFF00000F02000A:0050")
+	require.NoError(t, err)
+	assert.True(t, ip.Equal(net.ParseIP("10.0.2.15")))
+
+	_, _, err = parseProcNetAddr("0100007F")
+	assert.Error(t, err)
+	_, _, err = parseProcNetAddr("7F:0050")
+	assert.Error(t, err)
+}
+
+func TestNetStatsPerListener(t *testing.T) {
+	dir, err := ioutil.TempDir("", "netstat")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	require.NoError(t, ioutil.WriteFile(path.Join(dir, "tcp"), []byte(procNetTCP), 0644))
+	require.NoError(t, ioutil.WriteFile(path.Join(dir, "tcp6"), []byte(procNetTCP6), 0644))
+
+	var mps MockPS
+	defer mps.AssertExpectations(t)
+	mps.On("NetConnections").Return([]psnet.ConnectionStat{}, nil)
+
+	var acc testutil.Accumulator
+	err = (&NetStats{ps: &mps, PerListener: true, procNet: dir}).Gather(&acc)
+	require.NoError(t, err)
+
+	acc.AssertContainsTaggedFields(t, "netstat_listener",
+		map[string]interface{}{
+			"tcp_established": 2,
+			"tcp_time_wait":   1,
+			"tcp_close_wait":  0,
+		},
+		map[string]string{"port": "22"})
+
+	// only the connection to 127.0.0.1 was accepted by the listener
+	acc.AssertContainsTaggedFields(t, "netstat_listener",
+		map[string]interface{}{
+			"tcp_established": 0,
+			"tcp_time_wait":   0,
+			"tcp_close_wait":  1,
+		},
+		map[string]string{"port": "8086"})
+
+	acc.AssertContainsTaggedFields(t, "netstat_listener",
+		map[string]interface{}{
+			"tcp_established": 1,
+			"tcp_time_wait":   1,
+			"tcp_close_wait":  0,
+		},
+		map[string]string{"port": "80"})
+
+	listeners := 0
+	for _, m := range acc.Metrics {
+		if m.Measurement == "netstat_listener" {
+			listeners++
+		}
+	}
+	assert.Equal(t, 3, listeners)
+}
@@ -3,8 +3,10 @@ package github
 import (
 	"crypto/hmac"
 	"crypto/sha1"
+	"crypto/sha256"
 	"encoding/hex"
 	"encoding/json"
+	"hash"
 	"io/ioutil"
 	"log"
 	"net/http"
@@ -34,9 +36,9 @@ func (gh *GithubWebhook) eventHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	if gh.Secret != "" && !checkSignature(gh.Secret, data, r.Header.Get("X-Hub-Signature")) {
+	if gh.Secret != "" && !verifyRequest(gh.Secret, data, r.Header) {
 		log.Printf("E! Fail to check the github webhook signature\n")
-		w.WriteHeader(http.StatusBadRequest)
+		w.WriteHeader(http.StatusUnauthorized)
 		return
 	}
 
@@ -47,7 +49,7 @@ func (gh *GithubWebhook) eventHandler(w http.ResponseWriter, r *http.Request) {
 	}
 	if e != nil {
 		p := e.NewMetric()
-		gh.acc.AddFields("github_webhooks", p.Fields(), p.Tags(), p.Time())
+		gh.acc.AddFields(p.Name(), p.Fields(), p.Tags(), p.Time())
 	}
 
 	w.WriteHeader(http.StatusOK)
@@ -61,14 +63,6 @@ func generateEvent(data []byte, event Event) (Event, error) {
 	return event, nil
 }
 
-type newEventError struct {
-	s string
-}
-
-func (e *newEventError) Error() string {
-	return e.s
-}
-
 func NewEvent(data []byte, name string) (Event, error) {
 	log.Printf("D! New %v event received", name)
 	switch name {
@@ -116,17 +110,48 @@ func NewEvent(data []byte, name string) (Event, error) {
 		return generateEvent(data, &TeamAddEvent{})
 	case "watch":
 		return generateEvent(data, &WatchEvent{})
+	case "workflow_run":
+		return generateEvent(data, &WorkflowRunEvent{})
+	case "check_run":
+		return generateEvent(data, &CheckRunEvent{})
 	}
-	return nil, &newEventError{"Not a recognized event type"}
+	// GitHub adds new events over time, those not modeled are acknowledged
+	log.Printf("D! Ignoring unsupported %v event", name)
+	return nil, nil
+}
+
+// verifyRequest checks the HMAC of the body, with the SHA256 signature of
+// the X-Hub-Signature-256 header when GitHub sent it, or the SHA1 signature
+// of the X-Hub-Signature header.
+func verifyRequest(secret string, data []byte, header http.Header) bool {
+	if signature := header.Get("X-Hub-Signature-256"); signature != "" {
+		return checkSignature256(secret, data, signature)
+	}
+	if signature := header.Get("X-Hub-Signature"); signature != "" {
+		return checkSignature(secret, data, signature)
+	}
+	return false
 }
 
 func checkSignature(secret string, data []byte, signature string) bool {
 	return hmac.Equal([]byte(signature), []byte(generateSignature(secret, data)))
 }
 
+func checkSignature256(secret string, data []byte, signature string) bool {
+	return hmac.Equal([]byte(signature), []byte(generateSignature256(secret, data)))
+}
+
 func generateSignature(secret string, data []byte) string {
-	mac := hmac.New(sha1.New, []byte(secret))
+	return "sha1=" + hexHMAC(sha1.New, secret, data)
+}
+
+func generateSignature256(secret string, data []byte) string {
+	return "sha256=" + hexHMAC(sha256.New, secret, data)
+}
+
+func hexHMAC(h func() hash.Hash, secret string, data []byte) string {
+	mac := hmac.New(h, []byte(secret))
 	mac.Write(data)
 	result := mac.Sum(nil)
-	return "sha1=" + hex.EncodeToString(result)
+	return hex.EncodeToString(result)
 }
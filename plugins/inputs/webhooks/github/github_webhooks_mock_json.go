@@ -3557,3 +3557,127 @@ func WatchEventJSON() string {
   }
 }`
 }
+
+func WorkflowRunEventJSON() string {
+	return `{
+  "action": "completed",
+  "workflow_run": {
+    "id": 1058362916,
+    "name": "CI",
+    "node_id": "WFR_kwLOAX8vs84_FOAk",
+    "head_branch": "master",
+    "head_sha": "0d1a26e67d8f5eaf1f6ba5c57fc3c7d91ac0fd1c",
+    "run_number": 42,
+    "event": "push",
+    "status": "completed",
+    "conclusion": "failure",
+    "workflow_id": 5286391,
+    "check_suite_id": 3021581893,
+    "url": "https://api.github.com/repos/baxterthehacker/public-repo/actions/runs/1058362916",
+    "html_url": "https://github.com/baxterthehacker/public-repo/actions/runs/1058362916",
+    "created_at": "2021-07-22T13:16:45Z",
+    "updated_at": "2021-07-22T13:20:11Z",
+    "run_attempt": 1,
+    "run_started_at": "2021-07-22T13:16:50Z"
+  },
+  "workflow": {
+    "id": 5286391,
+    "node_id": "MDg6V29ya2Zsb3c1Mjg2Mzkx",
+    "name": "CI",
+    "path": ".github/workflows/ci.yml",
+    "state": "active",
+    "created_at": "2021-01-27T10:40:12.000Z",
+    "updated_at": "2021-01-27T10:40:12.000Z"
+  },
+  "repository": {
+    "id": 35129377,
+    "name": "public-repo",
+    "full_name": "baxterthehacker/public-repo",
+    "private": false,
+    "stargazers_count": 0,
+    "forks_count": 0,
+    "open_issues_count": 2
+  },
+  "sender": {
+    "login": "baxterthehacker",
+    "id": 6752317,
+    "type": "User",
+    "site_admin": false
+  }
+}`
+}
+
+func WorkflowRunRequestedEventJSON() string {
+	return `{
+  "action": "requested",
+  "workflow_run": {
+    "id": 1058362917,
+    "name": "CI",
+    "head_branch": "master",
+    "run_number": 43,
+    "event": "push",
+    "status": "queued",
+    "conclusion": null,
+    "created_at": "2021-07-22T13:21:02Z",
+    "updated_at": "2021-07-22T13:21:02Z"
+  },
+  "workflow": {
+    "id": 5286391,
+    "name": "CI",
+    "path": ".github/workflows/ci.yml"
+  },
+  "repository": {
+    "id": 35129377,
+    "full_name": "baxterthehacker/public-repo",
+    "private": false
+  },
+  "sender": {
+    "login": "baxterthehacker",
+    "site_admin": false
+  }
+}`
+}
+
+func CheckRunEventJSON() string {
+	return `{
+  "action": "completed",
+  "check_run": {
+    "id": 3147402283,
+    "name": "build (1.16)",
+    "node_id": "MDg6Q2hlY2tSdW4zMTQ3NDAyMjgz",
+    "head_sha": "0d1a26e67d8f5eaf1f6ba5c57fc3c7d91ac0fd1c",
+    "external_id": "ca395085-040a-526b-2ce8-bdc85f692774",
+    "status": "completed",
+    "conclusion": "success",
+    "started_at": "2021-07-22T13:16:58Z",
+    "completed_at": "2021-07-22T13:18:30Z",
+    "check_suite": {
+      "id": 3021581893,
+      "head_branch": "master",
+      "status": "completed",
+      "conclusion": "failure"
+    },
+    "app": {
+      "id": 15368,
+      "slug": "github-actions",
+      "name": "GitHub Actions"
+    },
+    "pull_requests": []
+  },
+  "repository": {
+    "id": 35129377,
+    "name": "public-repo",
+    "full_name": "baxterthehacker/public-repo",
+    "private": false,
+    "stargazers_count": 0,
+    "forks_count": 0,
+    "open_issues_count": 2
+  },
+  "sender": {
+    "login": "baxterthehacker",
+    "id": 6752317,
+    "type": "User",
+    "site_admin": false
+  }
+}`
+}
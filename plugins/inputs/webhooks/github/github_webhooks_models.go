@@ -85,6 +85,31 @@ type DeploymentStatus struct {
 	Description string `json:"description"`
 }
 
+type Workflow struct {
+	Name string `json:"name"`
+}
+
+type WorkflowRun struct {
+	Name         string    `json:"name"`
+	RunNumber    int       `json:"run_number"`
+	Status       string    `json:"status"`
+	Conclusion   string    `json:"conclusion"`
+	CreatedAt    time.Time `json:"created_at"`
+	UpdatedAt    time.Time `json:"updated_at"`
+	RunStartedAt time.Time `json:"run_started_at"`
+}
+
+type CheckRun struct {
+	Name        string    `json:"name"`
+	Status      string    `json:"status"`
+	Conclusion  string    `json:"conclusion"`
+	StartedAt   time.Time `json:"started_at"`
+	CompletedAt time.Time `json:"completed_at"`
+	App         struct {
+		Name string `json:"name"`
+	} `json:"app"`
+}
+
 type CommitCommentEvent struct {
 	Comment    CommitComment `json:"comment"`
 	Repository Repository    `json:"repository"`
@@ -710,3 +735,82 @@ func (s WatchEvent) NewMetric() telegraf.Metric {
 	}
 	return m
 }
+
+type WorkflowRunEvent struct {
+	Action      string      `json:"action"`
+	WorkflowRun WorkflowRun `json:"workflow_run"`
+	Workflow    Workflow    `json:"workflow"`
+	Repository  Repository  `json:"repository"`
+	Sender      Sender      `json:"sender"`
+}
+
+func (s WorkflowRunEvent) NewMetric() telegraf.Metric {
+	event := "workflow_run"
+	workflow := s.Workflow.Name
+	if workflow == "" {
+		workflow = s.WorkflowRun.Name
+	}
+	t := map[string]string{
+		"event":      event,
+		"repository": s.Repository.Repository,
+		"workflow":   workflow,
+		"action":     s.Action,
+	}
+	// the conclusion is only known once the run is completed
+	if s.WorkflowRun.Conclusion != "" {
+		t["conclusion"] = s.WorkflowRun.Conclusion
+	}
+	f := map[string]interface{}{
+		"run_number": s.WorkflowRun.RunNumber,
+		"status":     s.WorkflowRun.Status,
+	}
+	if s.WorkflowRun.Status == "completed" {
+		started := s.WorkflowRun.RunStartedAt
+		if started.IsZero() {
+			started = s.WorkflowRun.CreatedAt
+		}
+		if !started.IsZero() && s.WorkflowRun.UpdatedAt.After(started) {
+			f["run_duration_ms"] = int64(s.WorkflowRun.UpdatedAt.Sub(started) / time.Millisecond)
+		}
+	}
+	m, err := metric.New("github_workflow_run", t, f, time.Now())
+	if err != nil {
+		log.Fatalf("Failed to create %v event", event)
+	}
+	return m
+}
+
+type CheckRunEvent struct {
+	Action     string     `json:"action"`
+	CheckRun   CheckRun   `json:"check_run"`
+	Repository Repository `json:"repository"`
+	Sender     Sender     `json:"sender"`
+}
+
+func (s CheckRunEvent) NewMetric() telegraf.Metric {
+	event := "check_run"
+	t := map[string]string{
+		"event":      event,
+		"repository": s.Repository.Repository,
+		"check":      s.CheckRun.Name,
+		"action":     s.Action,
+	}
+	if s.CheckRun.App.Name != "" {
+		t["app"] = s.CheckRun.App.Name
+	}
+	if s.CheckRun.Conclusion != "" {
+		t["conclusion"] = s.CheckRun.Conclusion
+	}
+	f := map[string]interface{}{
+		"status": s.CheckRun.Status,
+	}
+	started, completed := s.CheckRun.StartedAt, s.CheckRun.CompletedAt
+	if !started.IsZero() && completed.After(started) {
+		f["duration_ms"] = int64(completed.Sub(started) / time.Millisecond)
+	}
+	m, err := metric.New("github_check_run", t, f, time.Now())
+	if err != nil {
+		log.Fatalf("Failed to create %v event", event)
+	}
+	return m
+}
@@ -21,17 +21,37 @@ func GithubWebhookRequest(event string, jsonString string, t *testing.T) {
 	}
 }
 
+func githubWebhookGather(event string, jsonString string, t *testing.T) *testutil.Accumulator {
+	var acc testutil.Accumulator
+	gh := &GithubWebhook{Path: "/github", acc: &acc}
+	req, _ := http.NewRequest("POST", "/github", strings.NewReader(jsonString))
+	req.Header.Add("X-Github-Event", event)
+	w := httptest.NewRecorder()
+	gh.eventHandler(w, req)
+	if w.Code != http.StatusOK {
+		t.Errorf("POST "+event+" returned HTTP status code %v.\nExpected %v", w.Code, http.StatusOK)
+	}
+	return &acc
+}
+
 func GithubWebhookRequestWithSignature(event string, jsonString string, t *testing.T, signature string, expectedStatus int) {
+	githubWebhookRequestWithHeader(event, jsonString, t, "X-Hub-Signature", signature, expectedStatus)
+}
+
+func githubWebhookRequestWithHeader(event string, jsonString string, t *testing.T, header string, signature string, expectedStatus int) *testutil.Accumulator {
 	var acc testutil.Accumulator
 	gh := &GithubWebhook{Path: "/github", Secret: "signature", acc: &acc}
 	req, _ := http.NewRequest("POST", "/github", strings.NewReader(jsonString))
 	req.Header.Add("X-Github-Event", event)
-	req.Header.Add("X-Hub-Signature", signature)
+	if header != "" {
+		req.Header.Add(header, signature)
+	}
 	w := httptest.NewRecorder()
 	gh.eventHandler(w, req)
 	if w.Code != expectedStatus {
 		t.Errorf("POST "+event+" returned HTTP status code %v.\nExpected %v", w.Code, expectedStatus)
 	}
+	return &acc
 }
 
 func TestCommitCommentEvent(t *testing.T) {
@@ -114,14 +134,101 @@ func TestWatchEvent(t *testing.T) {
 	GithubWebhookRequest("watch", WatchEventJSON(), t)
 }
 
+func TestWorkflowRunEvent(t *testing.T) {
+	acc := githubWebhookGather("workflow_run", WorkflowRunEventJSON(), t)
+	acc.AssertContainsTaggedFields(t, "github_workflow_run",
+		map[string]interface{}{
+			"run_number":      int64(42),
+			"status":          "completed",
+			"run_duration_ms": int64(201000),
+		},
+		map[string]string{
+			"event":      "workflow_run",
+			"repository": "baxterthehacker/public-repo",
+			"workflow":   "CI",
+			"action":     "completed",
+			"conclusion": "failure",
+		})
+
+	// a queued run has neither a conclusion nor a duration
+	acc = githubWebhookGather("workflow_run", WorkflowRunRequestedEventJSON(), t)
+	acc.AssertContainsTaggedFields(t, "github_workflow_run",
+		map[string]interface{}{
+			"run_number": int64(43),
+			"status":     "queued",
+		},
+		map[string]string{
+			"event":      "workflow_run",
+			"repository": "baxterthehacker/public-repo",
+			"workflow":   "CI",
+			"action":     "requested",
+		})
+}
+
+func TestCheckRunEvent(t *testing.T) {
+	acc := githubWebhookGather("check_run", CheckRunEventJSON(), t)
+	acc.AssertContainsTaggedFields(t, "github_check_run",
+		map[string]interface{}{
+			"status":      "completed",
+			"duration_ms": int64(92000),
+		},
+		map[string]string{
+			"event":      "check_run",
+			"repository": "baxterthehacker/public-repo",
+			"check":      "build (1.16)",
+			"app":        "GitHub Actions",
+			"action":     "completed",
+			"conclusion": "success",
+		})
+}
+
+func TestUnsupportedEvent(t *testing.T) {
+	acc := githubWebhookGather("security_advisory", `{"action": "published"}`, t)
+	if len(acc.Metrics) != 0 {
+		t.Errorf("the unsupported event was gathered: %v", acc.Metrics)
+	}
+}
+
 func TestEventWithSignatureFail(t *testing.T) {
-	GithubWebhookRequestWithSignature("watch", WatchEventJSON(), t, "signature", http.StatusBadRequest)
+	GithubWebhookRequestWithSignature("watch", WatchEventJSON(), t, "signature", http.StatusUnauthorized)
 }
 
 func TestEventWithSignatureSuccess(t *testing.T) {
 	GithubWebhookRequestWithSignature("watch", WatchEventJSON(), t, generateSignature("signature", []byte(WatchEventJSON())), http.StatusOK)
 }
 
+func TestEventWithSignature256(t *testing.T) {
+	acc := githubWebhookRequestWithHeader("watch", WatchEventJSON(), t, "X-Hub-Signature-256",
+		generateSignature256("signature", []byte(WatchEventJSON())), http.StatusOK)
+	if !acc.HasMeasurement("github_webhooks") {
+		t.Errorf("the watch event wasn't gathered")
+	}
+
+	// the SHA1 signature doesn't match the SHA256 header
+	acc = githubWebhookRequestWithHeader("watch", WatchEventJSON(), t, "X-Hub-Signature-256",
+		generateSignature("signature", []byte(WatchEventJSON())), http.StatusUnauthorized)
+	if acc.HasMeasurement("github_webhooks") {
+		t.Errorf("the watch event with an invalid signature was gathered")
+	}
+
+	// the body is signed with another secret
+	githubWebhookRequestWithHeader("watch", WatchEventJSON(), t, "X-Hub-Signature-256",
+		generateSignature256("other", []byte(WatchEventJSON())), http.StatusUnauthorized)
+}
+
+func TestEventWithoutSignature(t *testing.T) {
+	acc := githubWebhookRequestWithHeader("watch", WatchEventJSON(), t, "", "", http.StatusUnauthorized)
+	if acc.HasMeasurement("github_webhooks") {
+		t.Errorf("the watch event without a signature was gathered")
+	}
+}
+
+func TestCheckSignature256Success(t *testing.T) {
+	if !checkSignature256("It's a Secret to Everybody", []byte("Hello, World!"), "sha256=757107ea0eb2509fc211221cce984b8a37570b6d7586c22c46f4379c8b043e17") {
+		t.Errorf("Check signature failed")
+	}
+}
+
 func TestCheckSignatureSuccess(t *testing.T) {
 	if !checkSignature("my_little_secret", []byte("random-signature-body"), "sha1=3dca279e731c97c38e3019a075dee9ebbd0a99f0") {
 		t.Errorf("check signature failed")
@@ -0,0 +1,59 @@
+package win_perf_counters
+
+import (
+	"strconv"
+	"strings"
+)
+
+// counterNames translates the English names of objects and counters into the
+// names of the language of the system. The English names are resolved to
+// their index with the "Counter" value of the Perflib\009 registry key, the
+// index is then looked up in the current language.
+type counterNames struct {
+	indexes   map[string]uint32
+	lookup    func(index uint32) (string, error)
+	localized map[string]string
+}
+
+// newCounterNames builds the translation from the content of the "Counter"
+// registry value, a list alternating indexes and names, ie:
+//   1, 1847, 2, System, 4, Memory, 6, % Processor Time
+func newCounterNames(counters []string, lookup func(index uint32) (string, error)) *counterNames {
+	indexes := make(map[string]uint32)
+	for i := 0; i+1 < len(counters); i += 2 {
+		index, err := strconv.ParseUint(strings.TrimSpace(counters[i]), 10, 32)
+		if err != nil {
+			continue
+		}
+		name := strings.ToLower(counters[i+1])
+		// a few names are registered twice, the first index is used by PDH
+		if _, ok := indexes[name]; !ok {
+			indexes[name] = uint32(index)
+		}
+	}
+	return &counterNames{
+		indexes:   indexes,
+		lookup:    lookup,
+		localized: make(map[string]string),
+	}
+}
+
+// translate returns the localized name, or the name itself when it has no
+// index or the index has no localized name.
+func (c *counterNames) translate(name string) string {
+	if c == nil {
+		return name
+	}
+	if localized, ok := c.localized[name]; ok {
+		return localized
+	}
+
+	localized := name
+	if index, ok := c.indexes[strings.ToLower(name)]; ok {
+		if n, err := c.lookup(index); err == nil && len(n) > 0 {
+			localized = n
+		}
+	}
+	c.localized[name] = localized
+	return localized
+}
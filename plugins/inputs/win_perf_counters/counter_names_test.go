@@ -0,0 +1,55 @@
+package win_perf_counters
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCounterNamesTranslate(t *testing.T) {
+	// Perflib\009 Counter value
+	english := []string{
+		"1", "1847",
+		"2", "System",
+		"4", "Memory",
+		"6", "% Processor Time",
+		"238", "Processor",
+		"not an index", "Broken",
+		"1500", "Processor",
+	}
+	// Perflib\CurrentLanguage of a German system
+	german := map[uint32]string{
+		2:   "System",
+		4:   "Speicher",
+		6:   "Prozessorzeit (%)",
+		238: "Prozessor",
+	}
+	lookups := 0
+	names := newCounterNames(english, func(index uint32) (string, error) {
+		lookups++
+		if name, ok := german[index]; ok {
+			return name, nil
+		}
+		return "", fmt.Errorf("no name for index %d", index)
+	})
+
+	assert.Equal(t, "Prozessor", names.translate("Processor"))
+	assert.Equal(t, "Prozessorzeit (%)", names.translate("% Processor Time"))
+	assert.Equal(t, "Speicher", names.translate("memory"))
+	assert.Equal(t, "System", names.translate("System"))
+
+	// the literal name is kept without an index or a localized name
+	assert.Equal(t, "Broken", names.translate("Broken"))
+	assert.Equal(t, "1847", names.translate("1847"))
+	assert.Equal(t, "Custom Counter", names.translate("Custom Counter"))
+
+	// translations are cached
+	count := lookups
+	assert.Equal(t, "Prozessor", names.translate("Processor"))
+	assert.Equal(t, count, lookups)
+
+	// without the registry mapping, the names are used as they are
+	var none *counterNames
+	assert.Equal(t, "Processor", none.translate("Processor"))
+}
@@ -0,0 +1,94 @@
+package win_perf_counters
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// expandInstances returns the instances of an object to query. The
+// instances currently enumerated for the object are matched against the
+// regular expressions when useRegex is set, or replace the "*" wildcard
+// when expandWildcards is set. Other instances are kept as they are.
+func expandInstances(patterns []string, useRegex, expandWildcards, includeTotal bool,
+	enumerate func() ([]string, error)) ([]string, error) {
+
+	if !useRegex && !(expandWildcards && containsInstance(patterns, "*")) {
+		return patterns, nil
+	}
+
+	var regexps []*regexp.Regexp
+	if useRegex {
+		for _, pattern := range patterns {
+			re, err := regexp.Compile(pattern)
+			if err != nil {
+				return nil, fmt.Errorf("invalid instance regular expression %q: %s", pattern, err)
+			}
+			regexps = append(regexps, re)
+		}
+	}
+
+	names, err := enumerate()
+	if err != nil {
+		return nil, err
+	}
+	names = indexInstances(names)
+
+	var instances []string
+	if useRegex {
+		for _, name := range names {
+			if !includeTotal && strings.Contains(name, "_Total") {
+				continue
+			}
+			for _, re := range regexps {
+				if re.MatchString(name) {
+					instances = append(instances, name)
+					break
+				}
+			}
+		}
+		return instances, nil
+	}
+
+	for _, pattern := range patterns {
+		if pattern != "*" {
+			instances = append(instances, pattern)
+			continue
+		}
+		for _, name := range names {
+			if !includeTotal && strings.Contains(name, "_Total") {
+				continue
+			}
+			if !containsInstance(instances, name) {
+				instances = append(instances, name)
+			}
+		}
+	}
+	return instances, nil
+}
+
+// indexInstances suffixes the repeated instance names with their index, the
+// way counter paths address them, ie "w3wp", "w3wp#1", "w3wp#2".
+func indexInstances(names []string) []string {
+	seen := make(map[string]int)
+	indexed := make([]string, 0, len(names))
+	for _, name := range names {
+		if n, ok := seen[name]; ok {
+			indexed = append(indexed, fmt.Sprintf("%s#%d", name, n))
+			seen[name] = n + 1
+		} else {
+			indexed = append(indexed, name)
+			seen[name] = 1
+		}
+	}
+	return indexed
+}
+
+func containsInstance(instances []string, instance string) bool {
+	for _, i := range instances {
+		if i == instance {
+			return true
+		}
+	}
+	return false
+}
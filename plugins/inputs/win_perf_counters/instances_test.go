@@ -0,0 +1,83 @@
+package win_perf_counters
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// enumerator mocks the PDH enumeration of the instances of an object
+type enumerator struct {
+	instances []string
+	err       error
+	calls     int
+}
+
+func (e *enumerator) enumerate() ([]string, error) {
+	e.calls++
+	return e.instances, e.err
+}
+
+func TestExpandInstancesRegex(t *testing.T) {
+	disks := &enumerator{instances: []string{"HarddiskVolume1", "C:", "D:", "E:", "_Total"}}
+
+	instances, err := expandInstances([]string{"^C:", "^[D-Z]:$"}, true, false, false, disks.enumerate)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"C:", "D:", "E:"}, instances)
+
+	// _Total only matches with IncludeTotal
+	instances, err = expandInstances([]string{".*"}, true, false, false, disks.enumerate)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"HarddiskVolume1", "C:", "D:", "E:"}, instances)
+
+	instances, err = expandInstances([]string{".*"}, true, false, true, disks.enumerate)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"HarddiskVolume1", "C:", "D:", "E:", "_Total"}, instances)
+
+	// instances added since the last enumeration are picked up
+	disks.instances = append(disks.instances, "F:")
+	instances, err = expandInstances([]string{"^[D-Z]:$"}, true, false, false, disks.enumerate)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"D:", "E:", "F:"}, instances)
+}
+
+func TestExpandInstancesRepeatedNames(t *testing.T) {
+	processes := &enumerator{instances: []string{"w3wp", "svchost", "w3wp", "w3wp"}}
+
+	instances, err := expandInstances([]string{"^w3wp"}, true, false, false, processes.enumerate)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"w3wp", "w3wp#1", "w3wp#2"}, instances)
+}
+
+func TestExpandInstancesWildcard(t *testing.T) {
+	disks := &enumerator{instances: []string{"C:", "D:", "_Total"}}
+
+	// the wildcard is left to PDH without the expansion
+	instances, err := expandInstances([]string{"*"}, false, false, false, disks.enumerate)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"*"}, instances)
+	assert.Equal(t, 0, disks.calls)
+
+	instances, err = expandInstances([]string{"*", "_Total"}, false, true, false, disks.enumerate)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"C:", "D:", "_Total"}, instances)
+	assert.Equal(t, 1, disks.calls)
+
+	// objects without instances aren't enumerated
+	instances, err = expandInstances([]string{"------"}, false, true, false, disks.enumerate)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"------"}, instances)
+	assert.Equal(t, 1, disks.calls)
+}
+
+func TestExpandInstancesErrors(t *testing.T) {
+	disks := &enumerator{err: errors.New("The specified object was not found on the computer.")}
+
+	_, err := expandInstances([]string{"^C:"}, true, false, false, disks.enumerate)
+	assert.Error(t, err)
+
+	_, err = expandInstances([]string{"^C:("}, true, false, false, disks.enumerate)
+	assert.Error(t, err)
+}
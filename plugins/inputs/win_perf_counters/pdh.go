@@ -154,6 +154,8 @@ const (
 	PDH_FMT_NOCAP100     = 0x00008000 // can be OR-ed: do not cap values > 100.
 	PERF_DETAIL_COSTLY   = 0x00010000
 	PERF_DETAIL_STANDARD = 0x0000FFFF
+	PERF_DETAIL_WIZARD   = 400  // detail level of PdhEnumObjectItems: all the counters and instances.
+	PDH_MAX_COUNTER_NAME = 1024 // maximum length of an object or counter name.
 )
 
 type (
@@ -207,8 +209,10 @@ var (
 	pdh_AddEnglishCounterW        *syscall.Proc
 	pdh_CloseQuery                *syscall.Proc
 	pdh_CollectQueryData          *syscall.Proc
+	pdh_EnumObjectItemsW          *syscall.Proc
 	pdh_GetFormattedCounterValue  *syscall.Proc
 	pdh_GetFormattedCounterArrayW *syscall.Proc
+	pdh_LookupPerfNameByIndexW    *syscall.Proc
 	pdh_OpenQuery                 *syscall.Proc
 	pdh_ValidatePathW             *syscall.Proc
 )
@@ -222,8 +226,10 @@ func init() {
 	pdh_AddEnglishCounterW, _ = libpdhDll.FindProc("PdhAddEnglishCounterW") // XXX: only supported on versions > Vista.
 	pdh_CloseQuery = libpdhDll.MustFindProc("PdhCloseQuery")
 	pdh_CollectQueryData = libpdhDll.MustFindProc("PdhCollectQueryData")
+	pdh_EnumObjectItemsW = libpdhDll.MustFindProc("PdhEnumObjectItemsW")
 	pdh_GetFormattedCounterValue = libpdhDll.MustFindProc("PdhGetFormattedCounterValue")
 	pdh_GetFormattedCounterArrayW = libpdhDll.MustFindProc("PdhGetFormattedCounterArrayW")
+	pdh_LookupPerfNameByIndexW = libpdhDll.MustFindProc("PdhLookupPerfNameByIndexW")
 	pdh_OpenQuery = libpdhDll.MustFindProc("PdhOpenQuery")
 	pdh_ValidatePathW = libpdhDll.MustFindProc("PdhValidatePathW")
 }
@@ -329,6 +335,40 @@ func PdhCollectQueryData(hQuery PDH_HQUERY) uint32 {
 	return uint32(ret)
 }
 
+// Returns the name of the object or counter of the specified index of the local computer, in the language
+// of the system, into szNameBuffer. pcchNameBufferSize is the size of the buffer in characters, PDH_MAX_COUNTER_NAME
+// is enough.
+func PdhLookupPerfNameByIndex(dwNameIndex uint32, szNameBuffer *uint16, pcchNameBufferSize *uint32) uint32 {
+	ret, _, _ := pdh_LookupPerfNameByIndexW.Call(
+		0, // local computer
+		uintptr(dwNameIndex),
+		uintptr(unsafe.Pointer(szNameBuffer)),
+		uintptr(unsafe.Pointer(pcchNameBufferSize)))
+
+	return uint32(ret)
+}
+
+// Lists the counters and the instances of the specified object of the local computer into the
+// mszCounterList and mszInstanceList buffers, as lists of null terminated strings ended by an
+// additional null character. Call it with nil buffers and zero lengths to get the required lengths
+// (in characters) and PDH_MORE_DATA, then call it again with buffers of these lengths.
+func PdhEnumObjectItems(szObjectName string, mszCounterList *uint16, pcchCounterListLength *uint32,
+	mszInstanceList *uint16, pcchInstanceListLength *uint32, dwDetailLevel uint32) uint32 {
+	ptxt, _ := syscall.UTF16PtrFromString(szObjectName)
+	ret, _, _ := pdh_EnumObjectItemsW.Call(
+		0, // local data source
+		0, // local computer
+		uintptr(unsafe.Pointer(ptxt)),
+		uintptr(unsafe.Pointer(mszCounterList)),
+		uintptr(unsafe.Pointer(pcchCounterListLength)),
+		uintptr(unsafe.Pointer(mszInstanceList)),
+		uintptr(unsafe.Pointer(pcchInstanceListLength)),
+		uintptr(dwDetailLevel),
+		0)
+
+	return uint32(ret)
+}
+
 // Formats the given hCounter using a 'double'. The result is set into the specialized union struct pValue.
 // This function does not directly translate to a Windows counterpart due to union specialization tricks.
 func PdhGetFormattedCounterValueDouble(hCounter PDH_HCOUNTER, lpdwType *uint32, pValue *PDH_FMT_COUNTERVALUE_DOUBLE) uint32 {
@@ -6,10 +6,14 @@ import (
 	"errors"
 	"fmt"
 	"strings"
+	"syscall"
+	"time"
 	"unsafe"
 
 	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/internal"
 	"github.com/influxdata/telegraf/plugins/inputs"
+	"golang.org/x/sys/windows/registry"
 )
 
 var sampleConfig = `
@@ -20,6 +24,12 @@ var sampleConfig = `
   ## agent, it will not be gathered.
   ## Settings:
   # PrintValid = false # Print All matching performance counters
+  ## Replace the "*" instance by the instances of the object, enumerated when
+  ## the counters are refreshed.
+  # UseWildcardsExpansion = false
+  ## How often the instances are enumerated again, for the "*" instance with
+  ## UseWildcardsExpansion and for the objects with UseRegex.
+  # CountersRefreshInterval = "1m"
 
   [[inputs.win_perf_counters.object]]
     # Processor usage, alternative to native, reports on a per core.
@@ -35,6 +45,8 @@ var sampleConfig = `
     # IncludeTotal=false
     # Print out when the performance counter is missing from object, counter or instance.
     # WarnOnMissing = false
+    # Set to true to match the Instances as regular expressions, ie ["^C:", "^D:"].
+    # UseRegex = false
 
   [[inputs.win_perf_counters.object]]
     # Disk times and queues
@@ -66,12 +78,22 @@ var sampleConfig = `
 `
 
 type Win_PerfCounters struct {
-	PrintValid      bool
-	PreVistaSupport bool
-	Object          []perfobject
+	PrintValid              bool
+	PreVistaSupport         bool
+	UseWildcardsExpansion   bool
+	CountersRefreshInterval internal.Duration
+	Object                  []perfobject
 
 	configParsed bool
 	itemCache    []*item
+	// set when instances were enumerated and need to be refreshed
+	expanded    bool
+	lastRefresh time.Time
+	// lists the instances of an object, mocked in tests
+	enumerateInstances func(objectName string) ([]string, error)
+	// localized names of the objects and counters, loaded once
+	names       *counterNames
+	namesLoaded bool
 }
 
 type perfobject struct {
@@ -82,6 +104,7 @@ type perfobject struct {
 	WarnOnMissing bool
 	FailOnMissing bool
 	IncludeTotal  bool
+	UseRegex      bool
 }
 
 type item struct {
@@ -135,16 +158,49 @@ func (m *Win_PerfCounters) SampleConfig() string {
 func (m *Win_PerfCounters) ParseConfig() error {
 	var query string
 
+	if m.enumerateInstances == nil {
+		m.enumerateInstances = enumObjectInstances
+	}
+	if !m.namesLoaded {
+		// the English names are used when the mapping can't be read
+		m.names, _ = loadCounterNames()
+		m.namesLoaded = true
+	}
+	m.lastRefresh = time.Now()
+
 	if len(m.Object) > 0 {
 		for _, PerfObject := range m.Object {
+			objectname := PerfObject.ObjectName
+			instances, err := expandInstances(PerfObject.Instances, PerfObject.UseRegex,
+				m.UseWildcardsExpansion, PerfObject.IncludeTotal,
+				func() ([]string, error) { return m.enumerateInstances(m.names.translate(objectname)) })
+			if err != nil {
+				if PerfObject.FailOnMissing || PerfObject.WarnOnMissing {
+					fmt.Printf("Invalid instances of '%s'. Error: %s", objectname, err.Error())
+				}
+				if PerfObject.FailOnMissing {
+					return err
+				}
+				continue
+			}
+			if PerfObject.UseRegex || m.UseWildcardsExpansion {
+				m.expanded = true
+			}
+
 			for _, counter := range PerfObject.Counters {
-				for _, instance := range PerfObject.Instances {
-					objectname := PerfObject.ObjectName
+				for _, instance := range instances {
+					// the localized interface needs the names in the language
+					// of the system
+					pathObject, pathCounter := objectname, counter
+					if m.PreVistaSupport {
+						pathObject = m.names.translate(objectname)
+						pathCounter = m.names.translate(counter)
+					}
 
 					if instance == "------" {
-						query = "\\" + objectname + "\\" + counter
+						query = "\\" + pathObject + "\\" + pathCounter
 					} else {
-						query = "\\" + objectname + "(" + instance + ")\\" + counter
+						query = "\\" + pathObject + "(" + instance + ")\\" + pathCounter
 					}
 
 					err := m.AddItem(query, objectname, counter, instance,
@@ -173,11 +229,87 @@ func (m *Win_PerfCounters) ParseConfig() error {
 	}
 }
 
+// loadCounterNames reads the indexes of the English names of the objects and
+// counters from the registry.
+func loadCounterNames() (*counterNames, error) {
+	k, err := registry.OpenKey(registry.LOCAL_MACHINE,
+		`SOFTWARE\Microsoft\Windows NT\CurrentVersion\Perflib\009`, registry.QUERY_VALUE)
+	if err != nil {
+		return nil, err
+	}
+	defer k.Close()
+
+	counters, _, err := k.GetStringsValue("Counter")
+	if err != nil {
+		return nil, err
+	}
+	return newCounterNames(counters, lookupPerfName), nil
+}
+
+// lookupPerfName returns the name of an index in the language of the system.
+func lookupPerfName(index uint32) (string, error) {
+	buf := make([]uint16, PDH_MAX_COUNTER_NAME)
+	size := uint32(len(buf))
+	ret := PdhLookupPerfNameByIndex(index, &buf[0], &size)
+	if ret != ERROR_SUCCESS {
+		return "", errors.New(PdhFormatError(ret))
+	}
+	return syscall.UTF16ToString(buf), nil
+}
+
+// enumObjectInstances lists the current instances of an object with PDH.
+func enumObjectInstances(objectName string) ([]string, error) {
+	var counterLen, instanceLen uint32
+	ret := PdhEnumObjectItems(objectName, nil, &counterLen, nil, &instanceLen, PERF_DETAIL_WIZARD)
+	if ret != PDH_MORE_DATA {
+		if ret == ERROR_SUCCESS {
+			return nil, nil
+		}
+		return nil, errors.New(PdhFormatError(ret))
+	}
+	if instanceLen == 0 {
+		return nil, nil
+	}
+
+	counterBuf := make([]uint16, counterLen+1)
+	instanceBuf := make([]uint16, instanceLen)
+	ret = PdhEnumObjectItems(objectName, &counterBuf[0], &counterLen, &instanceBuf[0], &instanceLen, PERF_DETAIL_WIZARD)
+	if ret != ERROR_SUCCESS {
+		return nil, errors.New(PdhFormatError(ret))
+	}
+
+	// the instances are null terminated strings, the list ends with an empty string
+	var instances []string
+	for start := 0; start < len(instanceBuf); {
+		end := start
+		for end < len(instanceBuf) && instanceBuf[end] != 0 {
+			end++
+		}
+		if end == start {
+			break
+		}
+		instances = append(instances, syscall.UTF16ToString(instanceBuf[start:end]))
+		start = end + 1
+	}
+	return instances, nil
+}
+
 func (m *Win_PerfCounters) GetParsedItemsForTesting() []*item {
 	return m.itemCache
 }
 
 func (m *Win_PerfCounters) Gather(acc telegraf.Accumulator) error {
+	// Enumerate the instances again once the refresh interval has elapsed
+	if m.configParsed && m.expanded && m.CountersRefreshInterval.Duration > 0 &&
+		time.Since(m.lastRefresh) >= m.CountersRefreshInterval.Duration {
+		for _, metric := range m.itemCache {
+			PdhCloseQuery(metric.handle)
+		}
+		m.itemCache = nil
+		m.expanded = false
+		m.configParsed = false
+	}
+
 	// Parse the config once
 	if !m.configParsed {
 		err := m.ParseConfig()
@@ -261,5 +393,9 @@ func (m *Win_PerfCounters) Gather(acc telegraf.Accumulator) error {
 }
 
 func init() {
-	inputs.Add("win_perf_counters", func() telegraf.Input { return &Win_PerfCounters{} })
+	inputs.Add("win_perf_counters", func() telegraf.Input {
+		return &Win_PerfCounters{
+			CountersRefreshInterval: internal.Duration{Duration: time.Minute},
+		}
+	})
 }
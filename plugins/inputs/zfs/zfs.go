@@ -1,14 +1,31 @@
 package zfs
 
+import (
+	"bytes"
+	"fmt"
+	"os/exec"
+	"strconv"
+	"strings"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/filter"
+)
+
 type Sysctl func(metric string) ([]string, error)
 type Zpool func() ([]string, error)
+type Zdataset func() ([]string, error)
 
 type Zfs struct {
-	KstatPath    string
-	KstatMetrics []string
-	PoolMetrics  bool
-	sysctl       Sysctl
-	zpool        Zpool
+	KstatPath      string
+	KstatMetrics   []string
+	PoolMetrics    bool
+	DatasetMetrics bool
+	DatasetInclude []string
+	DatasetExclude []string
+	sysctl         Sysctl
+	zpool          Zpool
+	zdataset       Zdataset
+	datasetFilter  filter.Filter
 }
 
 var sampleConfig = `
@@ -22,6 +39,14 @@ var sampleConfig = `
 
   ## By default, don't gather zpool stats
   # poolMetrics = false
+
+  ## By default, don't gather dataset stats
+  # datasetMetrics = false
+
+  ## Datasets to include and exclude. Globs accepted.
+  ## Note that an empty array for both will include all datasets
+  # datasetInclude = []
+  # datasetExclude = []
 `
 
 func (z *Zfs) SampleConfig() string {
@@ -31,3 +56,164 @@ func (z *Zfs) SampleConfig() string {
 func (z *Zfs) Description() string {
 	return "Read metrics of ZFS from arcstats, zfetchstats, vdev_cache_stats, and pools"
 }
+
+// poolHealthCodes maps the pool health reported by zpool to the numeric
+// health_code field, so that alerts don't need to match on strings.
+var poolHealthCodes = map[string]int64{
+	"ONLINE":    0,
+	"DEGRADED":  1,
+	"FAULTED":   2,
+	"OFFLINE":   3,
+	"UNAVAIL":   4,
+	"REMOVED":   5,
+	"SUSPENDED": 6,
+}
+
+// parseZpoolLine parses a line of `zpool list -Hp -o name,size,allocated,
+// free,fragmentation,capacity,dedupratio,health` into the tags and fields of
+// the zfs_pool measurement.
+func parseZpoolLine(line string) (map[string]string, map[string]interface{}, error) {
+	col := strings.Split(line, "\t")
+	if len(col) != 8 {
+		return nil, nil, fmt.Errorf("Unexpected zpool output: %q", line)
+	}
+
+	tags := map[string]string{"pool": col[0], "health": col[7]}
+	fields := map[string]interface{}{}
+
+	if code, ok := poolHealthCodes[tags["health"]]; ok {
+		fields["health_code"] = code
+	}
+
+	if tags["health"] == "UNAVAIL" {
+		fields["size"] = int64(0)
+		return tags, fields, nil
+	}
+
+	// Degraded and faulted pools may report "-" for some of the columns,
+	// the remaining capacity fields are still gathered.
+	for _, c := range []struct {
+		name string
+		col  int
+	}{
+		{"size", 1},
+		{"allocated", 2},
+		{"free", 3},
+		{"capacity", 5},
+	} {
+		if col[c.col] == "-" {
+			continue
+		}
+		value, err := strconv.ParseInt(col[c.col], 10, 64)
+		if err != nil {
+			return nil, nil, fmt.Errorf("Error parsing %s: %s", c.name, err)
+		}
+		fields[c.name] = value
+	}
+
+	frag, err := strconv.ParseInt(strings.TrimSuffix(col[4], "%"), 10, 0)
+	if err != nil { // This might be - for RO devs
+		frag = 0
+	}
+	fields["fragmentation"] = frag
+
+	if col[6] != "-" {
+		dedup, err := strconv.ParseFloat(strings.TrimSuffix(col[6], "x"), 32)
+		if err != nil {
+			return nil, nil, fmt.Errorf("Error parsing dedupratio: %s", err)
+		}
+		fields["dedupratio"] = dedup
+	}
+
+	return tags, fields, nil
+}
+
+// gatherDatasetStats adds a zfs_dataset metric for each line of
+// `zfs list -Hp -o name,used,available,referenced,compressratio`.
+func (z *Zfs) gatherDatasetStats(acc telegraf.Accumulator) error {
+	if !z.DatasetMetrics {
+		return nil
+	}
+
+	if z.datasetFilter == nil {
+		datasetFilter, err := filter.NewIncludeExcludeFilter(
+			z.DatasetInclude, z.DatasetExclude)
+		if err != nil {
+			return err
+		}
+		z.datasetFilter = datasetFilter
+	}
+
+	lines, err := z.zdataset()
+	if err != nil {
+		return err
+	}
+
+	for _, line := range lines {
+		col := strings.Split(line, "\t")
+		if len(col) < 5 {
+			return fmt.Errorf("Unexpected zfs list output: %q", line)
+		}
+
+		if !z.datasetFilter.Match(col[0]) {
+			continue
+		}
+
+		fields := map[string]interface{}{}
+		for i, name := range []string{"used", "available", "referenced"} {
+			if col[i+1] == "-" {
+				continue
+			}
+			value, err := strconv.ParseInt(col[i+1], 10, 64)
+			if err != nil {
+				return fmt.Errorf("Error parsing %s: %s", name, err)
+			}
+			fields[name] = value
+		}
+
+		// Older versions keep the x suffix even with -p
+		if col[4] != "-" {
+			ratio, err := strconv.ParseFloat(strings.TrimSuffix(col[4], "x"), 64)
+			if err != nil {
+				return fmt.Errorf("Error parsing compressratio: %s", err)
+			}
+			fields["compressratio"] = ratio
+		}
+
+		acc.AddFields("zfs_dataset", fields, map[string]string{"dataset": col[0]})
+	}
+
+	return nil
+}
+
+func run(command string, args ...string) ([]string, error) {
+	cmd := exec.Command(command, args...)
+	var outbuf, errbuf bytes.Buffer
+	cmd.Stdout = &outbuf
+	cmd.Stderr = &errbuf
+	err := cmd.Run()
+
+	stdout := strings.TrimSpace(outbuf.String())
+	stderr := strings.TrimSpace(errbuf.String())
+
+	if _, ok := err.(*exec.ExitError); ok {
+		return nil, fmt.Errorf("%s error: %s", command, stderr)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if stdout == "" {
+		return []string{}, nil
+	}
+	return strings.Split(stdout, "\n"), nil
+}
+
+// zpool lists the pools with explicit columns, newer releases of ZFS add
+// columns such as checkpoint to the default ones.
+func zpool() ([]string, error) {
+	return run("zpool", []string{"list", "-Hp", "-o", "name,size,allocated,free,fragmentation,capacity,dedupratio,health"}...)
+}
+
+func zdataset() ([]string, error) {
+	return run("zfs", []string{"list", "-Hp", "-o", "name,used,available,referenced,compressratio"}...)
+}
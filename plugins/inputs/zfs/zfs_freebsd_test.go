@@ -10,21 +10,21 @@ import (
 	"github.com/stretchr/testify/require"
 )
 
-// $ zpool list -Hp
+// $ zpool list -Hp -o name,size,allocated,free,fragmentation,capacity,dedupratio,health
 var zpool_output = []string{
-	"freenas-boot	30601641984	2022177280	28579464704	-	-	6	1.00x	ONLINE	-",
-	"red1	8933531975680	1126164848640	7807367127040	-	8%	12	1.83x	ONLINE	/mnt",
-	"temp1	2989297238016	1626309320704	1362987917312	-	38%	54	1.28x	ONLINE	/mnt",
-	"temp2	2989297238016	626958278656	2362338959360	-	12%	20	1.00x	ONLINE	/mnt",
+	"freenas-boot	30601641984	2022177280	28579464704	-	6	1.00x	ONLINE",
+	"red1	8933531975680	1126164848640	7807367127040	8%	12	1.83x	ONLINE",
+	"temp1	2989297238016	1626309320704	1362987917312	38%	54	1.28x	ONLINE",
+	"temp2	2989297238016	626958278656	2362338959360	12%	20	1.00x	ONLINE",
 }
 
 func mock_zpool() ([]string, error) {
 	return zpool_output, nil
 }
 
-// $ zpool list -Hp
+// $ zpool list -Hp -o name,size,allocated,free,fragmentation,capacity,dedupratio,health
 var zpool_output_unavail = []string{
-	"temp2	-	-	-	-	-	-	-	UNAVAIL	-",
+	"temp2	-	-	-	-	-	-	UNAVAIL",
 }
 
 func mock_zpool_unavail() ([]string, error) {
@@ -169,12 +169,14 @@ func getFreeNasBootPoolMetrics() map[string]interface{} {
 		"free":          int64(28579464704),
 		"size":          int64(30601641984),
 		"fragmentation": int64(0),
+		"health_code":   int64(0),
 	}
 }
 
 func getTemp2PoolMetrics() map[string]interface{} {
 	return map[string]interface{}{
-		"size": int64(0),
+		"size":        int64(0),
+		"health_code": int64(4),
 	}
 }
 
@@ -31,6 +31,32 @@ func getPools(kstatPath string) []poolInfo {
 	return pools
 }
 
+// poolList holds the zpool list tags and fields of a pool, they are merged
+// into the kstat io fields of the pool.
+type poolList struct {
+	tags   map[string]string
+	fields map[string]interface{}
+}
+
+func gatherZpoolList(zpool Zpool) (map[string]poolList, error) {
+	list := make(map[string]poolList)
+
+	lines, err := zpool()
+	if err != nil {
+		return list, err
+	}
+
+	for _, line := range lines {
+		tags, fields, err := parseZpoolLine(line)
+		if err != nil {
+			return list, err
+		}
+		list[tags["pool"]] = poolList{tags: tags, fields: fields}
+	}
+
+	return list, nil
+}
+
 func getTags(pools []poolInfo) map[string]string {
 	var poolNames string
 
@@ -44,7 +70,7 @@ func getTags(pools []poolInfo) map[string]string {
 	return map[string]string{"pools": poolNames}
 }
 
-func gatherPoolStats(pool poolInfo, acc telegraf.Accumulator) error {
+func gatherPoolStats(pool poolInfo, list map[string]poolList, acc telegraf.Accumulator) error {
 	lines, err := internal.ReadLines(pool.ioFilename)
 	if err != nil {
 		return err
@@ -65,6 +91,11 @@ func gatherPoolStats(pool poolInfo, acc telegraf.Accumulator) error {
 
 	tag := map[string]string{"pool": pool.name}
 	fields := make(map[string]interface{})
+	if l, ok := list[pool.name]; ok {
+		tag = l.tags
+		fields = l.fields
+		delete(list, pool.name)
+	}
 	for i := 0; i < keyCount; i++ {
 		value, err := strconv.ParseInt(values[i], 10, 64)
 		if err != nil {
@@ -92,12 +123,31 @@ func (z *Zfs) Gather(acc telegraf.Accumulator) error {
 	tags := getTags(pools)
 
 	if z.PoolMetrics {
+		list := make(map[string]poolList)
+		if z.zpool != nil {
+			var err error
+			list, err = gatherZpoolList(z.zpool)
+			if err != nil {
+				acc.AddError(err)
+			}
+		}
+
 		for _, pool := range pools {
-			err := gatherPoolStats(pool, acc)
+			err := gatherPoolStats(pool, list, acc)
 			if err != nil {
 				return err
 			}
 		}
+
+		// Pools without kstats, e.g. faulted ones, still report capacity
+		for _, l := range list {
+			acc.AddFields("zfs_pool", l.fields, l.tags)
+		}
+	}
+
+	err := z.gatherDatasetStats(acc)
+	if err != nil {
+		return err
 	}
 
 	fields := make(map[string]interface{})
@@ -126,6 +176,9 @@ func (z *Zfs) Gather(acc telegraf.Accumulator) error {
 
 func init() {
 	inputs.Add("zfs", func() telegraf.Input {
-		return &Zfs{}
+		return &Zfs{
+			zpool:    zpool,
+			zdataset: zdataset,
+		}
 	})
 }
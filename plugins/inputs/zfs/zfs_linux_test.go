@@ -8,6 +8,7 @@ import (
 	"testing"
 
 	"github.com/influxdata/telegraf/testutil"
+	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
 
@@ -167,6 +168,101 @@ func TestZfsPoolMetrics(t *testing.T) {
 	require.NoError(t, err)
 }
 
+// $ zpool list -Hp -o name,size,allocated,free,fragmentation,capacity,dedupratio,health
+var zpool_list_output = []string{
+	"HOME	30601641984	2022177280	28579464704	3%	6	1.00x	DEGRADED",
+	"tank	1992864825344	797145930137	1195718895207	-	40	-	FAULTED",
+	"temp1	2989297238016	1626309320704	1362987917312	38%	54	1.28x	ONLINE",
+}
+
+func mock_zpool_list() ([]string, error) {
+	return zpool_list_output, nil
+}
+
+func TestParseZpoolLine(t *testing.T) {
+	tags, fields, err := parseZpoolLine("tank\t1992864825344\t797145930137\t1195718895207\t12%\t40\t1.05x\tONLINE")
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{"pool": "tank", "health": "ONLINE"}, tags)
+	assert.Equal(t, int64(12), fields["fragmentation"])
+	assert.Equal(t, int64(40), fields["capacity"])
+	assert.Equal(t, int64(0), fields["health_code"])
+
+	// the default columns of ZoL 0.8 include the checkpoint, they are not
+	// read by position
+	_, _, err = parseZpoolLine("tank\t1992864825344\t797145930137\t1195718895207\t-\t-\t12%\t40\t1.05x\tONLINE\t-")
+	assert.Error(t, err)
+}
+
+func TestZfsPoolMetrics_zpoolList(t *testing.T) {
+	err := os.MkdirAll(testKstatPath+"/HOME", 0755)
+	require.NoError(t, err)
+	defer os.RemoveAll(os.TempDir() + "/telegraf")
+
+	err = ioutil.WriteFile(testKstatPath+"/HOME/io", []byte(pool_ioContents), 0644)
+	require.NoError(t, err)
+
+	err = ioutil.WriteFile(testKstatPath+"/arcstats", []byte(arcstatsContents), 0644)
+	require.NoError(t, err)
+
+	var acc testutil.Accumulator
+
+	z := &Zfs{
+		KstatPath:    testKstatPath,
+		KstatMetrics: []string{"arcstats"},
+		PoolMetrics:  true,
+		zpool:        mock_zpool_list,
+	}
+	err = z.Gather(&acc)
+	require.NoError(t, err)
+
+	//degraded pool, kstat and zpool list metrics
+	poolMetrics := getPoolMetrics()
+	poolMetrics["size"] = int64(30601641984)
+	poolMetrics["allocated"] = int64(2022177280)
+	poolMetrics["free"] = int64(28579464704)
+	poolMetrics["fragmentation"] = int64(3)
+	poolMetrics["capacity"] = int64(6)
+	poolMetrics["dedupratio"] = float64(1)
+	poolMetrics["health_code"] = int64(1)
+
+	tags := map[string]string{
+		"pool":   "HOME",
+		"health": "DEGRADED",
+	}
+	acc.AssertContainsTaggedFields(t, "zfs_pool", poolMetrics, tags)
+
+	//faulted pool without kstats
+	tags = map[string]string{
+		"pool":   "tank",
+		"health": "FAULTED",
+	}
+	acc.AssertContainsTaggedFields(t, "zfs_pool",
+		map[string]interface{}{
+			"size":          int64(1992864825344),
+			"allocated":     int64(797145930137),
+			"free":          int64(1195718895207),
+			"fragmentation": int64(0),
+			"capacity":      int64(40),
+			"health_code":   int64(2),
+		}, tags)
+
+	//online pool without kstats
+	tags = map[string]string{
+		"pool":   "temp1",
+		"health": "ONLINE",
+	}
+	acc.AssertContainsTaggedFields(t, "zfs_pool",
+		map[string]interface{}{
+			"size":          int64(2989297238016),
+			"allocated":     int64(1626309320704),
+			"free":          int64(1362987917312),
+			"fragmentation": int64(38),
+			"capacity":      int64(54),
+			"dedupratio":    float64(float32(1.28)),
+			"health_code":   int64(0),
+		}, tags)
+}
+
 func TestZfsGeneratesMetrics(t *testing.T) {
 	err := os.MkdirAll(testKstatPath, 0755)
 	require.NoError(t, err)
@@ -366,3 +462,74 @@ func getPoolMetrics() map[string]interface{} {
 		"rcnt":     int64(0),
 	}
 }
+
+// $ zfs list -Hp -o name,used,available,referenced,compressratio
+var zfs_list_output = []string{
+	"HOME	1884160000	28579464704	98304	1.00x",
+	"HOME/alice	1048576000	28579464704	1048576000	2.35x",
+	"HOME/bob	835485696	28579464704	835485696	1.42",
+	"HOME/bob@daily	0	-	835485696	1.42x",
+}
+
+func mock_zfs_list() ([]string, error) {
+	return zfs_list_output, nil
+}
+
+func TestZfsDatasetMetrics(t *testing.T) {
+	err := os.MkdirAll(testKstatPath, 0755)
+	require.NoError(t, err)
+	defer os.RemoveAll(os.TempDir() + "/telegraf")
+
+	err = ioutil.WriteFile(testKstatPath+"/arcstats", []byte(arcstatsContents), 0644)
+	require.NoError(t, err)
+
+	var acc testutil.Accumulator
+
+	z := &Zfs{
+		KstatPath:    testKstatPath,
+		KstatMetrics: []string{"arcstats"},
+		zdataset:     mock_zfs_list,
+	}
+	err = z.Gather(&acc)
+	require.NoError(t, err)
+
+	require.False(t, acc.HasMeasurement("zfs_dataset"))
+	acc.Metrics = nil
+
+	z = &Zfs{
+		KstatPath:      testKstatPath,
+		KstatMetrics:   []string{"arcstats"},
+		DatasetMetrics: true,
+		DatasetInclude: []string{"HOME/*"},
+		DatasetExclude: []string{"*@*"},
+		zdataset:       mock_zfs_list,
+	}
+	err = z.Gather(&acc)
+	require.NoError(t, err)
+
+	acc.AssertContainsTaggedFields(t, "zfs_dataset",
+		map[string]interface{}{
+			"used":          int64(1048576000),
+			"available":     int64(28579464704),
+			"referenced":    int64(1048576000),
+			"compressratio": float64(2.35),
+		},
+		map[string]string{"dataset": "HOME/alice"})
+
+	acc.AssertContainsTaggedFields(t, "zfs_dataset",
+		map[string]interface{}{
+			"used":          int64(835485696),
+			"available":     int64(28579464704),
+			"referenced":    int64(835485696),
+			"compressratio": float64(1.42),
+		},
+		map[string]string{"dataset": "HOME/bob"})
+
+	datasets := 0
+	for _, m := range acc.Metrics {
+		if m.Measurement == "zfs_dataset" {
+			datasets++
+		}
+	}
+	require.Equal(t, 2, datasets)
+}
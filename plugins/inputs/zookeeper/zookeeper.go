@@ -2,6 +2,7 @@ package zookeeper
 
 import (
 	"bufio"
+	"crypto/tls"
 	"fmt"
 	"net"
 	"os"
@@ -11,12 +12,27 @@ import (
 	"time"
 
 	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/internal"
 	"github.com/influxdata/telegraf/plugins/inputs"
 )
 
 // Zookeeper is a zookeeper plugin
 type Zookeeper struct {
-	Servers []string
+	Servers  []string
+	Timeout  internal.Duration
+	Commands []string
+
+	// Path to CA file
+	SSLCA string `toml:"ssl_ca"`
+	// Path to host cert file
+	SSLCert string `toml:"ssl_cert"`
+	// Path to cert key file
+	SSLKey string `toml:"ssl_key"`
+	// Use SSL but skip chain & host verification
+	InsecureSkipVerify bool
+
+	tlsConfig   *tls.Config
+	initialized bool
 }
 
 var sampleConfig = `
@@ -26,10 +42,33 @@ var sampleConfig = `
   ## If no servers are specified, then localhost is used as the host.
   ## If no port is specified, 2181 is used
   servers = [":2181"]
+
+  ## Timeout for connecting to and reading from each server.
+  # timeout = "5s"
+
+  ## Additional four letter words to send besides 'mntr', one of "ruok",
+  ## "wchs" or "cons".
+  # commands = ["ruok", "wchs", "cons"]
+
+  ## Optional SSL Config, the client port is dialed with TLS when any of
+  ## these are set
+  # ssl_ca = "/etc/telegraf/ca.pem"
+  # ssl_cert = "/etc/telegraf/cert.pem"
+  # ssl_key = "/etc/telegraf/key.pem"
+  ## Use SSL but skip chain & host verification
+  # insecure_skip_verify = false
 `
 
 var defaultTimeout = time.Second * time.Duration(5)
 
+// commandParsers holds the four letter words that may be sent in addition to
+// 'mntr', other commands are rejected.
+var commandParsers = map[string]func(lines []string, fields map[string]interface{}, tags map[string]string, acc telegraf.Accumulator) error{
+	"ruok": parseRuok,
+	"wchs": parseWchs,
+	"cons": parseCons,
+}
+
 // SampleConfig returns sample configuration message
 func (z *Zookeeper) SampleConfig() string {
 	return sampleConfig
@@ -42,6 +81,22 @@ func (z *Zookeeper) Description() string {
 
 // Gather reads stats from all configured servers accumulates stats
 func (z *Zookeeper) Gather(acc telegraf.Accumulator) error {
+	if !z.initialized {
+		tlsConfig, err := internal.GetTLSConfig(
+			z.SSLCert, z.SSLKey, z.SSLCA, z.InsecureSkipVerify)
+		if err != nil {
+			return err
+		}
+		z.tlsConfig = tlsConfig
+
+		for _, cmd := range z.Commands {
+			if _, ok := commandParsers[cmd]; !ok {
+				return fmt.Errorf("unsupported four letter word %q", cmd)
+			}
+		}
+		z.initialized = true
+	}
+
 	if len(z.Servers) == 0 {
 		z.Servers = []string{":2181"}
 	}
@@ -59,29 +114,18 @@ func (z *Zookeeper) gatherServer(address string, acc telegraf.Accumulator) error
 		address = address + ":2181"
 	}
 
-	c, err := net.DialTimeout("tcp", address, defaultTimeout)
-	if err != nil {
-		fmt.Fprintln(os.Stderr, err)
-		return err
-	}
-	defer c.Close()
-
-	// Extend connection
-	c.SetDeadline(time.Now().Add(defaultTimeout))
-
-	fmt.Fprintf(c, "%s\n", "mntr")
-	rdr := bufio.NewReader(c)
-	scanner := bufio.NewScanner(rdr)
-
 	service := strings.Split(address, ":")
 	if len(service) != 2 {
 		return fmt.Errorf("Invalid service address: %s", address)
 	}
 
-	fields := make(map[string]interface{})
-	for scanner.Scan() {
-		line := scanner.Text()
+	lines, err := z.sendCommand(address, "mntr")
+	if err != nil {
+		return err
+	}
 
+	fields := make(map[string]interface{})
+	for _, line := range lines {
 		re := regexp.MustCompile(`^zk_(\w+)\s+([\w\.\-]+)`)
 		parts := re.FindStringSubmatch(string(line))
 
@@ -103,16 +147,153 @@ func (z *Zookeeper) gatherServer(address string, acc telegraf.Accumulator) error
 			}
 		}
 	}
+
 	tags := map[string]string{
 		"server": service[0],
 		"port":   service[1],
 		"state":  zookeeper_state,
 	}
+
+	for _, cmd := range z.Commands {
+		lines, err := z.sendCommand(address, cmd)
+		if err == nil {
+			err = commandParsers[cmd](lines, fields, tags, acc)
+		}
+		acc.AddError(err)
+	}
+
 	acc.AddFields("zookeeper", fields, tags)
 
 	return nil
 }
 
+// sendCommand sends a four letter word on a new connection, the server
+// closes it after answering, and returns the lines of the response.
+func (z *Zookeeper) sendCommand(address string, cmd string) ([]string, error) {
+	timeout := z.Timeout.Duration
+	if timeout == 0 {
+		timeout = defaultTimeout
+	}
+
+	c, err := z.dial(address, timeout)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return nil, err
+	}
+	defer c.Close()
+
+	// Extend connection
+	c.SetDeadline(time.Now().Add(timeout))
+
+	fmt.Fprintf(c, "%s\n", cmd)
+	scanner := bufio.NewScanner(c)
+
+	var lines []string
+	for scanner.Scan() {
+		lines = append(lines, scanner.Text())
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("reading %s response from %s: %s", cmd, address, err)
+	}
+
+	return lines, nil
+}
+
+// parseRuok sets the ruok field to whether the server answered 'imok'.
+func parseRuok(lines []string, fields map[string]interface{}, tags map[string]string, acc telegraf.Accumulator) error {
+	fields["ruok"] = len(lines) > 0 && strings.TrimSpace(lines[0]) == "imok"
+	return nil
+}
+
+// parseWchs parses the watch summary:
+//
+//	3 connections watching 4 paths
+//	Total watches:5
+func parseWchs(lines []string, fields map[string]interface{}, tags map[string]string, acc telegraf.Accumulator) error {
+	var connections, paths, watches int64
+	if len(lines) != 2 {
+		return fmt.Errorf("unexpected wchs response: %q", lines)
+	}
+	if _, err := fmt.Sscanf(lines[0], "%d connections watching %d paths", &connections, &paths); err != nil {
+		return fmt.Errorf("unexpected line in wchs response: %q", lines[0])
+	}
+	if _, err := fmt.Sscanf(lines[1], "Total watches:%d", &watches); err != nil {
+		return fmt.Errorf("unexpected line in wchs response: %q", lines[1])
+	}
+	fields["wchs_connection_count"] = connections
+	fields["wchs_path_count"] = paths
+	fields["wchs_watch_count"] = watches
+	return nil
+}
+
+var consRe = regexp.MustCompile(`^\s*/(\S+)\[\d+\]\((.*)\)\s*$`)
+
+// consFields maps the statistics of a cons connection to field names
+var consFields = map[string]string{
+	"queued": "queued",
+	"recved": "received",
+	"sent":   "sent",
+	"to":     "timeout",
+	"llat":   "last_latency",
+	"minlat": "min_latency",
+	"avglat": "avg_latency",
+	"maxlat": "max_latency",
+	"sid":    "session_id",
+	"lop":    "last_operation",
+	"lcxid":  "last_cxid",
+	"lzxid":  "last_zxid",
+	"est":    "established",
+	"lresp":  "last_response",
+}
+
+// parseCons adds a zookeeper_connection metric for each connection listed:
+//
+//	/127.0.0.1:54232[1](queued=0,recved=1,sent=1,sid=0x15a8,lop=PING,...)
+func parseCons(lines []string, fields map[string]interface{}, tags map[string]string, acc telegraf.Accumulator) error {
+	for _, line := range lines {
+		if strings.TrimSpace(line) == "" {
+			continue
+		}
+
+		parts := consRe.FindStringSubmatch(line)
+		if len(parts) != 3 {
+			return fmt.Errorf("unexpected line in cons response: %q", line)
+		}
+
+		connTags := map[string]string{
+			"server": tags["server"],
+			"port":   tags["port"],
+			"client": parts[1],
+		}
+		connFields := make(map[string]interface{})
+		for _, stat := range strings.Split(parts[2], ",") {
+			kv := strings.SplitN(stat, "=", 2)
+			if len(kv) != 2 {
+				continue
+			}
+			name, ok := consFields[kv[0]]
+			if !ok {
+				continue
+			}
+			if iVal, err := strconv.ParseInt(kv[1], 10, 64); err == nil {
+				connFields[name] = iVal
+			} else {
+				connFields[name] = kv[1]
+			}
+		}
+		acc.AddFields("zookeeper_connection", connFields, connTags)
+	}
+	return nil
+}
+
+func (z *Zookeeper) dial(address string, timeout time.Duration) (net.Conn, error) {
+	dialer := &net.Dialer{Timeout: timeout}
+	if z.tlsConfig != nil {
+		return tls.DialWithDialer(dialer, "tcp", address, z.tlsConfig)
+	}
+	return dialer.Dial("tcp", address)
+}
+
 func init() {
 	inputs.Add("zookeeper", func() telegraf.Input {
 		return &Zookeeper{}
@@ -1,8 +1,19 @@
 package zookeeper
 
 import (
+	"bufio"
+	"crypto/tls"
+	"encoding/pem"
+	"fmt"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
 	"testing"
+	"time"
 
+	"github.com/influxdata/telegraf/internal"
 	"github.com/influxdata/telegraf/testutil"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -40,3 +51,228 @@ func TestZookeeperGeneratesMetrics(t *testing.T) {
 		assert.True(t, acc.HasInt64Field("zookeeper", metric), metric)
 	}
 }
+
+const sampleMntrResponse = `zk_version	3.5.3-beta-8ce24f9e675cbefffb8f21a47e06b42864475a60, built on 04/03/2017 16:19 GMT
+zk_avg_latency	0
+zk_max_latency	5
+zk_min_latency	0
+zk_packets_received	31
+zk_packets_sent	30
+zk_num_alive_connections	1
+zk_outstanding_requests	0
+zk_server_state	leader
+zk_znode_count	5
+zk_watch_count	0
+zk_ephemerals_count	0
+zk_approximate_data_size	44
+zk_open_file_descriptor_count	32
+zk_max_file_descriptor_count	1048576
+`
+
+// serveCommands answers each four letter word sent to the listener with the
+// matching response.
+func serveCommands(ln net.Listener, responses map[string]string) {
+	for {
+		conn, err := ln.Accept()
+		if err != nil {
+			return
+		}
+		go func(c net.Conn) {
+			defer c.Close()
+			cmd, err := bufio.NewReader(c).ReadString('\n')
+			if err != nil {
+				return
+			}
+			fmt.Fprint(c, responses[cmd[:len(cmd)-1]])
+		}(conn)
+	}
+}
+
+func TestZookeeperTLS(t *testing.T) {
+	// Borrow the certificate of a test server, it is valid for 127.0.0.1
+	ts := httptest.NewTLSServer(http.NotFoundHandler())
+	defer ts.Close()
+
+	caFile, err := ioutil.TempFile("", "zookeeper-ca")
+	require.NoError(t, err)
+	defer os.Remove(caFile.Name())
+	require.NoError(t, pem.Encode(caFile, &pem.Block{Type: "CERTIFICATE", Bytes: ts.Certificate().Raw}))
+	require.NoError(t, caFile.Close())
+
+	ln, err := tls.Listen("tcp", "127.0.0.1:0", &tls.Config{Certificates: ts.TLS.Certificates})
+	require.NoError(t, err)
+	defer ln.Close()
+	go serveCommands(ln, map[string]string{"mntr": sampleMntrResponse})
+
+	z := &Zookeeper{
+		Servers: []string{ln.Addr().String()},
+		SSLCA:   caFile.Name(),
+	}
+
+	var acc testutil.Accumulator
+	require.NoError(t, acc.GatherError(z.Gather))
+
+	_, port, err := net.SplitHostPort(ln.Addr().String())
+	require.NoError(t, err)
+
+	acc.AssertContainsTaggedFields(t, "zookeeper",
+		map[string]interface{}{
+			"version":                    "3.5.3-beta-8ce24f9e675cbefffb8f21a47e06b42864475a60",
+			"avg_latency":                int64(0),
+			"max_latency":                int64(5),
+			"min_latency":                int64(0),
+			"packets_received":           int64(31),
+			"packets_sent":               int64(30),
+			"num_alive_connections":      int64(1),
+			"outstanding_requests":       int64(0),
+			"znode_count":                int64(5),
+			"watch_count":                int64(0),
+			"ephemerals_count":           int64(0),
+			"approximate_data_size":      int64(44),
+			"open_file_descriptor_count": int64(32),
+			"max_file_descriptor_count":  int64(1048576),
+		},
+		map[string]string{
+			"server": "127.0.0.1",
+			"port":   port,
+			"state":  "leader",
+		})
+}
+
+func TestZookeeperTLSPlaintextServer(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer ln.Close()
+	go serveCommands(ln, map[string]string{"mntr": sampleMntrResponse})
+
+	z := &Zookeeper{
+		Servers:            []string{ln.Addr().String()},
+		InsecureSkipVerify: true,
+		Timeout:            internal.Duration{Duration: time.Second},
+	}
+
+	var acc testutil.Accumulator
+	require.NoError(t, z.Gather(&acc))
+	require.Len(t, acc.Errors, 1)
+	assert.False(t, acc.HasMeasurement("zookeeper"))
+}
+
+func TestZookeeperTimeout(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer ln.Close()
+
+	// Accept connections but never answer, like a wedged node
+	go func() {
+		for {
+			conn, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			defer conn.Close()
+		}
+	}()
+
+	z := &Zookeeper{
+		Servers: []string{ln.Addr().String()},
+		Timeout: internal.Duration{Duration: 100 * time.Millisecond},
+	}
+
+	var acc testutil.Accumulator
+	start := time.Now()
+	require.NoError(t, z.Gather(&acc))
+	assert.True(t, time.Since(start) < 5*time.Second)
+	require.Len(t, acc.Errors, 1)
+	assert.False(t, acc.HasMeasurement("zookeeper"))
+}
+
+const sampleWchsResponse = `1 connections watching 2 paths
+Total watches:3
+`
+
+const sampleConsResponse = ` /10.0.0.12:54232[1](queued=0,recved=114,sent=115,sid=0x15b4b6ec4c10000,lop=PING,est=1493289345683,to=30000,lcxid=0x7,lzxid=0xffffffffffffffff,lresp=1493289455861,llat=0,minlat=0,avglat=1,maxlat=12)
+ /127.0.0.1:54236[0](queued=0,recved=1,sent=0)
+
+`
+
+func TestZookeeperCommands(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer ln.Close()
+	go serveCommands(ln, map[string]string{
+		"mntr": sampleMntrResponse,
+		"ruok": "imok",
+		"wchs": sampleWchsResponse,
+		"cons": sampleConsResponse,
+	})
+
+	z := &Zookeeper{
+		Servers:  []string{ln.Addr().String()},
+		Commands: []string{"ruok", "wchs", "cons"},
+	}
+
+	var acc testutil.Accumulator
+	require.NoError(t, z.Gather(&acc))
+	require.Empty(t, acc.Errors)
+
+	_, port, err := net.SplitHostPort(ln.Addr().String())
+	require.NoError(t, err)
+
+	m, ok := acc.Get("zookeeper")
+	require.True(t, ok)
+	assert.Equal(t, true, m.Fields["ruok"])
+	assert.Equal(t, int64(1), m.Fields["wchs_connection_count"])
+	assert.Equal(t, int64(2), m.Fields["wchs_path_count"])
+	assert.Equal(t, int64(3), m.Fields["wchs_watch_count"])
+	assert.Equal(t, int64(5), m.Fields["znode_count"])
+
+	acc.AssertContainsTaggedFields(t, "zookeeper_connection",
+		map[string]interface{}{
+			"queued":         int64(0),
+			"received":       int64(114),
+			"sent":           int64(115),
+			"session_id":     "0x15b4b6ec4c10000",
+			"last_operation": "PING",
+			"established":    int64(1493289345683),
+			"timeout":        int64(30000),
+			"last_cxid":      "0x7",
+			"last_zxid":      "0xffffffffffffffff",
+			"last_response":  int64(1493289455861),
+			"last_latency":   int64(0),
+			"min_latency":    int64(0),
+			"avg_latency":    int64(1),
+			"max_latency":    int64(12),
+		},
+		map[string]string{
+			"server": "127.0.0.1",
+			"port":   port,
+			"client": "10.0.0.12:54232",
+		})
+
+	acc.AssertContainsTaggedFields(t, "zookeeper_connection",
+		map[string]interface{}{
+			"queued":   int64(0),
+			"received": int64(1),
+			"sent":     int64(0),
+		},
+		map[string]string{
+			"server": "127.0.0.1",
+			"port":   port,
+			"client": "127.0.0.1:54236",
+		})
+}
+
+func TestZookeeperWchsInvalid(t *testing.T) {
+	err := parseWchs([]string{"no watches here"}, map[string]interface{}{}, nil, nil)
+	require.Error(t, err)
+}
+
+func TestZookeeperUnsupportedCommand(t *testing.T) {
+	z := &Zookeeper{
+		Servers:  []string{"127.0.0.1:0"},
+		Commands: []string{"wchs", "kill"},
+	}
+
+	var acc testutil.Accumulator
+	require.Error(t, z.Gather(&acc))
+}
@@ -0,0 +1,130 @@
+// Package fakesql provides a database/sql driver answering the queries of a
+// test with the results it expects, in order.
+package fakesql
+
+import (
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// DriverName is the name the fake driver is registered with.
+const DriverName = "telegraf_fake"
+
+// Result is the result returned to the query containing Match.
+type Result struct {
+	Match   string
+	Columns []string
+	Rows    [][]driver.Value
+	Err     error
+}
+
+// DB answers the queries of a test with the expected results, in order.
+type DB struct {
+	sync.Mutex
+	results []Result
+}
+
+func (f *DB) query(query string) (driver.Rows, error) {
+	f.Lock()
+	defer f.Unlock()
+	if len(f.results) == 0 || !strings.Contains(query, f.results[0].Match) {
+		return nil, fmt.Errorf("unexpected query %q", query)
+	}
+	result := f.results[0]
+	f.results = f.results[1:]
+	if result.Err != nil {
+		return nil, result.Err
+	}
+	return &rows{columns: result.Columns, rows: result.Rows}, nil
+}
+
+// Unmet returns the results which weren't queried.
+func (f *DB) Unmet() []Result {
+	f.Lock()
+	defer f.Unlock()
+	return f.results
+}
+
+var dbs = struct {
+	sync.Mutex
+	dbs map[string]*DB
+}{dbs: make(map[string]*DB)}
+
+func init() {
+	sql.Register(DriverName, fakeDriver{})
+}
+
+// Open returns a connection answering the queries of the test with the
+// results.
+func Open(t *testing.T, results ...Result) (*sql.DB, *DB) {
+	fake := &DB{results: results}
+	dbs.Lock()
+	dbs.dbs[t.Name()] = fake
+	dbs.Unlock()
+
+	db, err := sql.Open(DriverName, t.Name())
+	require.NoError(t, err)
+	return db, fake
+}
+
+type fakeDriver struct{}
+
+func (fakeDriver) Open(name string) (driver.Conn, error) {
+	dbs.Lock()
+	defer dbs.Unlock()
+	fake, ok := dbs.dbs[name]
+	if !ok {
+		return nil, fmt.Errorf("unknown fake database %q", name)
+	}
+	return &conn{db: fake}, nil
+}
+
+type conn struct {
+	db *DB
+}
+
+func (c *conn) Prepare(query string) (driver.Stmt, error) {
+	return nil, errors.New("prepared statements aren't supported")
+}
+
+func (c *conn) Close() error {
+	return nil
+}
+
+func (c *conn) Begin() (driver.Tx, error) {
+	return nil, errors.New("transactions aren't supported")
+}
+
+func (c *conn) Query(query string, args []driver.Value) (driver.Rows, error) {
+	return c.db.query(query)
+}
+
+type rows struct {
+	columns []string
+	rows    [][]driver.Value
+}
+
+func (r *rows) Columns() []string {
+	return r.columns
+}
+
+func (r *rows) Close() error {
+	return nil
+}
+
+func (r *rows) Next(dest []driver.Value) error {
+	if len(r.rows) == 0 {
+		return io.EOF
+	}
+	copy(dest, r.rows[0])
+	r.rows = r.rows[1:]
+	return nil
+}